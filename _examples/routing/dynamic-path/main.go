@@ -153,6 +153,51 @@ func main() {
 	// string of time.Weekday longname format ("sunday" to "monday" or "Sunday" to "Monday")
 	// format e.g. /schedule/{param:weekday} matches /schedule/monday.
 	//
+	// +------------------------+
+	// | {param:ulid}           |
+	// +------------------------+
+	// ULID path parameter validation, e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV.
+	//
+	// +------------------------+
+	// | {param:semver}         |
+	// +------------------------+
+	// semantic version, e.g. 1.2.3 or v1.0.0-rc.1, as macro.SemanticVersion.
+	//
+	// +------------------------+
+	// | {param:base64}         |
+	// | {param:hex}            |
+	// +------------------------+
+	// base64 (standard or URL-safe) or hexadecimal string, decoded as []byte.
+	//
+	// +------------------------+
+	// | {param:slug}           |
+	// +------------------------+
+	// lowercase letters and numbers separated by dashes, e.g. hello-world.
+	//
+	// +------------------------+
+	// | {param:ipv4}           |
+	// | {param:ipv6}           |
+	// +------------------------+
+	// IP address as netip.Addr.
+	//
+	// +------------------------+
+	// | {param:enum(a,b,c)}    |
+	// +------------------------+
+	// one of the given values, e.g. /colors/{param:enum(red,green,blue)}.
+	//
+	// +------------------------+
+	// | {param:duration}       |
+	// +------------------------+
+	// time.Duration, e.g. 1h30m.
+	//
+	// +------------------------+
+	// | {param:iso8601}        |
+	// +------------------------+
+	// ISO 8601 (RFC 3339) date-time as time.Time, e.g. 2024-04-25T10:00:00Z.
+	//
+	// New parameter types, based on the above, can be declared
+	// in a YAML or JSON file too, see app.Macros().Load and macro.Definition.
+	//
 	// If type is missing then parameter's type is defaulted to string, so
 	// {param} is identical to {param:string}.
 	//
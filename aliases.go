@@ -108,6 +108,26 @@ type (
 	//
 	// It is an alias of the `context#ProblemOptions` type.
 	ProblemOptions = context.ProblemOptions
+	// SSEEvent is a Server-Sent Event.
+	// See `Context.SSE` method and `SSEBroker` type for more details.
+	//
+	// It is an alias of the `context#SSEEvent` type.
+	SSEEvent = context.SSEEvent
+	// SSEWriter writes Server-Sent Events to a client,
+	// it's returned by the `Context.SSE` method.
+	//
+	// It is an alias of the `context#SSEWriter` type.
+	SSEWriter = context.SSEWriter
+	// SSEBroker broadcasts Server-Sent Events to all of its subscribers.
+	// See `NewSSEBroker` package-level function.
+	//
+	// It is an alias of the `context#SSEBroker` type.
+	SSEBroker = context.SSEBroker
+	// EventStream is a handler result which streams the events of a channel
+	// as Server-Sent Events.
+	//
+	// It is an alias of the `context#EventStream` type.
+	EventStream = context.EventStream
 	// JSON the optional settings for JSON renderer.
 	//
 	// It is an alias of the `context#JSON` type.
@@ -150,6 +170,11 @@ type (
 	//
 	// A shortcut for the `core/router#APIContainer`.
 	APIContainer = router.APIContainer
+	// RoutesTransaction builds a new set of routes and swaps it in atomically on serve-time.
+	// See `Application.BeginRoutes` for more.
+	//
+	// A shortcut for the `core/router#RoutesTransaction`.
+	RoutesTransaction = router.RoutesTransaction
 	// ResultHandler describes the function type which should serve the "v" struct value.
 	// See `APIContainer.UseResultHandler`.
 	ResultHandler = hero.ResultHandler
@@ -614,6 +639,11 @@ var (
 	//
 	// A shortcut for the `context#NewProblem`.
 	NewProblem = context.NewProblem
+	// NewSSEBroker returns a new Server-Sent Events broker.
+	// Head over to the `SSEBroker` type godoc for more.
+	//
+	// A shortcut for the `context#NewSSEBroker`.
+	NewSSEBroker = context.NewSSEBroker
 	// XMLMap wraps a map[string]interface{} to compatible xml marshaler,
 	// in order to be able to render maps as XML on the `Context.XML` method.
 	//
@@ -5,6 +5,7 @@ package auth
 
 import (
 	stdContext "context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/kataras/iris/v12/context"
+	jwtmiddleware "github.com/kataras/iris/v12/middleware/jwt"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/securecookie"
@@ -56,6 +58,18 @@ type (
 		claimsProvider ClaimsProvider
 		// True if KIDRefresh on config.Keys.
 		refreshEnabled bool
+		// Not nil if a rotating key set is registered, it signs the access tokens.
+		keySet *jwtmiddleware.KeySet
+		// Not nil if refresh token rotation is enabled.
+		refreshTokenStore RefreshTokenStore
+		// Optional, returns the subject of refresh token families.
+		refreshTokenSubject func(T) string
+		// Not nil if multi-factor authentication is enabled.
+		mfaStore MFAStore
+		// Returns the subject of the MFAStore's entries.
+		mfaSubject func(T) string
+		// Signs and verifies the "mfa-pending" tokens, it's never published.
+		mfaKeys jwt.Keys
 	}
 
 	// VerifyUserFunc is passed on Verify and VerifyHandler method
@@ -215,11 +229,67 @@ func (s *Auth[T]) SetTransformerFunc(transfermerFunc func(ctx stdContext.Context
 	return s
 }
 
+// SetKeySet sets a rotating key set to this Auth of T instance and returns itself.
+// The access tokens are signed by the key set's current key instead of the
+// configuration's KIDAccess key, which keeps its max age. Tokens signed by the configuration's
+// keys and by the retired keys of the set, in their grace period, are still valid.
+// Refresh tokens are always signed by the configuration's KIDRefresh key.
+//
+// Publish the key set's public keys through the JWKSHandler method.
+//
+// Usage:
+//
+//	set, err := jwt.NewKeySet(jwt.EdDSA, 24*time.Hour, 2*time.Hour)
+//	s.SetKeySet(set)
+//	app.Get(jwt.JWKSPath, s.JWKSHandler)
+func (s *Auth[T]) SetKeySet(set *jwtmiddleware.KeySet) *Auth[T] {
+	s.keySet = set
+	return s
+}
+
+// JWKSHandler serves the public keys of the key set (see SetKeySet) and the asymmetric
+// keys of the configuration as a JSON Web Key Set, so other services can
+// verify the access tokens through the jwt.NewRemoteVerifier function.
+// Register it on the jwt.JWKSPath ("/.well-known/jwks.json").
+func (s *Auth[T]) JWKSHandler(ctx *context.Context) {
+	var set jwtmiddleware.JWKS
+	if s.keySet != nil {
+		set = s.keySet.JWKS()
+	}
+
+	for _, kid := range []string{KIDAccess, KIDRefresh} {
+		if key, ok := s.keys[kid]; ok && key.Encrypt == nil { // encrypted tokens cannot be verified by others anyway.
+			if jwk, err := jwtmiddleware.NewJWK(kid, key.Alg, key.Public); err == nil {
+				set.Keys = append(set.Keys, jwk)
+			}
+		}
+	}
+
+	ctx.Header(context.CacheControlHeaderKey, "public, max-age=3600")
+	ctx.JSON(set)
+}
+
+// validateHeader resolves the key of a token by its "kid",
+// through the key set first and then through the configuration's keys.
+func (s *Auth[T]) validateHeader(alg string, headerDecoded []byte) (jwt.Alg, jwt.PublicKey, jwt.InjectFunc, error) {
+	if s.keySet != nil {
+		keyAlg, key, decrypt, err := s.keySet.ValidateHeader(alg, headerDecoded)
+		if err != jwt.ErrUnknownKid {
+			return keyAlg, key, decrypt, err
+		}
+	}
+
+	return s.keys.ValidateHeader(alg, headerDecoded)
+}
+
 // Signin signs a token based on the provided username and password
 // and returns a pair of access and refresh tokens.
 //
 // Signin calls the Provider.Signin method to check if a user
 // is authenticated by the given username and password combination.
+//
+// If the user has to complete the second factor, see SetMFAStore,
+// it returns a *MFARequiredError which holds the "mfa-pending" token instead.
 func (s *Auth[T]) Signin(ctx stdContext.Context, username, password string) ([]byte, []byte, error) {
 	var t T
 
@@ -245,8 +315,20 @@ func (s *Auth[T]) Signin(ctx stdContext.Context, username, password string) ([]b
 		return nil, nil, fmt.Errorf("auth: signin: no provider")
 	}
 
+	// check for the second factor.
+	if required, err := s.mfaRequired(ctx, t); err != nil {
+		return nil, nil, fmt.Errorf("auth: signin: %w", err)
+	} else if required {
+		mfaToken, err := s.signMFAPending(t)
+		if err != nil {
+			return nil, nil, fmt.Errorf("auth: signin: %w", err)
+		}
+
+		return nil, nil, &MFARequiredError{Token: mfaToken}
+	}
+
 	// sign the tokens.
-	accessToken, refreshToken, err := s.sign(t)
+	accessToken, refreshToken, err := s.sign(ctx, t, "")
 	if err != nil {
 		return nil, nil, fmt.Errorf("auth: signin: %w", err)
 	}
@@ -254,7 +336,10 @@ func (s *Auth[T]) Signin(ctx stdContext.Context, username, password string) ([]b
 	return accessToken, refreshToken, nil
 }
 
-func (s *Auth[T]) sign(t T) ([]byte, []byte, error) {
+// sign signs a new access and refresh token pair for "t".
+// The "usedRefreshTokenID" is the id of the refresh token which is exchanged
+// for the new pair, if any, see SetRefreshTokenStore.
+func (s *Auth[T]) sign(ctx stdContext.Context, t T, usedRefreshTokenID string) ([]byte, []byte, error) {
 	// sign the tokens.
 	var (
 		accessStdClaims  StandardClaims
@@ -267,6 +352,7 @@ func (s *Auth[T]) sign(t T) ([]byte, []byte, error) {
 	}
 
 	iat := jwt.Clock().Unix()
+	var err error
 
 	if accessStdClaims.IssuedAt == 0 {
 		accessStdClaims.IssuedAt = iat
@@ -291,7 +377,17 @@ func (s *Auth[T]) sign(t T) ([]byte, []byte, error) {
 		refreshStdClaims.OriginID = accessStdClaims.ID
 	}
 
-	accessToken, err := s.keys.SignToken(KIDAccess, t, accessStdClaims)
+	var accessToken []byte
+	if s.keySet != nil {
+		signOpts := []jwt.SignOption{accessStdClaims}
+		if maxAge := s.keys[KIDAccess].MaxAge; maxAge > 0 {
+			signOpts = append([]jwt.SignOption{jwt.MaxAge(maxAge)}, signOpts...)
+		}
+
+		accessToken, err = s.keySet.SignToken(t, signOpts...)
+	} else {
+		accessToken, err = s.keys.SignToken(KIDAccess, t, accessStdClaims)
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("access: %w", err)
 	}
@@ -302,6 +398,10 @@ func (s *Auth[T]) sign(t T) ([]byte, []byte, error) {
 		if err != nil {
 			return nil, nil, fmt.Errorf("refresh: %w", err)
 		}
+
+		if err = s.trackRefreshToken(ctx, t, usedRefreshTokenID, refreshStdClaims); err != nil {
+			return nil, nil, fmt.Errorf("refresh: %w", err)
+		}
 	}
 
 	return accessToken, refreshToken, nil
@@ -309,6 +409,8 @@ func (s *Auth[T]) sign(t T) ([]byte, []byte, error) {
 
 // SignHandler generates and sends a pair of access and refresh token to the client
 // as JSON body of `SigninResponse` and cookie (if cookie setting was provided).
+// If the user has to complete the second factor then it sends
+// the `MFAPendingResponse` instead, see `MFAHandler` method.
 // See `Signin` method for more.
 func (s *Auth[T]) SigninHandler(ctx *context.Context) {
 	// No, let the developer decide it based on a middleware, e.g. iris.LimitRequestBodySize.
@@ -339,6 +441,12 @@ func (s *Auth[T]) SigninHandler(ctx *context.Context) {
 	if err != nil {
 		s.tryRemoveCookie(ctx) // remove cookie on invalidated.
 
+		var mfaErr *MFARequiredError
+		if errors.As(err, &mfaErr) {
+			s.writeMFAPending(ctx, mfaErr.Token)
+			return
+		}
+
 		s.errorHandler.Unauthenticated(ctx, err)
 		return
 	}
@@ -382,22 +490,21 @@ func (s *Auth[T]) verify(ctx stdContext.Context, token []byte) (T, StandardClaim
 		return t, StandardClaims{}, jwt.ErrMissing
 	}
 
-	verifiedToken, err := jwt.VerifyWithHeaderValidator(nil, nil, token, s.keys.ValidateHeader, jwt.Future(time.Minute), jwt.Leeway(time.Minute))
+	verifiedToken, err := jwt.VerifyWithHeaderValidator(nil, nil, token, s.validateHeader, jwt.Future(time.Minute), jwt.Leeway(time.Minute))
 	if err != nil {
 		return t, StandardClaims{}, err
 	}
 
-	if s.transformer != nil {
-		if t, err = s.transformer.Transform(ctx, verifiedToken); err != nil {
-			return t, StandardClaims{}, err
-		}
-	} else {
-		if err = verifiedToken.Claims(&t); err != nil {
-			return t, StandardClaims{}, err
+	standardClaims := verifiedToken.StandardClaims
+	for _, aud := range standardClaims.Audience {
+		if aud == mfaPendingAudience {
+			return t, StandardClaims{}, ErrMFAPendingToken
 		}
 	}
 
-	standardClaims := verifiedToken.StandardClaims
+	if t, err = s.claims(ctx, verifiedToken); err != nil {
+		return t, StandardClaims{}, err
+	}
 
 	if n := len(s.providers); n > 0 {
 		for i := 0; i < n; i++ {
@@ -422,6 +529,16 @@ func (s *Auth[T]) verify(ctx stdContext.Context, token []byte) (T, StandardClaim
 	return t, standardClaims, nil
 }
 
+// claims returns the T user of a verified token.
+func (s *Auth[T]) claims(ctx stdContext.Context, verifiedToken *VerifiedToken) (t T, err error) {
+	if s.transformer != nil {
+		return s.transformer.Transform(ctx, verifiedToken)
+	}
+
+	err = verifiedToken.Claims(&t)
+	return
+}
+
 // VerifyHandler verifies and sets the necessary information about the user(claims) and
 // the verified token to the Iris Context and calls the Context's Next method.
 // This information is available through auth.GetAccessToken, auth.GetStandardClaims and
@@ -476,14 +593,16 @@ func (s *Auth[T]) Refresh(ctx stdContext.Context, refreshToken []byte) ([]byte,
 		return nil, nil, fmt.Errorf("auth: refresh: disabled")
 	}
 
-	t, _, err := s.verify(ctx, refreshToken)
+	t, claims, err := s.verify(ctx, refreshToken)
 	if err != nil {
 		return nil, nil, fmt.Errorf("auth: refresh: %w", err)
 	}
 
 	// refresh the tokens, both refresh & access tokens will be renew to prevent
 	// malicious 😈 users that may hold a refresh token.
-	accessTok, refreshTok, err := s.sign(t)
+	// If refresh token rotation is enabled then the used refresh token
+	// can not be used again, see SetRefreshTokenStore.
+	accessTok, refreshTok, err := s.sign(ctx, t, claims.ID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("auth: refresh: %w", err)
 	}
@@ -553,6 +672,12 @@ func (s *Auth[T]) Signout(ctx stdContext.Context, token []byte, all bool) error
 		break
 	}
 
+	if all {
+		if err = s.revokeRefreshTokens(ctx, t); err != nil {
+			return fmt.Errorf("auth: signout: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -40,6 +40,9 @@ type (
 		// Keys MUST define the jwt keys configuration for access,
 		// and optionally, for refresh tokens signing and verification.
 		Keys jwt.KeysConfiguration `json:"keys" yaml:"Keys" toml:"Keys" ini:"keys"`
+		// MFA optional configuration of the multi-factor authentication,
+		// see the Auth.SetMFAStore method.
+		MFA MFAConfiguration `json:"mfa" yaml:"MFA" toml:"MFA" ini:"mfa"`
 	}
 
 	// CookieConfiguration holds the necessary information for cookie client storage.
@@ -61,6 +64,30 @@ type (
 		// 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
 		Block string `json:"block" yaml:"Block" toml:"Block" ini:"block"`
 	}
+
+	// MFAConfiguration holds the necessary information for the multi-factor authentication.
+	MFAConfiguration struct {
+		// Issuer is the name of the application which authenticator apps display
+		// next to the user's account, see the TOTPURI package-level function.
+		Issuer string `json:"issuer" yaml:"Issuer" toml:"Issuer" ini:"issuer"`
+		// PendingMaxAge is the lifetime of the "mfa-pending" token which is sent on sign in
+		// to the users that should complete the second factor.
+		//
+		// Defaults to 5 minutes.
+		PendingMaxAge time.Duration `json:"pending_max_age" yaml:"PendingMaxAge" toml:"PendingMaxAge" ini:"pending_max_age"`
+		// Skew is the number of TOTP time steps before and after the current one
+		// which are accepted to allow for clock drift.
+		//
+		// Defaults to 1, a negative value accepts the current time step only.
+		Skew int `json:"skew" yaml:"Skew" toml:"Skew" ini:"skew"`
+		// PendingSecret is the HMAC secret which signs and verifies the "mfa-pending" tokens.
+		// It's never published and these tokens are not signed by the access token's key,
+		// so other verifiers do not accept them as access tokens.
+		// Set it when more than one instance of the application serve the same users.
+		//
+		// Defaults to a random secret per Auth instance.
+		PendingSecret string `json:"pending_secret" yaml:"PendingSecret" toml:"PendingSecret" ini:"pending_secret"`
+	}
 )
 
 func (c *Configuration) validate() (jwt.Keys, error) {
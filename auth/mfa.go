@@ -0,0 +1,609 @@
+//go:build go1.18
+// +build go1.18
+
+package auth
+
+import (
+	stdContext "context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+	jwtmiddleware "github.com/kataras/iris/v12/middleware/jwt"
+
+	"github.com/google/uuid"
+	"github.com/kataras/jwt"
+)
+
+// mfaPendingAudience is the audience of the "mfa-pending" tokens,
+// they are not accepted as access or refresh tokens.
+const mfaPendingAudience = "iris.auth.mfa_pending"
+
+// kidMFAPending is the key id of the "mfa-pending" tokens.
+const kidMFAPending = "IRIS_AUTH_MFA_PENDING"
+
+// mfaRecoveryCodes is the number of the recovery codes of an enrollment.
+const mfaRecoveryCodes = 10
+
+var (
+	// ErrMFAInvalidCode is returned when a TOTP or a recovery code is not valid.
+	ErrMFAInvalidCode = errors.New("auth: mfa: invalid code")
+	// ErrMFANotEnrolled is returned when a user completes the second factor
+	// without a TOTP secret.
+	ErrMFANotEnrolled = errors.New("auth: mfa: not enrolled")
+	// ErrMFAPendingToken is returned when an "mfa-pending" token
+	// is used as an access or refresh token.
+	ErrMFAPendingToken = errors.New("auth: mfa: pending token")
+)
+
+// MFARequiredError is returned by the Auth.Signin method when the user
+// has to complete the second factor. Its Token is the short-lived "mfa-pending" token
+// which is exchanged for the access and refresh tokens through the Auth.VerifyMFA method.
+type MFARequiredError struct {
+	Token []byte
+}
+
+// Error implements the error interface.
+func (e *MFARequiredError) Error() string {
+	return "auth: mfa required"
+}
+
+type (
+	// MFAStore is the interface which keeps the TOTP secrets and the hashed
+	// recovery codes of the users, by their subject. A new enrollment is kept
+	// next to the active secret and recovery codes until it's confirmed.
+	//
+	// See the Auth.SetMFAStore method and the NewMemoryMFAStore package-level function.
+	MFAStore interface {
+		// Secret returns the TOTP secret of the subject and reports whether
+		// the multi-factor authentication is enabled for the subject.
+		// It should return an empty secret if the subject is not enrolled.
+		Secret(ctx stdContext.Context, subject string) (secret string, enabled bool, err error)
+		// SetSecret sets the TOTP secret of the subject.
+		SetSecret(ctx stdContext.Context, subject, secret string, enabled bool) error
+		// SetRecoveryCodes replaces the hashed recovery codes of the subject.
+		SetRecoveryCodes(ctx stdContext.Context, subject string, hashes []string) error
+		// PendingEnrollment returns the TOTP secret and the hashed recovery codes
+		// of the subject's enrollment which is not confirmed yet.
+		// It should return an empty secret if there is no such enrollment.
+		PendingEnrollment(ctx stdContext.Context, subject string) (secret string, hashes []string, err error)
+		// SetPendingEnrollment sets the TOTP secret and the hashed recovery codes
+		// of a new enrollment of the subject, without modifying the active ones.
+		// An empty secret removes the pending enrollment.
+		SetPendingEnrollment(ctx stdContext.Context, subject, secret string, hashes []string) error
+		// UseRecoveryCode removes the hashed recovery code of the subject
+		// and reports whether it existed. It must be atomic.
+		UseRecoveryCode(ctx stdContext.Context, subject, hash string) (bool, error)
+		// UseStep records the TOTP time step of an accepted code and reports
+		// whether it's later than the last recorded one, so a code cannot be used twice.
+		// It must be atomic.
+		UseStep(ctx stdContext.Context, subject string, step int64) (bool, error)
+		// Delete removes the secret, the recovery codes, the pending enrollment
+		// and the time step of the subject.
+		Delete(ctx stdContext.Context, subject string) error
+	}
+
+	// MFAEnrollment is the result of the Auth.EnrollMFA method.
+	// The secret, its URI (usually presented as a QR code) and the recovery codes
+	// should be shown to the user once.
+	MFAEnrollment struct {
+		Secret        string   `json:"secret"`
+		URI           string   `json:"uri"`
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	// MFARequest is the request body the server expects on MFAHandler and ConfirmMFAHandler.
+	// The MFAHandler expects the MFAToken and the Code or the RecoveryCode.
+	// The ConfirmMFAHandler expects the Code.
+	MFARequest struct {
+		MFAToken     string `json:"mfa_token,omitempty" form:"mfa_token,omitempty"`
+		Code         string `json:"code,omitempty" form:"code,omitempty"`
+		RecoveryCode string `json:"recovery_code,omitempty" form:"recovery_code,omitempty"`
+	}
+
+	// MFAPendingResponse is the response body the server sends
+	// on SigninHandler and OIDC.CallbackHandler when the user has to complete the second factor.
+	MFAPendingResponse struct {
+		MFARequired bool   `json:"mfa_required"`
+		MFAToken    string `json:"mfa_token"`
+		// ExpiresIn is the lifetime of the MFAToken in seconds.
+		ExpiresIn int64 `json:"expires_in"`
+	}
+)
+
+// SetMFAStore enables the multi-factor authentication and returns this Auth of T instance.
+// The users that have confirmed their enrollment (see EnrollMFA and ConfirmMFA methods)
+// receive a short-lived "mfa-pending" token from the SigninHandler (and the CallbackHandler
+// of an OIDC sign in, the OpenID Provider's second factor is not trusted), instead of the access and
+// refresh tokens, which they exchange through the MFAHandler by submitting an RFC 6238 TOTP code
+// or a one-time recovery code. See the Configuration.MFA field too.
+//
+// The "subject" function returns the identifier of a T user.
+//
+// Consider limiting the requests of the MFAHandler route, e.g. through the rate middleware.
+//
+// Usage:
+//
+//	s.SetMFAStore(auth.NewMemoryMFAStore(), func(u User) string { return u.ID })
+//	app.Post("/signin", s.SigninHandler)
+//	app.Post("/signin/mfa", s.MFAHandler)
+//	protected := app.Party("/", s.VerifyHandler())
+//	protected.Post("/mfa/enroll", s.EnrollMFAHandler)
+//	protected.Post("/mfa/confirm", s.ConfirmMFAHandler)
+//
+// The "mfa-pending" tokens are signed by the Configuration.MFA.PendingSecret,
+// set it when more than one instance of the application serve the same users.
+func (s *Auth[T]) SetMFAStore(store MFAStore, subject func(t T) string) *Auth[T] {
+	secret := []byte(s.config.MFA.PendingSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(fmt.Sprintf("auth: mfa: pending secret: %v", err))
+		}
+	}
+
+	s.mfaKeys = make(jwt.Keys)
+	s.mfaKeys.Register(jwt.HS256, kidMFAPending, secret, secret)
+	s.mfaStore = store
+	s.mfaSubject = subject
+	return s
+}
+
+func (s *Auth[T]) mfaPendingMaxAge() time.Duration {
+	if maxAge := s.config.MFA.PendingMaxAge; maxAge > 0 {
+		return maxAge
+	}
+
+	return 5 * time.Minute
+}
+
+func (s *Auth[T]) mfaSkew() int {
+	switch skew := s.config.MFA.Skew; {
+	case skew < 0:
+		return 0
+	case skew == 0:
+		return 1
+	default:
+		return skew
+	}
+}
+
+// mfaRequired reports whether the "t" user has to complete the second factor.
+func (s *Auth[T]) mfaRequired(ctx stdContext.Context, t T) (bool, error) {
+	if s.mfaStore == nil {
+		return false, nil
+	}
+
+	secret, enabled, err := s.mfaStore.Secret(ctx, s.mfaSubject(t))
+	if err != nil {
+		return false, err
+	}
+
+	return enabled && secret != "", nil
+}
+
+// writeMFAPending sends the "mfa-pending" token as JSON body of `MFAPendingResponse`.
+func (s *Auth[T]) writeMFAPending(ctx *context.Context, mfaToken []byte) {
+	ctx.JSON(MFAPendingResponse{
+		MFARequired: true,
+		MFAToken:    jwt.BytesToString(mfaToken),
+		ExpiresIn:   int64(s.mfaPendingMaxAge().Seconds()),
+	})
+}
+
+// mfaPendingHeader is the header of the "mfa-pending" tokens.
+type mfaPendingHeader struct {
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// signMFAPending signs a short-lived "mfa-pending" token for "t".
+// It's signed by its own key, not the access token's one,
+// and its type is rejected by the jwt middleware's verifiers.
+func (s *Auth[T]) signMFAPending(t T) ([]byte, error) {
+	now := jwt.Clock()
+	claims := StandardClaims{
+		ID:       uuid.NewString(),
+		IssuedAt: now.Unix(),
+		Expiry:   now.Add(s.mfaPendingMaxAge()).Unix(),
+		Audience: jwt.Audience{mfaPendingAudience},
+	}
+
+	key, ok := s.mfaKeys.Get(kidMFAPending)
+	if !ok {
+		return nil, jwt.ErrUnknownKid
+	}
+
+	header := mfaPendingHeader{
+		Kid: kidMFAPending,
+		Alg: key.Alg.Name(),
+		Typ: jwtmiddleware.MFAPendingType,
+	}
+
+	return jwt.SignWithHeader(key.Alg, key.Private, t, header, claims)
+}
+
+// VerifyMFA exchanges an "mfa-pending" token, see MFARequiredError,
+// for a pair of access and refresh tokens when the TOTP "code"
+// or the one-time "recoveryCode" of the user is valid.
+func (s *Auth[T]) VerifyMFA(ctx stdContext.Context, mfaToken []byte, code, recoveryCode string) ([]byte, []byte, error) {
+	if s.mfaStore == nil {
+		return nil, nil, fmt.Errorf("auth: mfa: disabled")
+	}
+
+	verifiedToken, err := jwt.VerifyWithHeaderValidator(nil, nil, mfaToken, s.mfaKeys.ValidateHeader,
+		jwt.Expected{Audience: jwt.Audience{mfaPendingAudience}}, jwt.Leeway(time.Second))
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	t, err := s.claims(ctx, verifiedToken)
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	subject := s.mfaSubject(t)
+
+	if recoveryCode != "" {
+		ok, err := s.mfaStore.UseRecoveryCode(ctx, subject, HashRecoveryCode(recoveryCode))
+		if err != nil {
+			return nil, nil, fmt.Errorf("auth: mfa: %w", err)
+		}
+
+		if !ok {
+			return nil, nil, ErrMFAInvalidCode
+		}
+	} else if err = s.validateTOTP(ctx, subject, code); err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, refreshToken, err := s.sign(ctx, t, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// validateTOTP validates the TOTP "code" of the subject's active secret, once.
+func (s *Auth[T]) validateTOTP(ctx stdContext.Context, subject, code string) error {
+	secret, enabled, err := s.mfaStore.Secret(ctx, subject)
+	if err != nil {
+		return fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	if secret == "" || !enabled {
+		return ErrMFANotEnrolled
+	}
+
+	step, ok := ValidateTOTP(secret, code, jwt.Clock(), s.mfaSkew())
+	if !ok {
+		return ErrMFAInvalidCode
+	}
+
+	if ok, err = s.mfaStore.UseStep(ctx, subject, step); err != nil {
+		return fmt.Errorf("auth: mfa: %w", err)
+	} else if !ok {
+		return ErrMFAInvalidCode // replay.
+	}
+
+	return nil
+}
+
+// MFAHandler reads the request body which should include data for `MFARequest` structure
+// and sends a new access and refresh token pair as JSON body of `SigninResponse`,
+// also sets the cookie to the new encrypted access token value.
+// See `VerifyMFA` method for more.
+func (s *Auth[T]) MFAHandler(ctx *context.Context) {
+	req, ok := s.readMFARequest(ctx)
+	if !ok {
+		return
+	}
+
+	accessTokenBytes, refreshTokenBytes, err := s.VerifyMFA(ctx, []byte(req.MFAToken), req.Code, req.RecoveryCode)
+	if err != nil {
+		s.errorHandler.Unauthenticated(ctx, err)
+		return
+	}
+	accessToken := jwt.BytesToString(accessTokenBytes)
+	refreshToken := jwt.BytesToString(refreshTokenBytes)
+
+	s.trySetCookie(ctx, accessToken)
+
+	resp := SigninResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+	ctx.JSON(resp)
+}
+
+func (s *Auth[T]) readMFARequest(ctx *context.Context) (req MFARequest, ok bool) {
+	var err error
+
+	switch ctx.GetContentTypeRequested() {
+	case context.ContentFormHeaderValue, context.ContentFormMultipartHeaderValue:
+		err = ctx.ReadForm(&req)
+	default:
+		err = ctx.ReadJSON(&req)
+	}
+
+	if err != nil {
+		s.errorHandler.InvalidArgument(ctx, err)
+		return
+	}
+
+	return req, true
+}
+
+// EnrollMFA generates a new TOTP secret and new recovery codes for the "t" user.
+// The "account" is the user's name or email which authenticator apps display.
+// The new secret and recovery codes are not used until the user
+// confirms the enrollment with a valid code, see the ConfirmMFA method,
+// so a re-enrollment keeps the active ones enforced until then.
+func (s *Auth[T]) EnrollMFA(ctx stdContext.Context, t T, account string) (MFAEnrollment, error) {
+	if s.mfaStore == nil {
+		return MFAEnrollment{}, fmt.Errorf("auth: mfa: disabled")
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return MFAEnrollment{}, fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	recoveryCodes, err := GenerateRecoveryCodes(mfaRecoveryCodes)
+	if err != nil {
+		return MFAEnrollment{}, fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	hashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = HashRecoveryCode(code)
+	}
+
+	if err = s.mfaStore.SetPendingEnrollment(ctx, s.mfaSubject(t), secret, hashes); err != nil {
+		return MFAEnrollment{}, fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	enrollment := MFAEnrollment{
+		Secret:        secret,
+		URI:           TOTPURI(s.config.MFA.Issuer, account, secret),
+		RecoveryCodes: recoveryCodes,
+	}
+
+	return enrollment, nil
+}
+
+// ConfirmMFA enables the multi-factor authentication of the "t" user
+// if the TOTP "code" is valid for the secret of its pending enrollment.
+// The secret and the recovery codes of the enrollment replace the active ones.
+func (s *Auth[T]) ConfirmMFA(ctx stdContext.Context, t T, code string) error {
+	if s.mfaStore == nil {
+		return fmt.Errorf("auth: mfa: disabled")
+	}
+
+	subject := s.mfaSubject(t)
+	secret, hashes, err := s.mfaStore.PendingEnrollment(ctx, subject)
+	if err != nil {
+		return fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	if secret == "" {
+		return ErrMFANotEnrolled
+	}
+
+	step, ok := ValidateTOTP(secret, code, jwt.Clock(), s.mfaSkew())
+	if !ok {
+		return ErrMFAInvalidCode
+	}
+
+	if err = s.mfaStore.SetSecret(ctx, subject, secret, true); err != nil {
+		return fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	if err = s.mfaStore.SetRecoveryCodes(ctx, subject, hashes); err != nil {
+		return fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	if err = s.mfaStore.SetPendingEnrollment(ctx, subject, "", nil); err != nil {
+		return fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	// The code of the confirmation cannot be used again.
+	if _, err = s.mfaStore.UseStep(ctx, subject, step); err != nil {
+		return fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	return nil
+}
+
+// DisableMFA removes the TOTP secret and the recovery codes of the "t" user.
+func (s *Auth[T]) DisableMFA(ctx stdContext.Context, t T) error {
+	if s.mfaStore == nil {
+		return fmt.Errorf("auth: mfa: disabled")
+	}
+
+	if err := s.mfaStore.Delete(ctx, s.mfaSubject(t)); err != nil {
+		return fmt.Errorf("auth: mfa: %w", err)
+	}
+
+	return nil
+}
+
+// EnrollMFAHandler sends a new `MFAEnrollment` to the signed in user.
+// It should be registered after the VerifyHandler.
+// The account name of the TOTP URI is the user's subject.
+// See `EnrollMFA` method for more.
+func (s *Auth[T]) EnrollMFAHandler(ctx *context.Context) {
+	t := s.GetUser(ctx)
+
+	enrollment, err := s.EnrollMFA(ctx, t, s.mfaSubject(t))
+	if err != nil {
+		ctx.StopWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(enrollment)
+}
+
+// ConfirmMFAHandler reads the request body which should include the Code of the `MFARequest` structure
+// and enables the multi-factor authentication of the signed in user.
+// It should be registered after the VerifyHandler.
+// See `ConfirmMFA` method for more.
+func (s *Auth[T]) ConfirmMFAHandler(ctx *context.Context) {
+	req, ok := s.readMFARequest(ctx)
+	if !ok {
+		return
+	}
+
+	if err := s.ConfirmMFA(ctx, s.GetUser(ctx), req.Code); err != nil {
+		s.errorHandler.InvalidArgument(ctx, err)
+		return
+	}
+
+	ctx.StatusCode(http.StatusNoContent)
+}
+
+type (
+	memoryMFAEntry struct {
+		secret        string
+		enabled       bool
+		recoveryCodes map[string]struct{}
+		lastStep      int64
+
+		pendingSecret        string
+		pendingRecoveryCodes []string
+	}
+
+	// MemoryMFAStore is an in-memory MFAStore.
+	// Initialize it through the NewMemoryMFAStore package-level function.
+	MemoryMFAStore struct {
+		mu      sync.Mutex
+		entries map[string]*memoryMFAEntry
+	}
+)
+
+var _ MFAStore = (*MemoryMFAStore)(nil)
+
+// NewMemoryMFAStore returns a new in-memory MFAStore.
+// The secrets are lost on restart, it's useful for tests and examples,
+// a persistent MFAStore should be used in production.
+func NewMemoryMFAStore() *MemoryMFAStore {
+	return &MemoryMFAStore{
+		entries: make(map[string]*memoryMFAEntry),
+	}
+}
+
+func (m *MemoryMFAStore) entry(subject string) *memoryMFAEntry {
+	e, ok := m.entries[subject]
+	if !ok {
+		e = &memoryMFAEntry{recoveryCodes: make(map[string]struct{})}
+		m.entries[subject] = e
+	}
+
+	return e
+}
+
+// Secret returns the TOTP secret of the subject and reports whether it's enabled.
+func (m *MemoryMFAStore) Secret(ctx stdContext.Context, subject string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[subject]
+	if !ok {
+		return "", false, nil
+	}
+
+	return e.secret, e.enabled, nil
+}
+
+// SetSecret sets the TOTP secret of the subject.
+func (m *MemoryMFAStore) SetSecret(ctx stdContext.Context, subject, secret string, enabled bool) error {
+	m.mu.Lock()
+	e := m.entry(subject)
+	if e.secret != secret {
+		e.lastStep = 0
+	}
+	e.secret = secret
+	e.enabled = enabled
+	m.mu.Unlock()
+	return nil
+}
+
+// SetRecoveryCodes replaces the hashed recovery codes of the subject.
+func (m *MemoryMFAStore) SetRecoveryCodes(ctx stdContext.Context, subject string, hashes []string) error {
+	m.mu.Lock()
+	e := m.entry(subject)
+	e.recoveryCodes = make(map[string]struct{}, len(hashes))
+	for _, hash := range hashes {
+		e.recoveryCodes[hash] = struct{}{}
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// PendingEnrollment returns the TOTP secret and the hashed recovery codes of the subject's pending enrollment.
+func (m *MemoryMFAStore) PendingEnrollment(ctx stdContext.Context, subject string) (string, []string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[subject]
+	if !ok {
+		return "", nil, nil
+	}
+
+	return e.pendingSecret, e.pendingRecoveryCodes, nil
+}
+
+// SetPendingEnrollment sets the TOTP secret and the hashed recovery codes of the subject's pending enrollment.
+func (m *MemoryMFAStore) SetPendingEnrollment(ctx stdContext.Context, subject, secret string, hashes []string) error {
+	m.mu.Lock()
+	e := m.entry(subject)
+	e.pendingSecret = secret
+	e.pendingRecoveryCodes = append([]string(nil), hashes...)
+	m.mu.Unlock()
+	return nil
+}
+
+// UseRecoveryCode removes the hashed recovery code of the subject and reports whether it existed.
+func (m *MemoryMFAStore) UseRecoveryCode(ctx stdContext.Context, subject, hash string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[subject]
+	if !ok {
+		return false, nil
+	}
+
+	if _, ok = e.recoveryCodes[hash]; ok {
+		delete(e.recoveryCodes, hash)
+	}
+
+	return ok, nil
+}
+
+// UseStep records the TOTP time step of an accepted code.
+func (m *MemoryMFAStore) UseStep(ctx stdContext.Context, subject string, step int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.entry(subject)
+	if step <= e.lastStep {
+		return false, nil
+	}
+
+	e.lastStep = step
+	return true, nil
+}
+
+// Delete removes the secret, the recovery codes, the pending enrollment and the time step of the subject.
+func (m *MemoryMFAStore) Delete(ctx stdContext.Context, subject string) error {
+	m.mu.Lock()
+	delete(m.entries, subject)
+	m.mu.Unlock()
+	return nil
+}
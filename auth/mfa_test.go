@@ -0,0 +1,177 @@
+//go:build go1.18
+// +build go1.18
+
+package auth_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/auth"
+	"github.com/kataras/iris/v12/httptest"
+	jwtmiddleware "github.com/kataras/iris/v12/middleware/jwt"
+
+	"github.com/kataras/jwt"
+)
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 Appendix B, SHA-1 test vectors truncated to 6 digits.
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := auth.TOTP(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code != tt.code {
+			t.Fatalf("[%d] expected code: %s but got: %s", tt.unix, tt.code, code)
+		}
+
+		if _, ok := auth.ValidateTOTP(secret, tt.code, time.Unix(tt.unix+auth.TOTPPeriod, 0), 1); !ok {
+			t.Fatalf("[%d] expected code to be valid in the skew window", tt.unix)
+		}
+
+		if _, ok := auth.ValidateTOTP(secret, tt.code, time.Unix(tt.unix+3*auth.TOTPPeriod, 0), 1); ok {
+			t.Fatalf("[%d] expected code to be invalid out of the skew window", tt.unix)
+		}
+	}
+
+	uri, err := url.Parse(auth.TOTPURI("Iris", "kataras@example.com", secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "otpauth://totp/Iris:kataras@example.com", uri.Scheme+"://"+uri.Host+uri.Path; expected != got {
+		t.Fatalf("expected uri: %s but got: %s", expected, got)
+	}
+
+	if expected, got := secret, uri.Query().Get("secret"); expected != got {
+		t.Fatalf("expected secret: %s but got: %s", expected, got)
+	}
+}
+
+func TestMFA(t *testing.T) {
+	config := auth.MustGenerateConfiguration()
+	s := auth.Must(auth.New[refreshUser](config)).
+		AddProvider(new(refreshProvider)).
+		SetMFAStore(auth.NewMemoryMFAStore(), func(u refreshUser) string { return u.ID })
+
+	app := iris.New()
+	app.Post("/signin", s.SigninHandler)
+	app.Post("/signin/mfa", s.MFAHandler)
+	protected := app.Party("/", s.VerifyHandler())
+	protected.Get("/me", func(ctx iris.Context) {
+		ctx.JSON(s.GetUser(ctx))
+	})
+	protected.Post("/mfa/enroll", s.EnrollMFAHandler)
+	protected.Post("/mfa/confirm", s.ConfirmMFAHandler)
+
+	e := httptest.New(t, app)
+
+	signin := iris.Map{"username": "kataras", "password": "pass"}
+
+	// not enrolled, single step.
+	accessToken := e.POST("/signin").WithJSON(signin).Expect().Status(iris.StatusOK).
+		JSON().Object().Value("access_token").String().Raw()
+
+	var enrollment auth.MFAEnrollment
+	e.POST("/mfa/enroll").WithHeader("Authorization", "Bearer "+accessToken).Expect().
+		Status(iris.StatusOK).JSON().Decode(&enrollment)
+
+	if expected, got := 10, len(enrollment.RecoveryCodes); expected != got {
+		t.Fatalf("expected %d recovery codes but got %d", expected, got)
+	}
+
+	// enrollment is not confirmed yet.
+	e.POST("/signin").WithJSON(signin).Expect().Status(iris.StatusOK).
+		JSON().Object().NotContainsKey("mfa_token")
+
+	code, _ := auth.TOTP(enrollment.Secret, time.Now())
+	e.POST("/mfa/confirm").WithHeader("Authorization", "Bearer "+accessToken).
+		WithJSON(iris.Map{"code": "000000"}).Expect().Status(iris.StatusBadRequest)
+	e.POST("/mfa/confirm").WithHeader("Authorization", "Bearer "+accessToken).
+		WithJSON(iris.Map{"code": code}).Expect().Status(iris.StatusNoContent)
+
+	// second factor required.
+	pending := e.POST("/signin").WithJSON(signin).Expect().Status(iris.StatusOK).JSON().Object()
+	pending.Value("mfa_required").Boolean().IsTrue()
+	mfaToken := pending.Value("mfa_token").String().Raw()
+
+	// the mfa-pending token is not an access token.
+	e.GET("/me").WithHeader("Authorization", "Bearer "+mfaToken).Expect().Status(iris.StatusUnauthorized)
+
+	// nor for other verifiers of the access token's key.
+	keys, err := config.Keys.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := jwtmiddleware.NewVerifier(nil, nil)
+	verifier.HeaderValidator = keys.ValidateHeader
+	if _, err = verifier.VerifyToken([]byte(mfaToken)); err == nil {
+		t.Fatal("expected the mfa-pending token to be rejected by the access token's verifier")
+	}
+
+	// the code of the confirmation can not be used again.
+	e.POST("/signin/mfa").WithJSON(iris.Map{"mfa_token": mfaToken, "code": code}).
+		Expect().Status(iris.StatusUnauthorized)
+
+	// recovery codes are single-use.
+	e.POST("/signin/mfa").WithJSON(iris.Map{"mfa_token": mfaToken, "recovery_code": enrollment.RecoveryCodes[0]}).
+		Expect().Status(iris.StatusOK).JSON().Object().ContainsKey("access_token")
+	e.POST("/signin/mfa").WithJSON(iris.Map{"mfa_token": mfaToken, "recovery_code": enrollment.RecoveryCodes[0]}).
+		Expect().Status(iris.StatusUnauthorized)
+
+	// the code of the next time step.
+	now := time.Now().Add(auth.TOTPPeriod * time.Second)
+	jwt.Clock = func() time.Time { return now }
+	defer func() { jwt.Clock = time.Now }()
+
+	code, _ = auth.TOTP(enrollment.Secret, now)
+	accessToken = e.POST("/signin/mfa").WithJSON(iris.Map{"mfa_token": mfaToken, "code": code}).
+		Expect().Status(iris.StatusOK).JSON().Object().Value("access_token").String().Raw()
+
+	e.GET("/me").WithHeader("Authorization", "Bearer "+accessToken).Expect().Status(iris.StatusOK).
+		JSON().IsEqual(refreshUser{ID: "kataras"})
+
+	// a new enrollment keeps the active secret and recovery codes enforced until it's confirmed.
+	var reenrollment auth.MFAEnrollment
+	e.POST("/mfa/enroll").WithHeader("Authorization", "Bearer "+accessToken).Expect().
+		Status(iris.StatusOK).JSON().Decode(&reenrollment)
+
+	pending = e.POST("/signin").WithJSON(signin).Expect().Status(iris.StatusOK).JSON().Object()
+	pending.Value("mfa_required").Boolean().IsTrue()
+	mfaToken = pending.Value("mfa_token").String().Raw()
+
+	e.POST("/signin/mfa").WithJSON(iris.Map{"mfa_token": mfaToken, "recovery_code": reenrollment.RecoveryCodes[0]}).
+		Expect().Status(iris.StatusUnauthorized)
+	e.POST("/signin/mfa").WithJSON(iris.Map{"mfa_token": mfaToken, "recovery_code": enrollment.RecoveryCodes[1]}).
+		Expect().Status(iris.StatusOK)
+
+	// the confirmation replaces them.
+	now = now.Add(auth.TOTPPeriod * time.Second)
+	code, _ = auth.TOTP(reenrollment.Secret, now)
+	e.POST("/mfa/confirm").WithHeader("Authorization", "Bearer "+accessToken).
+		WithJSON(iris.Map{"code": code}).Expect().Status(iris.StatusNoContent)
+
+	pending = e.POST("/signin").WithJSON(signin).Expect().Status(iris.StatusOK).JSON().Object()
+	pending.Value("mfa_required").Boolean().IsTrue()
+	mfaToken = pending.Value("mfa_token").String().Raw()
+
+	e.POST("/signin/mfa").WithJSON(iris.Map{"mfa_token": mfaToken, "recovery_code": enrollment.RecoveryCodes[2]}).
+		Expect().Status(iris.StatusUnauthorized)
+	e.POST("/signin/mfa").WithJSON(iris.Map{"mfa_token": mfaToken, "recovery_code": reenrollment.RecoveryCodes[0]}).
+		Expect().Status(iris.StatusOK)
+}
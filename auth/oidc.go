@@ -0,0 +1,522 @@
+//go:build go1.18
+// +build go1.18
+
+package auth
+
+import (
+	stdContext "context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+	jwtmiddleware "github.com/kataras/iris/v12/middleware/jwt"
+
+	"github.com/kataras/jwt"
+)
+
+type (
+	// OIDCConfiguration holds the necessary information for an OpenID Connect
+	// (or OAuth 2.0 with ID tokens) relying party which signs users in
+	// through the authorization code flow with PKCE.
+	//
+	// See the `NewOIDC` package-level function.
+	OIDCConfiguration struct {
+		// Issuer is the URL of the OpenID Provider, e.g. "https://accounts.google.com".
+		// Its discovery document is fetched from Issuer + "/.well-known/openid-configuration".
+		Issuer string `json:"issuer" yaml:"Issuer" toml:"Issuer" ini:"issuer"`
+		// ClientID is the client identifier registered at the OpenID Provider.
+		ClientID string `json:"client_id" yaml:"ClientID" toml:"ClientID" ini:"client_id"`
+		// ClientSecret is optional, public clients rely on PKCE only.
+		ClientSecret string `json:"client_secret" yaml:"ClientSecret" toml:"ClientSecret" ini:"client_secret"`
+		// RedirectURL is the full URL of the route which serves the CallbackHandler.
+		RedirectURL string `json:"redirect_url" yaml:"RedirectURL" toml:"RedirectURL" ini:"redirect_url"`
+		// PostLogoutRedirectURL is optional, the URL which the OpenID Provider
+		// redirects the user to after the LogoutHandler.
+		PostLogoutRedirectURL string `json:"post_logout_redirect_url" yaml:"PostLogoutRedirectURL" toml:"PostLogoutRedirectURL" ini:"post_logout_redirect_url"`
+		// Scopes of the authorization request.
+		//
+		// Defaults to:
+		// - openid
+		// - profile
+		// - email
+		Scopes []string `json:"scopes" yaml:"Scopes" toml:"Scopes" ini:"scopes"`
+		// HTTPClient is the client which talks to the OpenID Provider,
+		// e.g. to a local stand-in issuer when testing.
+		//
+		// Defaults to a client with a 15 seconds timeout.
+		HTTPClient *http.Client `json:"-" yaml:"-" toml:"-" ini:"-"`
+	}
+
+	// OIDCProviderMetadata holds the fields of the OpenID Provider's discovery document
+	// which the relying party uses.
+	OIDCProviderMetadata struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint,omitempty"`
+		JWKSURI               string `json:"jwks_uri"`
+		EndSessionEndpoint    string `json:"end_session_endpoint,omitempty"`
+	}
+
+	// OIDCTokenResponse is the response body of the OpenID Provider's token endpoint.
+	OIDCTokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		ExpiresIn    int64  `json:"expires_in,omitempty"`
+		IDToken      string `json:"id_token"`
+	}
+
+	// OIDC is an OpenID Connect relying party of an Auth of T instance.
+	// It signs users in through the OpenID Provider and, on success,
+	// it issues the Auth's own access and refresh tokens for the T user
+	// which is mapped from the ID token's claims.
+	//
+	// Initialize it through the `NewOIDC` package-level function
+	// and register its LoginHandler, CallbackHandler and LogoutHandler methods.
+	OIDC[T User] struct {
+		auth   *Auth[T]
+		config OIDCConfiguration
+
+		mu       sync.Mutex
+		metadata *OIDCProviderMetadata
+		jwks     *jwtmiddleware.RemoteJWKS
+
+		mapper Transformer[T]
+	}
+
+	// oidcState is the state of an authorization request, kept in an encrypted cookie
+	// between the LoginHandler and the CallbackHandler.
+	oidcState struct {
+		State        string `json:"state"`
+		Nonce        string `json:"nonce"`
+		CodeVerifier string `json:"code_verifier"`
+		ReturnTo     string `json:"return_to,omitempty"`
+	}
+)
+
+// ErrOIDCState is returned by the CallbackHandler when the "state" of the
+// authorization response does not match the one of the authorization request.
+var ErrOIDCState = errors.New("auth: oidc: invalid state")
+
+const (
+	oidcDiscoveryPath     = "/.well-known/openid-configuration"
+	oidcStateCookieSuffix = "_oidc"
+	oidcStateMaxAge       = 10 * time.Minute
+)
+
+// NewOIDC returns a new OpenID Connect relying party for the given Auth of T instance.
+// The Auth's configuration must define the cookie hash and block keys,
+// they encrypt the state of the authorization requests.
+//
+// By default the ID token's claims are decoded into T,
+// use the SetClaimsMapper method to map them manually.
+//
+// Usage Example:
+//
+//	oidc, err := auth.NewOIDC(s, auth.OIDCConfiguration{
+//	  Issuer:      "https://accounts.google.com",
+//	  ClientID:    "...",
+//	  RedirectURL: "https://example.com/auth/callback",
+//	})
+//	app.Get("/auth/login", oidc.LoginHandler)
+//	app.Get("/auth/callback", oidc.CallbackHandler)
+//	app.Get("/auth/logout", oidc.LogoutHandler)
+func NewOIDC[T User](s *Auth[T], config OIDCConfiguration) (*OIDC[T], error) {
+	if config.Issuer == "" {
+		return nil, fmt.Errorf("auth: oidc: configuration: issuer is required")
+	}
+
+	if config.ClientID == "" {
+		return nil, fmt.Errorf("auth: oidc: configuration: client id is required")
+	}
+
+	if config.RedirectURL == "" {
+		return nil, fmt.Errorf("auth: oidc: configuration: redirect url is required")
+	}
+
+	if s.config.Cookie.Hash == "" || s.config.Cookie.Block == "" {
+		return nil, fmt.Errorf("auth: oidc: configuration: cookie block and cookie hash are required to encrypt the state")
+	}
+
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	o := &OIDC[T]{
+		auth:   s,
+		config: config,
+	}
+
+	return o, nil
+}
+
+// SetClaimsMapper sets a function which converts the verified ID token to a T user value.
+// Defaults to decoding the ID token's claims into T.
+func (o *OIDC[T]) SetClaimsMapper(mapper func(ctx stdContext.Context, idToken *VerifiedToken) (T, error)) *OIDC[T] {
+	o.mapper = TransformerFunc[T](mapper)
+	return o
+}
+
+// Discover fetches the OpenID Provider's discovery document and its keys.
+// It's called automatically on the first request,
+// call it on initialization to fail early on misconfiguration.
+func (o *OIDC[T]) Discover(ctx stdContext.Context) (*OIDCProviderMetadata, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.metadata != nil {
+		return o.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.config.Issuer+oidcDiscoveryPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("auth: oidc: discovery: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("auth: oidc: discovery: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth: oidc: discovery: unexpected status code: %d", resp.StatusCode)
+	}
+
+	var metadata OIDCProviderMetadata
+	if err = json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("auth: oidc: discovery: %w", err)
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != o.config.Issuer {
+		return nil, fmt.Errorf("auth: oidc: discovery: issuer mismatch: %q", metadata.Issuer)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("auth: oidc: discovery: authorization, token and jwks endpoints are required")
+	}
+
+	jwks := jwtmiddleware.NewRemoteJWKS(metadata.JWKSURI)
+	jwks.Client = o.config.HTTPClient
+	if err = jwks.Fetch(ctx); err != nil {
+		return nil, fmt.Errorf("auth: oidc: %w", err)
+	}
+
+	o.metadata = &metadata
+	o.jwks = jwks
+	return o.metadata, nil
+}
+
+// LoginHandler redirects the user to the OpenID Provider's authorization endpoint.
+// The optional "return_to" URL query parameter is a local path which the
+// user is redirected to after a successful sign in.
+func (o *OIDC[T]) LoginHandler(ctx *context.Context) {
+	metadata, err := o.Discover(ctx)
+	if err != nil {
+		ctx.StopWithError(http.StatusBadGateway, err)
+		return
+	}
+
+	state := oidcState{
+		State:        randomString(),
+		Nonce:        randomString(),
+		CodeVerifier: randomString(),
+		ReturnTo:     localPath(ctx.URLParam("return_to")),
+	}
+
+	stateValue, err := json.Marshal(state)
+	if err != nil {
+		ctx.StopWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     o.stateCookieName(),
+		Value:    string(stateValue),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   o.auth.config.Cookie.Secure || ctx.IsSSL(),
+		SameSite: http.SameSiteLaxMode, // sent on the top-level redirect back from the provider.
+		MaxAge:   int(oidcStateMaxAge.Seconds()),
+	}, context.CookieEncoding(o.auth.securecookie), context.CookieAllowReclaim())
+
+	challenge := sha256.Sum256([]byte(state.CodeVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {o.config.ClientID},
+		"redirect_uri":          {o.config.RedirectURL},
+		"scope":                 {strings.Join(o.config.Scopes, " ")},
+		"state":                 {state.State},
+		"nonce":                 {state.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	ctx.Redirect(appendQuery(metadata.AuthorizationEndpoint, query), http.StatusFound)
+}
+
+// CallbackHandler completes the sign in: it validates the state, exchanges the authorization code
+// for the ID token, verifies the ID token and maps its claims to a T user.
+// Then it issues the Auth's own tokens like the Auth.SigninHandler does, the access token
+// is set to the Auth's cookie and the user is redirected to the "return_to" path of the login request,
+// if it was not given then the tokens are sent as JSON body of `SigninResponse`.
+// A user who has enabled the multi-factor authentication, see Auth.SetMFAStore,
+// receives the `MFAPendingResponse` instead and completes the sign in through the Auth.MFAHandler,
+// the second factor of the OpenID Provider is not trusted.
+func (o *OIDC[T]) CallbackHandler(ctx *context.Context) {
+	cookieName := o.stateCookieName()
+	stateValue := ctx.GetCookie(cookieName, context.CookieEncoding(o.auth.securecookie))
+	ctx.RemoveCookie(cookieName)
+
+	var state oidcState
+	if stateValue == "" || json.Unmarshal([]byte(stateValue), &state) != nil ||
+		subtle.ConstantTimeCompare([]byte(state.State), []byte(ctx.URLParam("state"))) != 1 {
+		o.auth.errorHandler.InvalidArgument(ctx, ErrOIDCState)
+		return
+	}
+
+	if errCode := ctx.URLParam("error"); errCode != "" {
+		o.auth.errorHandler.Unauthenticated(ctx, fmt.Errorf("auth: oidc: %s: %s", errCode, ctx.URLParam("error_description")))
+		return
+	}
+
+	code := ctx.URLParam("code")
+	if code == "" {
+		o.auth.errorHandler.InvalidArgument(ctx, fmt.Errorf("auth: oidc: missing code"))
+		return
+	}
+
+	t, err := o.Exchange(ctx, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		o.auth.tryRemoveCookie(ctx)
+		o.auth.errorHandler.Unauthenticated(ctx, err)
+		return
+	}
+
+	// check for the second factor.
+	if required, err := o.auth.mfaRequired(ctx, t); err != nil {
+		o.auth.errorHandler.Unauthenticated(ctx, fmt.Errorf("auth: oidc: %w", err))
+		return
+	} else if required {
+		mfaToken, err := o.auth.signMFAPending(t)
+		if err != nil {
+			o.auth.errorHandler.Unauthenticated(ctx, fmt.Errorf("auth: oidc: %w", err))
+			return
+		}
+
+		o.auth.tryRemoveCookie(ctx)
+		o.auth.writeMFAPending(ctx, mfaToken)
+		return
+	}
+
+	accessTokenBytes, refreshTokenBytes, err := o.auth.sign(ctx, t, "")
+	if err != nil {
+		o.auth.errorHandler.Unauthenticated(ctx, fmt.Errorf("auth: oidc: %w", err))
+		return
+	}
+
+	accessToken := jwt.BytesToString(accessTokenBytes)
+	refreshToken := jwt.BytesToString(refreshTokenBytes)
+
+	o.auth.trySetCookie(ctx, accessToken)
+
+	if state.ReturnTo != "" {
+		ctx.Redirect(state.ReturnTo, http.StatusFound)
+		return
+	}
+
+	ctx.JSON(SigninResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
+}
+
+// Exchange exchanges the authorization "code" for the tokens at the OpenID Provider's token endpoint,
+// verifies the ID token's signature, issuer, audience, expiration and "nonce"
+// and returns the T user mapped from its claims.
+func (o *OIDC[T]) Exchange(ctx stdContext.Context, code, codeVerifier, nonce string) (T, error) {
+	var t T
+
+	metadata, err := o.Discover(ctx)
+	if err != nil {
+		return t, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.config.RedirectURL},
+		"client_id":     {o.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return t, fmt.Errorf("auth: oidc: token: %w", err)
+	}
+	req.Header.Set("Content-Type", context.ContentFormHeaderValue)
+	req.Header.Set("Accept", "application/json")
+	if o.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.config.ClientID), url.QueryEscape(o.config.ClientSecret))
+	}
+
+	resp, err := o.config.HTTPClient.Do(req)
+	if err != nil {
+		return t, fmt.Errorf("auth: oidc: token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return t, fmt.Errorf("auth: oidc: token: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return t, fmt.Errorf("auth: oidc: token: unexpected status code: %d: %s", resp.StatusCode, body)
+	}
+
+	var tokenResp OIDCTokenResponse
+	if err = json.Unmarshal(body, &tokenResp); err != nil {
+		return t, fmt.Errorf("auth: oidc: token: %w", err)
+	}
+
+	if tokenResp.IDToken == "" {
+		return t, fmt.Errorf("auth: oidc: token: missing id_token")
+	}
+
+	idToken, err := o.VerifyIDToken(ctx, []byte(tokenResp.IDToken), nonce)
+	if err != nil {
+		return t, err
+	}
+
+	if o.mapper != nil {
+		t, err = o.mapper.Transform(ctx, idToken)
+	} else {
+		err = idToken.Claims(&t)
+	}
+
+	if err != nil {
+		return t, fmt.Errorf("auth: oidc: claims: %w", err)
+	}
+
+	return t, nil
+}
+
+// VerifyIDToken verifies the ID token's signature through the OpenID Provider's keys,
+// its issuer, audience, expiration and, if not empty, its "nonce" claim.
+func (o *OIDC[T]) VerifyIDToken(ctx stdContext.Context, token []byte, nonce string) (*VerifiedToken, error) {
+	if _, err := o.Discover(ctx); err != nil {
+		return nil, err
+	}
+
+	idToken, err := jwt.VerifyWithHeaderValidator(nil, nil, token, o.jwks.ValidateHeader, jwt.Expected{Issuer: o.config.Issuer})
+	if err != nil {
+		return nil, fmt.Errorf("auth: oidc: id token: %w", err)
+	}
+
+	audienceOK := false
+	for _, aud := range idToken.StandardClaims.Audience {
+		if aud == o.config.ClientID {
+			audienceOK = true
+			break
+		}
+	}
+
+	if !audienceOK {
+		return nil, fmt.Errorf("auth: oidc: id token: %w: aud", jwt.ErrExpected)
+	}
+
+	if nonce != "" {
+		var claims struct {
+			Nonce string `json:"nonce"`
+		}
+
+		if err = idToken.Claims(&claims); err != nil {
+			return nil, fmt.Errorf("auth: oidc: id token: %w", err)
+		}
+
+		if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+			return nil, fmt.Errorf("auth: oidc: id token: %w: nonce", jwt.ErrExpected)
+		}
+	}
+
+	return idToken, nil
+}
+
+// LogoutHandler signs the user out like the Auth.SignoutHandler does and, if the OpenID Provider
+// supports RP-initiated logout, it redirects the user to its end session endpoint.
+func (o *OIDC[T]) LogoutHandler(ctx *context.Context) {
+	o.auth.SignoutHandler(ctx)
+	if ctx.IsStopped() {
+		return
+	}
+
+	metadata, err := o.Discover(ctx)
+	if err != nil || metadata.EndSessionEndpoint == "" {
+		return
+	}
+
+	query := url.Values{"client_id": {o.config.ClientID}}
+	if o.config.PostLogoutRedirectURL != "" {
+		query.Set("post_logout_redirect_uri", o.config.PostLogoutRedirectURL)
+	}
+
+	ctx.Redirect(appendQuery(metadata.EndSessionEndpoint, query), http.StatusFound)
+}
+
+func (o *OIDC[T]) stateCookieName() string {
+	name := o.auth.config.Cookie.Name
+	if name == "" {
+		name = "iris_auth"
+	}
+
+	return name + oidcStateCookieSuffix
+}
+
+// randomString returns a url-safe string of 32 random bytes,
+// suitable for the state, the nonce and the PKCE code verifier.
+func randomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// localPath returns the "p" if it's a local path, otherwise an empty string,
+// to prevent open redirects.
+func localPath(p string) string {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.HasPrefix(p, "/\\") {
+		return ""
+	}
+
+	return p
+}
+
+func appendQuery(endpoint string, query url.Values) string {
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + query.Encode()
+	}
+
+	return endpoint + "?" + query.Encode()
+}
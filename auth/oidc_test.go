@@ -0,0 +1,212 @@
+//go:build go1.18
+// +build go1.18
+
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	stdhttptest "net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/auth"
+	"github.com/kataras/iris/v12/httptest"
+	jwtmiddleware "github.com/kataras/iris/v12/middleware/jwt"
+
+	"github.com/iris-contrib/httpexpect/v2"
+	"github.com/kataras/jwt"
+)
+
+type oidcUser struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email"`
+}
+
+// newTestIssuer returns a local stand-in OpenID Provider which accepts the code "code"
+// if the PKCE code verifier matches the challenge of the last authorization request.
+func newTestIssuer(t *testing.T, clientID string, challenge, nonce *string) *stdhttptest.Server {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make(jwt.Keys)
+	keys.Register(jwt.RS256, "key-1", &privateKey.PublicKey, privateKey)
+
+	mux := http.NewServeMux()
+	srv := stdhttptest.NewServer(mux)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(auth.OIDCProviderMetadata{
+			Issuer:                srv.URL,
+			AuthorizationEndpoint: srv.URL + "/authorize",
+			TokenEndpoint:         srv.URL + "/token",
+			JWKSURI:               srv.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwtmiddleware.JWKS{Keys: []jwtmiddleware.JWK{{
+			Kty: "RSA",
+			Kid: "key-1",
+			Alg: "RS256",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+		}}})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("code") != "code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != *challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		idToken, err := keys.SignToken("key-1", iris.Map{
+			"iss":   srv.URL,
+			"aud":   []string{clientID},
+			"sub":   "42",
+			"email": "kataras@example.com",
+			"nonce": *nonce,
+		}, jwt.MaxAge(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		json.NewEncoder(w).Encode(auth.OIDCTokenResponse{
+			AccessToken: "provider-access-token",
+			TokenType:   "Bearer",
+			IDToken:     string(idToken),
+		})
+	})
+
+	return srv
+}
+
+func TestOIDC(t *testing.T) {
+	var challenge, nonce string
+	issuer := newTestIssuer(t, "client", &challenge, &nonce)
+	defer issuer.Close()
+
+	s := auth.Must(auth.New[oidcUser](auth.MustGenerateConfiguration()))
+	oidc, err := auth.NewOIDC(s, auth.OIDCConfiguration{
+		Issuer:      issuer.URL,
+		ClientID:    "client",
+		RedirectURL: "http://localhost/callback",
+		HTTPClient:  issuer.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	app.Get("/login", oidc.LoginHandler)
+	app.Get("/callback", oidc.CallbackHandler)
+	app.Get("/me", s.VerifyHandler(), func(ctx iris.Context) {
+		ctx.JSON(s.GetUser(ctx))
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://localhost"))
+
+	location := e.GET("/login").WithQuery("return_to", "/me").WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().Status(iris.StatusFound).Header("Location").Raw()
+	authorizeURL, err := url.Parse(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := authorizeURL.Query()
+	if expected, got := issuer.URL+"/authorize", authorizeURL.Scheme+"://"+authorizeURL.Host+authorizeURL.Path; expected != got {
+		t.Fatalf("expected authorization endpoint: %s but got: %s", expected, got)
+	}
+	if expected, got := "S256", query.Get("code_challenge_method"); expected != got {
+		t.Fatalf("expected code challenge method: %s but got: %s", expected, got)
+	}
+	challenge, nonce = query.Get("code_challenge"), query.Get("nonce")
+
+	// invalid state.
+	e.GET("/callback").WithQuery("state", "invalid").WithQuery("code", "code").
+		Expect().Status(iris.StatusBadRequest)
+
+	// the state cookie is removed on the first callback, start again.
+	location = e.GET("/login").WithQuery("return_to", "/me").WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().Status(iris.StatusFound).Header("Location").Raw()
+	authorizeURL, _ = url.Parse(location)
+	query = authorizeURL.Query()
+	challenge, nonce = query.Get("code_challenge"), query.Get("nonce")
+
+	e.GET("/callback").WithQuery("state", query.Get("state")).WithQuery("code", "code").
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().Status(iris.StatusFound).Header("Location").IsEqual("/me")
+
+	e.GET("/me").Expect().Status(iris.StatusOK).
+		JSON().IsEqual(oidcUser{Subject: "42", Email: "kataras@example.com"})
+}
+
+func TestOIDCMFA(t *testing.T) {
+	var challenge, nonce string
+	issuer := newTestIssuer(t, "client", &challenge, &nonce)
+	defer issuer.Close()
+
+	s := auth.Must(auth.New[oidcUser](auth.MustGenerateConfiguration())).
+		SetMFAStore(auth.NewMemoryMFAStore(), func(u oidcUser) string { return u.Subject })
+	oidc, err := auth.NewOIDC(s, auth.OIDCConfiguration{
+		Issuer:      issuer.URL,
+		ClientID:    "client",
+		RedirectURL: "http://localhost/callback",
+		HTTPClient:  issuer.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := oidcUser{Subject: "42", Email: "kataras@example.com"}
+	enrollment, err := s.EnrollMFA(context.Background(), user, user.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := auth.TOTP(enrollment.Secret, time.Now())
+	if err = s.ConfirmMFA(context.Background(), user, code); err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	app.Get("/login", oidc.LoginHandler)
+	app.Get("/callback", oidc.CallbackHandler)
+	app.Get("/me", s.VerifyHandler(), func(ctx iris.Context) {
+		ctx.JSON(s.GetUser(ctx))
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://localhost"))
+
+	location := e.GET("/login").WithQuery("return_to", "/me").WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().Status(iris.StatusFound).Header("Location").Raw()
+	authorizeURL, err := url.Parse(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := authorizeURL.Query()
+	challenge, nonce = query.Get("code_challenge"), query.Get("nonce")
+
+	// the second factor is required, the user is not signed in.
+	pending := e.GET("/callback").WithQuery("state", query.Get("state")).WithQuery("code", "code").
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().Status(iris.StatusOK).JSON().Object()
+	pending.Value("mfa_required").Boolean().IsTrue()
+	pending.Value("mfa_token").String().NotEmpty()
+	pending.NotContainsKey("access_token")
+
+	e.GET("/me").Expect().Status(iris.StatusUnauthorized)
+}
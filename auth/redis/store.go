@@ -0,0 +1,315 @@
+//go:build go1.18
+// +build go1.18
+
+// Package redis provides a redis-based RefreshTokenStore for the auth package,
+// so the refresh token families are shared between all the servers behind a load balancer.
+package redis
+
+import (
+	stdContext "context"
+	"time"
+
+	"github.com/kataras/iris/v12/auth"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultPrefix is the default prefix of the redis keys which hold the refresh token families.
+// Its hash tag keeps all the keys on the same slot of a redis cluster.
+const DefaultPrefix = "{iris.auth.refresh}."
+
+// issueScript stores the first refresh token of a new family.
+//
+// KEYS[1]: the token's key.
+// KEYS[2]: the family's key.
+// KEYS[3]: the subject's key.
+// ARGV[1]: the token id.
+// ARGV[2]: the family id.
+// ARGV[3]: the subject, may be empty.
+// ARGV[4]: the token's time to live in milliseconds, zero for no expiration.
+var issueScript = redis.NewScript(`
+local family = ARGV[2]
+local subject = ARGV[3]
+local ttl = tonumber(ARGV[4])
+
+redis.call("HSET", KEYS[1], "family", family, "subject", subject)
+redis.call("HSET", KEYS[2], "current", ARGV[1], "subject", subject)
+if ttl > 0 then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+	redis.call("PEXPIRE", KEYS[2], ttl)
+end
+
+if subject ~= "" then
+	redis.call("SADD", KEYS[3], family)
+	if ttl > 0 and redis.call("PTTL", KEYS[3]) < ttl then
+		redis.call("PEXPIRE", KEYS[3], ttl)
+	end
+end
+
+return 1
+`)
+
+// getScript reads the fields of a hash.
+//
+// KEYS[1]: the hash key.
+// ARGV: the fields.
+var getScript = redis.NewScript(`
+return redis.call("HMGET", KEYS[1], unpack(ARGV))
+`)
+
+// membersScript reads the members of a set.
+//
+// KEYS[1]: the set key.
+var membersScript = redis.NewScript(`
+return redis.call("SMEMBERS", KEYS[1])
+`)
+
+// rotateScript atomically replaces the current refresh token of a family,
+// a reuse of a previous token revokes the family.
+// The family and the subject of the used token are read before,
+// they never change for a token.
+//
+// KEYS[1]: the used token's key.
+// KEYS[2]: the family's key.
+// KEYS[3]: the next token's key.
+// KEYS[4]: the subject's key.
+// ARGV[1]: the used token id.
+// ARGV[2]: the next token id.
+// ARGV[3]: the family id.
+// ARGV[4]: the subject, may be empty.
+// ARGV[5]: the next token's time to live in milliseconds, zero for no expiration.
+//
+// Returns: status (0: rotated, 1: reused, 2: revoked).
+var rotateScript = redis.NewScript(`
+local used = ARGV[1]
+local family = ARGV[3]
+local subject = ARGV[4]
+local ttl = tonumber(ARGV[5])
+
+if redis.call("HGET", KEYS[1], "family") ~= family then
+	return 2
+end
+
+local current = redis.call("HGET", KEYS[2], "current")
+if not current then
+	return 2
+end
+
+if current ~= used then
+	redis.call("DEL", KEYS[2])
+	if subject ~= "" then
+		redis.call("SREM", KEYS[4], family)
+	end
+	return 1
+end
+
+redis.call("HSET", KEYS[3], "family", family, "subject", subject)
+redis.call("HSET", KEYS[2], "current", ARGV[2])
+if ttl > 0 then
+	redis.call("PEXPIRE", KEYS[3], ttl)
+	if redis.call("PTTL", KEYS[2]) < ttl then
+		redis.call("PEXPIRE", KEYS[2], ttl)
+	end
+end
+
+return 0
+`)
+
+// revokeSubjectScript revokes the given families of a subject.
+//
+// KEYS[1]: the subject's key.
+// KEYS[2...]: the families' keys.
+// ARGV: the family ids, in the same order as their keys.
+var revokeSubjectScript = redis.NewScript(`
+for i = 2, #KEYS do
+	redis.call("DEL", KEYS[i])
+	redis.call("SREM", KEYS[1], ARGV[i - 1])
+end
+
+if redis.call("SCARD", KEYS[1]) == 0 then
+	redis.call("DEL", KEYS[1])
+end
+
+return #KEYS - 1
+`)
+
+// revokeFamilyScript revokes a family.
+//
+// KEYS[1]: the family's key.
+// KEYS[2]: the subject's key.
+// ARGV[1]: the family id.
+// ARGV[2]: the subject, may be empty.
+var revokeFamilyScript = redis.NewScript(`
+if ARGV[2] ~= "" then
+	redis.call("SREM", KEYS[2], ARGV[1])
+end
+
+return redis.call("DEL", KEYS[1])
+`)
+
+// Store is the redis-based auth.RefreshTokenStore.
+// The tokens of a revoked family are kept until they expire,
+// so their reuse is still detected.
+//
+// All the keys start with the prefix, a redis.ClusterClient
+// requires a prefix with a hash tag, like the `DefaultPrefix`,
+// so the scripts can access them on the same slot.
+type Store struct {
+	client redis.Scripter
+	prefix string
+	// Timeout of each redis call when the request's context has no deadline,
+	// defaults to 3 seconds.
+	Timeout time.Duration
+}
+
+var _ auth.RefreshTokenStore = (*Store)(nil)
+
+// New returns a new redis RefreshTokenStore based on a go-redis client,
+// both redis.Client and redis.ClusterClient are accepted.
+// If "prefix" is empty then the `DefaultPrefix` is used instead.
+//
+// Usage:
+//
+//	import authredis "github.com/kataras/iris/v12/auth/redis"
+//	[...]
+//	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379"})
+//	s.SetRefreshTokenStore(authredis.New(client, ""), func(u User) string { return u.ID })
+func New(client redis.Scripter, prefix string) *Store {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	return &Store{
+		client:  client,
+		prefix:  prefix,
+		Timeout: 3 * time.Second,
+	}
+}
+
+func (s *Store) context(ctx stdContext.Context) (stdContext.Context, stdContext.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	return stdContext.WithTimeout(ctx, s.Timeout)
+}
+
+func ttl(expiresAt time.Time) int64 {
+	if expiresAt.IsZero() {
+		return 0
+	}
+
+	ms := time.Until(expiresAt).Milliseconds()
+	if ms <= 0 {
+		ms = 1
+	}
+
+	return ms
+}
+
+func (s *Store) tokenKey(id string) string {
+	return s.prefix + "token:" + id
+}
+
+func (s *Store) familyKey(family string) string {
+	return s.prefix + "family:" + family
+}
+
+func (s *Store) subjectKey(subject string) string {
+	return s.prefix + "subject:" + subject
+}
+
+// get returns the values of the "fields" of a hash, empty for the missing ones.
+func (s *Store) get(ctx stdContext.Context, key string, fields ...interface{}) ([]string, error) {
+	values, err := getScript.Run(ctx, s.client, []string{key}, fields...).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i], _ = v.(string)
+	}
+
+	return result, nil
+}
+
+// Issue implements the auth.RefreshTokenStore interface.
+func (s *Store) Issue(ctx stdContext.Context, token auth.RefreshToken) error {
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+
+	keys := []string{s.tokenKey(token.ID), s.familyKey(token.Family), s.subjectKey(token.Subject)}
+	return issueScript.Run(ctx, s.client, keys, token.ID, token.Family, token.Subject, ttl(token.ExpiresAt)).Err()
+}
+
+// Rotate implements the auth.RefreshTokenStore interface.
+// The family is updated atomically on the redis server.
+func (s *Store) Rotate(ctx stdContext.Context, usedID string, next auth.RefreshToken) (auth.RefreshToken, error) {
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+
+	used, err := s.get(ctx, s.tokenKey(usedID), "family", "subject")
+	if err != nil {
+		return next, err
+	}
+
+	family, subject := used[0], used[1]
+	if family == "" {
+		return next, auth.ErrRefreshTokenRevoked
+	}
+
+	keys := []string{s.tokenKey(usedID), s.familyKey(family), s.tokenKey(next.ID), s.subjectKey(subject)}
+	status, err := rotateScript.Run(ctx, s.client, keys, usedID, next.ID, family, subject, ttl(next.ExpiresAt)).Int64()
+	if err != nil {
+		return next, err
+	}
+
+	next.Family = family
+	switch status {
+	case 0:
+		next.Subject = subject
+		return next, nil
+	case 1:
+		next.Subject = subject
+		return next, auth.ErrRefreshTokenReused
+	default:
+		return next, auth.ErrRefreshTokenRevoked
+	}
+}
+
+// RevokeFamily implements the auth.RefreshTokenStore interface.
+func (s *Store) RevokeFamily(ctx stdContext.Context, family string) error {
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+
+	values, err := s.get(ctx, s.familyKey(family), "subject")
+	if err != nil {
+		return err
+	}
+
+	subject := values[0]
+	keys := []string{s.familyKey(family), s.subjectKey(subject)}
+	return revokeFamilyScript.Run(ctx, s.client, keys, family, subject).Err()
+}
+
+// RevokeSubject implements the auth.RefreshTokenStore interface.
+func (s *Store) RevokeSubject(ctx stdContext.Context, subject string) error {
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+
+	families, err := membersScript.Run(ctx, s.client, []string{s.subjectKey(subject)}).StringSlice()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(families)+1)
+	keys = append(keys, s.subjectKey(subject))
+	args := make([]interface{}, 0, len(families))
+	for _, family := range families {
+		keys = append(keys, s.familyKey(family))
+		args = append(args, family)
+	}
+
+	return revokeSubjectScript.Run(ctx, s.client, keys, args...).Err()
+}
@@ -0,0 +1,93 @@
+//go:build go1.18
+// +build go1.18
+
+package redis_test
+
+import (
+	stdContext "context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12/auth"
+	authredis "github.com/kataras/iris/v12/auth/redis"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func newClient(t *testing.T) *redis.Client {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(stdContext.Background()).Err(); err != nil {
+		client.Close()
+		t.Skipf("redis server is not available at %s: %v", addr, err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestStore(t *testing.T) {
+	client := newClient(t)
+	// a fresh state on each run.
+	prefix := "{iris.auth.refresh.test." + strconv.FormatInt(time.Now().UnixNano(), 10) + "}."
+
+	store := authredis.New(client, prefix)
+	ctx := stdContext.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	if err := store.Issue(ctx, auth.RefreshToken{ID: "1", Family: "f1", Subject: "kataras", ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := store.Rotate(ctx, "1", auth.RefreshToken{ID: "2", ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next.Family != "f1" || next.Subject != "kataras" {
+		t.Fatalf("expected the family and the subject of the used token but got: %#+v", next)
+	}
+
+	// reuse of the first refresh token revokes the family.
+	if _, err = store.Rotate(ctx, "1", auth.RefreshToken{ID: "3", ExpiresAt: expiresAt}); !errors.Is(err, auth.ErrRefreshTokenReused) {
+		t.Fatalf("expected error: %v but got: %v", auth.ErrRefreshTokenReused, err)
+	}
+
+	if _, err = store.Rotate(ctx, "2", auth.RefreshToken{ID: "4", ExpiresAt: expiresAt}); !errors.Is(err, auth.ErrRefreshTokenRevoked) {
+		t.Fatalf("expected error: %v but got: %v", auth.ErrRefreshTokenRevoked, err)
+	}
+
+	if _, err = store.Rotate(ctx, "unknown", auth.RefreshToken{ID: "5"}); !errors.Is(err, auth.ErrRefreshTokenRevoked) {
+		t.Fatalf("expected error: %v but got: %v", auth.ErrRefreshTokenRevoked, err)
+	}
+
+	// revoke all the families of a subject.
+	if err = store.Issue(ctx, auth.RefreshToken{ID: "6", Family: "f2", Subject: "kataras"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.RevokeSubject(ctx, "kataras"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = store.Rotate(ctx, "6", auth.RefreshToken{ID: "7"}); !errors.Is(err, auth.ErrRefreshTokenRevoked) {
+		t.Fatalf("expected error: %v but got: %v", auth.ErrRefreshTokenRevoked, err)
+	}
+
+	// the keys of the tokens expire.
+	ttl, err := client.PTTL(ctx, prefix+"token:2").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Fatalf("expected the token to expire in about an hour but got: %s", ttl)
+	}
+}
@@ -0,0 +1,295 @@
+//go:build go1.18
+// +build go1.18
+
+package auth
+
+import (
+	stdContext "context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRefreshTokenReused is returned on Auth.Refresh when a refresh token
+	// is used for a second time. It is a signal of a stolen token,
+	// the whole family of the refresh token is revoked.
+	ErrRefreshTokenReused = errors.New("auth: refresh token reused")
+	// ErrRefreshTokenRevoked is returned on Auth.Refresh when the family
+	// of the refresh token is revoked or unknown.
+	ErrRefreshTokenRevoked = errors.New("auth: refresh token revoked")
+)
+
+// RefreshToken holds the information about an issued refresh token
+// which a RefreshTokenStore keeps.
+type RefreshToken struct {
+	// ID is the unique "jti" claim of the refresh token.
+	ID string
+	// Family is the id of the chain of refresh tokens which derive from the same sign in.
+	Family string
+	// Subject is the user's identifier, see the Auth.SetRefreshTokenStore method.
+	// It may be empty.
+	Subject string
+	// ExpiresAt is the expiration time of the refresh token.
+	// A zero value means that the refresh token does not expire.
+	ExpiresAt time.Time
+}
+
+// RefreshTokenStore is the interface which keeps the refresh token families
+// to make each refresh token single-use.
+// Each sign in starts a new family, each refresh rotates the family to a new token.
+//
+// See the Auth.SetRefreshTokenStore method, the NewMemoryRefreshTokenStore
+// package-level function and the auth/redis sub-package.
+type RefreshTokenStore interface {
+	// Issue starts a new family with its first refresh token.
+	Issue(ctx stdContext.Context, token RefreshToken) error
+	// Rotate replaces the current refresh token "usedID" of its family with the "next" one,
+	// the next token's Family and Subject are set by the store.
+	// It should return ErrRefreshTokenReused, and revoke the family, if the "usedID"
+	// is a previous token of a family and ErrRefreshTokenRevoked
+	// if the family is revoked or the "usedID" is unknown.
+	// It must be atomic.
+	Rotate(ctx stdContext.Context, usedID string, next RefreshToken) (RefreshToken, error)
+	// RevokeFamily revokes all the refresh tokens of the family.
+	RevokeFamily(ctx stdContext.Context, family string) error
+	// RevokeSubject revokes all the refresh token families of the subject.
+	RevokeSubject(ctx stdContext.Context, subject string) error
+}
+
+// SetRefreshTokenStore enables the refresh token rotation and returns this Auth of T instance.
+// Each refresh token becomes single-use: Auth.Refresh rotates its family to the new refresh token
+// and a reuse of an old refresh token revokes the whole family and calls
+// the Provider.InvalidateTokens method of the user, as it is a signal of a stolen token.
+//
+// The optional "subject" function returns the identifier of a T user,
+// it is required to revoke all the refresh tokens of a user
+// on Auth.SignoutAllHandler, next to the Provider.InvalidateTokens call.
+//
+// Usage:
+//
+//	s.SetRefreshTokenStore(auth.NewMemoryRefreshTokenStore(), func(u User) string { return u.ID })
+func (s *Auth[T]) SetRefreshTokenStore(store RefreshTokenStore, subject func(t T) string) *Auth[T] {
+	s.refreshTokenStore = store
+	s.refreshTokenSubject = subject
+	return s
+}
+
+// trackRefreshToken starts a new refresh token family or, if "usedID" is not empty,
+// rotates the family of the "usedID" refresh token to the new one.
+func (s *Auth[T]) trackRefreshToken(ctx stdContext.Context, t T, usedID string, claims StandardClaims) error {
+	if s.refreshTokenStore == nil || !s.refreshEnabled {
+		return nil
+	}
+
+	token := RefreshToken{ID: claims.ID}
+	if maxAge := s.keys[KIDRefresh].MaxAge; maxAge > 0 {
+		token.ExpiresAt = time.Unix(claims.IssuedAt, 0).Add(maxAge)
+	}
+
+	if usedID == "" {
+		token.Family = claims.ID
+		if s.refreshTokenSubject != nil {
+			token.Subject = s.refreshTokenSubject(t)
+		}
+
+		return s.refreshTokenStore.Issue(ctx, token)
+	}
+
+	_, err := s.refreshTokenStore.Rotate(ctx, usedID, token)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		// stolen token signal: the family is revoked by the store,
+		// let the providers invalidate the user's access tokens too.
+		for _, p := range s.providers {
+			if invalidateErr := p.InvalidateTokens(ctx, t); invalidateErr == nil {
+				break
+			}
+		}
+	}
+
+	return err
+}
+
+// revokeRefreshTokens revokes all the refresh token families of the "t" user.
+func (s *Auth[T]) revokeRefreshTokens(ctx stdContext.Context, t T) error {
+	if s.refreshTokenStore == nil || s.refreshTokenSubject == nil {
+		return nil
+	}
+
+	subject := s.refreshTokenSubject(t)
+	if subject == "" {
+		return nil
+	}
+
+	return s.refreshTokenStore.RevokeSubject(ctx, subject)
+}
+
+type (
+	memoryRefreshTokenFamily struct {
+		current   string
+		subject   string
+		expiresAt time.Time
+	}
+
+	// MemoryRefreshTokenStore is an in-memory RefreshTokenStore.
+	// Initialize it through the NewMemoryRefreshTokenStore package-level function.
+	MemoryRefreshTokenStore struct {
+		mu        sync.Mutex
+		tokens    map[string]string // token id -> family.
+		expires   map[string]time.Time
+		families  map[string]*memoryRefreshTokenFamily
+		subjects  map[string]map[string]struct{}
+		lastSweep time.Time
+	}
+)
+
+var _ RefreshTokenStore = (*MemoryRefreshTokenStore)(nil)
+
+// NewMemoryRefreshTokenStore returns a new in-memory RefreshTokenStore.
+// The expired tokens are removed periodically, the tokens without an expiration
+// once their family is revoked. The previous tokens of a family which never expires
+// are kept while the family lives, so their reuse is detected.
+// The state is lost on restart and it's not shared between servers,
+// use the auth/redis sub-package for that.
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{
+		tokens:   make(map[string]string),
+		expires:  make(map[string]time.Time),
+		families: make(map[string]*memoryRefreshTokenFamily),
+		subjects: make(map[string]map[string]struct{}),
+	}
+}
+
+// Issue starts a new family with its first refresh token.
+func (m *MemoryRefreshTokenStore) Issue(ctx stdContext.Context, token RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep()
+
+	m.families[token.Family] = &memoryRefreshTokenFamily{
+		current:   token.ID,
+		subject:   token.Subject,
+		expiresAt: token.ExpiresAt,
+	}
+	m.addToken(token)
+
+	if token.Subject != "" {
+		families, ok := m.subjects[token.Subject]
+		if !ok {
+			families = make(map[string]struct{})
+			m.subjects[token.Subject] = families
+		}
+		families[token.Family] = struct{}{}
+	}
+
+	return nil
+}
+
+// Rotate replaces the current refresh token of a family with the "next" one.
+func (m *MemoryRefreshTokenStore) Rotate(ctx stdContext.Context, usedID string, next RefreshToken) (RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	familyID, ok := m.tokens[usedID]
+	if !ok {
+		return next, ErrRefreshTokenRevoked
+	}
+
+	family, ok := m.families[familyID]
+	if !ok {
+		return next, ErrRefreshTokenRevoked
+	}
+
+	if family.current != usedID {
+		m.revokeFamily(familyID)
+		return next, ErrRefreshTokenReused
+	}
+
+	next.Family = familyID
+	next.Subject = family.subject
+	family.current = next.ID
+	if next.ExpiresAt.After(family.expiresAt) {
+		family.expiresAt = next.ExpiresAt
+	}
+	m.addToken(next)
+
+	return next, nil
+}
+
+// RevokeFamily revokes all the refresh tokens of the family.
+func (m *MemoryRefreshTokenStore) RevokeFamily(ctx stdContext.Context, family string) error {
+	m.mu.Lock()
+	m.revokeFamily(family)
+	m.mu.Unlock()
+	return nil
+}
+
+// RevokeSubject revokes all the refresh token families of the subject.
+func (m *MemoryRefreshTokenStore) RevokeSubject(ctx stdContext.Context, subject string) error {
+	m.mu.Lock()
+	for family := range m.subjects[subject] {
+		m.revokeFamily(family)
+	}
+	delete(m.subjects, subject)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryRefreshTokenStore) addToken(token RefreshToken) {
+	m.tokens[token.ID] = token.Family
+	if !token.ExpiresAt.IsZero() {
+		m.expires[token.ID] = token.ExpiresAt
+	}
+}
+
+// revokeFamily removes the family, its tokens are kept until they expire
+// so their reuse is still detected as revoked.
+func (m *MemoryRefreshTokenStore) revokeFamily(familyID string) {
+	family, ok := m.families[familyID]
+	if !ok {
+		return
+	}
+
+	delete(m.families, familyID)
+	if families, ok := m.subjects[family.subject]; ok {
+		delete(families, familyID)
+		if len(families) == 0 {
+			delete(m.subjects, family.subject)
+		}
+	}
+}
+
+// sweep removes the expired tokens and families
+// and the tokens without an expiration of the revoked families, at most once per minute.
+func (m *MemoryRefreshTokenStore) sweep() {
+	now := time.Now()
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	m.lastSweep = now
+
+	for id, expiresAt := range m.expires {
+		if now.After(expiresAt) {
+			delete(m.expires, id)
+			delete(m.tokens, id)
+		}
+	}
+
+	for id, family := range m.families {
+		if !family.expiresAt.IsZero() && now.After(family.expiresAt) {
+			m.revokeFamily(id)
+		}
+	}
+
+	for id, family := range m.tokens {
+		if _, ok := m.expires[id]; ok {
+			continue
+		}
+
+		// a token of a revoked family is reported as revoked either way.
+		if _, ok := m.families[family]; !ok {
+			delete(m.tokens, id)
+		}
+	}
+}
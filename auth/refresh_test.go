@@ -0,0 +1,83 @@
+//go:build go1.18
+// +build go1.18
+
+package auth_test
+
+import (
+	stdContext "context"
+	"errors"
+	"testing"
+
+	"github.com/kataras/iris/v12/auth"
+)
+
+type refreshUser struct {
+	ID string `json:"id"`
+}
+
+type refreshProvider struct {
+	invalidated int
+}
+
+func (p *refreshProvider) Signin(ctx stdContext.Context, username, password string) (refreshUser, error) {
+	return refreshUser{ID: username}, nil
+}
+
+func (p *refreshProvider) ValidateToken(ctx stdContext.Context, standardClaims auth.StandardClaims, u refreshUser) error {
+	return nil
+}
+
+func (p *refreshProvider) InvalidateToken(ctx stdContext.Context, standardClaims auth.StandardClaims, u refreshUser) error {
+	return nil
+}
+
+func (p *refreshProvider) InvalidateTokens(ctx stdContext.Context, u refreshUser) error {
+	p.invalidated++
+	return nil
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	provider := new(refreshProvider)
+	s := auth.Must(auth.New[refreshUser](auth.MustGenerateConfiguration())).
+		AddProvider(provider).
+		SetRefreshTokenStore(auth.NewMemoryRefreshTokenStore(), func(u refreshUser) string { return u.ID })
+
+	ctx := stdContext.Background()
+
+	_, first, err := s.Signin(ctx, "kataras", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, second, err := s.Refresh(ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reuse of the first refresh token revokes the family.
+	if _, _, err = s.Refresh(ctx, first); !errors.Is(err, auth.ErrRefreshTokenReused) {
+		t.Fatalf("expected error: %v but got: %v", auth.ErrRefreshTokenReused, err)
+	}
+
+	if expected, got := 1, provider.invalidated; expected != got {
+		t.Fatalf("expected InvalidateTokens to be called: %d time(s) but got: %d", expected, got)
+	}
+
+	if _, _, err = s.Refresh(ctx, second); !errors.Is(err, auth.ErrRefreshTokenRevoked) {
+		t.Fatalf("expected error: %v but got: %v", auth.ErrRefreshTokenRevoked, err)
+	}
+
+	// a new sign in starts a new family, signout of all sessions revokes it.
+	access, third, err := s.Signin(ctx, "kataras", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Signout(ctx, access, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = s.Refresh(ctx, third); !errors.Is(err, auth.ErrRefreshTokenRevoked) {
+		t.Fatalf("expected error: %v but got: %v", auth.ErrRefreshTokenRevoked, err)
+	}
+}
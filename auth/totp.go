@@ -0,0 +1,156 @@
+//go:build go1.18
+// +build go1.18
+
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPPeriod is the time step of the TOTP codes, in seconds.
+	TOTPPeriod = 30
+	// TOTPDigits is the length of the TOTP codes.
+	TOTPDigits = 6
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a new random base32-encoded TOTP secret of 160 bits,
+// as it's recommended by RFC 4226 for HMAC-SHA1.
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI returns the "otpauth://" key URI of the secret, which authenticator apps
+// import through a QR code. The "issuer" is the name of the application
+// and the "account" is the user's name or email.
+func TOTPURI(issuer, account, secret string) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(TOTPPeriod))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TOTP returns the RFC 6238 time-based one-time password
+// of the base32-encoded "secret" at the time "t".
+func TOTP(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(key, totpStep(t)), nil
+}
+
+// ValidateTOTP reports whether the "code" is the time-based one-time password
+// of the base32-encoded "secret" at the time "t" or at one of the "skew" time steps
+// before and after it, to allow for clock drift. It returns the time step of the code,
+// which should not be accepted again to prevent replays.
+func ValidateTOTP(secret, code string, t time.Time, skew int) (int64, bool) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil || len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	step := totpStep(t)
+	for i := -int64(skew); i <= int64(skew); i++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step+i)), []byte(code)) == 1 {
+			return step + i, true
+		}
+	}
+
+	return 0, false
+}
+
+func totpStep(t time.Time) int64 {
+	return t.Unix() / TOTPPeriod
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return totpEncoding.DecodeString(strings.TrimRight(secret, "="))
+}
+
+// hotp returns the RFC 4226 HMAC-based one-time password of the "counter".
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", TOTPDigits, value%1000000)
+}
+
+// recoveryCodeAlphabet excludes similar looking characters.
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// GenerateRecoveryCodes returns "n" new random one-time recovery codes,
+// in the form of "xxxxx-xxxxx". They should be shown to the user once
+// and kept hashed, see the `HashRecoveryCode` package-level function.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	code := make([]byte, 10)
+	// the largest multiple of the alphabet's length, bigger bytes are rejected to avoid modulo bias.
+	max := byte(256 / len(recoveryCodeAlphabet) * len(recoveryCodeAlphabet))
+	b := make([]byte, 1)
+
+	for i := range codes {
+		for j := 0; j < len(code); {
+			if _, err := rand.Read(b); err != nil {
+				return nil, err
+			}
+
+			if b[0] >= max {
+				continue
+			}
+
+			code[j] = recoveryCodeAlphabet[int(b[0])%len(recoveryCodeAlphabet)]
+			j++
+		}
+
+		codes[i] = string(code[:5]) + "-" + string(code[5:])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode returns the SHA-256 hex-encoded hash of a recovery code,
+// dashes, spaces and letter case are ignored.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
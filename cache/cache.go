@@ -28,6 +28,7 @@ Example code:
 package cache
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/kataras/iris/v12/cache/client"
@@ -94,3 +95,70 @@ func Handler(expiration time.Duration) context.Handler {
 	h := Cache(maxAgeFunc).ServeHTTP
 	return h
 }
+
+// Tag attaches one or more tags, e.g. "user:42", "products",
+// to the cached entry of the current request.
+// Should be called by the route handler, after the cache middleware.
+//
+// Usage:
+//
+//	app.Get("/users/{id}", cache.Handler(time.Minute), func(ctx iris.Context) {
+//		cache.Tag(ctx, "users", "user:"+ctx.Params().Get("id"))
+//		[...]
+//	})
+//
+// See `PurgeTags` too.
+func Tag(ctx *context.Context, tags ...string) {
+	client.AddTags(ctx, tags...)
+}
+
+// PurgeTags removes all the cached entries tagged with at least one of the given "tags",
+// so write endpoints can invalidate the read endpoints they affect.
+// Returns the number of the removed entries.
+//
+// Usage:
+//
+//	app.Put("/users/{id}", func(ctx iris.Context) {
+//		[...update user]
+//		cache.PurgeTags("user:" + ctx.Params().Get("id"))
+//	})
+func PurgeTags(tags ...string) int {
+	return client.DefaultInvalidator.PurgeTags(tags...)
+}
+
+// PurgePath removes all the cached entries which their request path
+// matches the given "pattern", e.g. "/products/*".
+// The pattern syntax is the same as the standard `path.Match` one.
+// Returns the number of the removed entries.
+func PurgePath(pattern string) (int, error) {
+	return client.DefaultInvalidator.PurgePath(pattern)
+}
+
+// PurgeHandler is an optional administration handler which removes cached entries
+// by the "tag" and "path" URL query parameters (both can be repeated)
+// and responds with the number of the removed entries as JSON.
+// Protect it through an authentication middleware.
+//
+// Usage:
+// app.Post("/admin/cache/purge", basicauth.Default(users), cache.PurgeHandler)
+//
+// Request:
+// POST /admin/cache/purge?tag=products&path=/products/*
+//
+// Response:
+// {"purged": 3}
+func PurgeHandler(ctx *context.Context) {
+	purged := PurgeTags(ctx.URLParamSlice("tag")...)
+
+	for _, pattern := range ctx.URLParamSlice("path") {
+		n, err := PurgePath(pattern)
+		if err != nil {
+			ctx.StopWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		purged += n
+	}
+
+	ctx.JSON(context.Map{"purged": purged})
+}
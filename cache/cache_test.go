@@ -3,6 +3,7 @@ package cache_test
 import (
 	"fmt"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/kataras/iris/v12/cache"
 	"github.com/kataras/iris/v12/cache/client"
 	"github.com/kataras/iris/v12/cache/client/rule"
+	"github.com/kataras/iris/v12/cache/entry"
 
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/context"
@@ -213,3 +215,217 @@ func TestCacheValidator(t *testing.T) {
 		t.Fatalf("%s: %v", t.Name(), &testError{3, counter})
 	}
 }
+
+func TestCacheCoalesce(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	app.Get("/", cache.Cache(cache.MaxAge(cacheDuration)).Coalesce(true).ServeHTTP, func(ctx *context.Context) {
+		atomic.AddUint32(&n, 1)
+		time.Sleep(cacheDuration / 4) // simulate a slow handler, e.g. a database query.
+		ctx.Write([]byte(expectedBodyStr))
+	})
+
+	e := httptest.New(t, app)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual(expectedBodyStr)
+		}()
+	}
+	wg.Wait()
+
+	if counter := atomic.LoadUint32(&n); counter != 1 {
+		t.Fatalf("%s: %v", t.Name(), &testError{1, counter})
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	app := iris.New()
+	var n, middlewareCounter uint32
+
+	middleware := func(ctx *context.Context) {
+		atomic.AddUint32(&middlewareCounter, 1)
+		ctx.Next()
+	}
+
+	app.Get("/", middleware, cache.Cache(cache.MaxAge(cacheDuration)).StaleWhileRevalidate(cacheDuration).ServeHTTP, func(ctx *context.Context) {
+		counter := atomic.AddUint32(&n, 1)
+		ctx.Writef("%d", counter)
+	})
+
+	app.Get("/directive", cache.Handler(cacheDuration), func(ctx *context.Context) {
+		counter := atomic.AddUint32(&n, 1)
+		ctx.Header("Cache-Control", "max-age=2, stale-while-revalidate=2")
+		ctx.Writef("%d", counter)
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual("1")
+	time.Sleep(cacheDuration + cacheDuration/5) // expired but still in the stale window.
+	e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual("1")
+	time.Sleep(cacheDuration / 5) // wait for the background revalidation.
+	e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual("2")
+	// the expiration of the replaced entry does not remove the revalidated one.
+	time.Sleep(cacheDuration * 7 / 10)
+	e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual("2")
+	// the middlewares before the cache handler are not executed by the revalidation.
+	if counter := atomic.LoadUint32(&middlewareCounter); counter != 4 {
+		t.Fatalf("expected the middleware to be executed 4 times instead of %d", counter)
+	}
+
+	e.GET("/directive").Expect().Status(http.StatusOK).Body().IsEqual("3")
+	time.Sleep(cacheDuration + cacheDuration/5)
+	e.GET("/directive").Expect().Status(http.StatusOK).Body().IsEqual("3")
+	time.Sleep(cacheDuration / 5)
+	e.GET("/directive").Expect().Status(http.StatusOK).Body().IsEqual("4")
+}
+
+func TestCacheStaleIfError(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	app.Get("/", cache.Cache(cache.MaxAge(cacheDuration)).StaleIfError(cacheDuration).ServeHTTP, func(ctx *context.Context) {
+		if counter := atomic.AddUint32(&n, 1); counter > 1 {
+			ctx.StopWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		ctx.Write([]byte(expectedBodyStr))
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual(expectedBodyStr)
+	time.Sleep(cacheDuration + cacheDuration/5)
+	e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual(expectedBodyStr)
+	if counter := atomic.LoadUint32(&n); counter != 2 {
+		t.Fatalf("%s: %v", t.Name(), &testError{2, counter})
+	}
+}
+
+func TestCacheVary(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	app.Get("/", cache.Handler(cacheDuration), func(ctx *context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.Header("Vary", "Accept-Language")
+		ctx.WriteString(ctx.GetHeader("Accept-Language"))
+	})
+
+	app.Get("/cookie", cache.Cache(cache.MaxAge(cacheDuration)).VaryByCookie("theme").ServeHTTP, func(ctx *context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.WriteString(ctx.GetCookie("theme"))
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/").WithHeader("Accept-Language", "en").Expect().Status(http.StatusOK).Body().IsEqual("en")
+	e.GET("/").WithHeader("Accept-Language", "el").Expect().Status(http.StatusOK).Body().IsEqual("el")
+	e.GET("/").WithHeader("Accept-Language", "en").Expect().Status(http.StatusOK).Body().IsEqual("en")
+	e.GET("/").WithHeader("Accept-Language", "el").Expect().Status(http.StatusOK).Body().IsEqual("el")
+	if counter := atomic.LoadUint32(&n); counter != 2 {
+		t.Fatalf("%s: %v", t.Name(), &testError{2, counter})
+	}
+
+	e.GET("/cookie").WithCookie("theme", "dark").Expect().Status(http.StatusOK).Body().IsEqual("dark")
+	e.GET("/cookie").WithCookie("theme", "light").Expect().Status(http.StatusOK).Body().IsEqual("light")
+	e.GET("/cookie").WithCookie("theme", "dark").Expect().Status(http.StatusOK).Body().IsEqual("dark")
+	if counter := atomic.LoadUint32(&n); counter != 4 {
+		t.Fatalf("%s: %v", t.Name(), &testError{4, counter})
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	app.Get("/products/{id}", cache.Handler(cacheDuration), func(ctx *context.Context) {
+		atomic.AddUint32(&n, 1)
+		cache.Tag(ctx, "products", "product:"+ctx.Params().Get("id"))
+		ctx.WriteString(ctx.Params().Get("id"))
+	})
+	app.Post("/purge", cache.PurgeHandler)
+	app.Get("/custom", cache.Cache(cache.MaxAge(cacheDuration)).Store(mapStore{entries: make(map[string]*entry.Entry), mu: new(sync.RWMutex)}).ServeHTTP, func(ctx *context.Context) {
+		atomic.AddUint32(&n, 1)
+		cache.Tag(ctx, "custom")
+		ctx.WriteString("custom")
+	})
+
+	e := httptest.New(t, app)
+
+	expectCounter := func(expected uint32) {
+		t.Helper()
+		if counter := atomic.LoadUint32(&n); counter != expected {
+			t.Fatalf("%s: %v", t.Name(), &testError{int(expected), counter})
+		}
+	}
+
+	e.GET("/products/1").Expect().Status(http.StatusOK).Body().IsEqual("1")
+	e.GET("/products/2").Expect().Status(http.StatusOK).Body().IsEqual("2")
+	e.GET("/products/1").Expect().Status(http.StatusOK).Body().IsEqual("1")
+	expectCounter(2)
+
+	if purged := cache.PurgeTags("product:1"); purged != 1 {
+		t.Fatalf("expected 1 purged entry but got %d", purged)
+	}
+	e.GET("/products/1").Expect().Status(http.StatusOK).Body().IsEqual("1")
+	e.GET("/products/2").Expect().Status(http.StatusOK).Body().IsEqual("2")
+	expectCounter(3)
+
+	e.POST("/purge").WithQuery("path", "/products/*").Expect().Status(http.StatusOK).JSON().IsEqual(map[string]any{"purged": 2})
+	e.GET("/products/1").Expect().Status(http.StatusOK).Body().IsEqual("1")
+	e.GET("/products/2").Expect().Status(http.StatusOK).Body().IsEqual("2")
+	expectCounter(5)
+
+	e.POST("/purge").WithQuery("tag", "products").Expect().Status(http.StatusOK).JSON().IsEqual(map[string]any{"purged": 2})
+	e.POST("/purge").WithQuery("path", "[").Expect().Status(http.StatusBadRequest)
+
+	// the expiration of a purged entry does not remove the entry which replaced it.
+	e.GET("/products/1").Expect().Status(http.StatusOK).Body().IsEqual("1")
+	time.Sleep(cacheDuration / 2)
+	cache.PurgeTags("product:1")
+	e.GET("/products/1").Expect().Status(http.StatusOK).Body().IsEqual("1")
+	time.Sleep(cacheDuration * 3 / 4)
+	e.GET("/products/1").Expect().Status(http.StatusOK).Body().IsEqual("1")
+	expectCounter(7)
+
+	// stores which are not comparable.
+	e.GET("/custom").Expect().Status(http.StatusOK).Body().IsEqual("custom")
+	e.GET("/custom").Expect().Status(http.StatusOK).Body().IsEqual("custom")
+	expectCounter(8)
+	if purged := cache.PurgeTags("custom"); purged != 1 {
+		t.Fatalf("expected 1 purged entry but got %d", purged)
+	}
+	e.GET("/custom").Expect().Status(http.StatusOK).Body().IsEqual("custom")
+	expectCounter(9)
+}
+
+// mapStore is an entry.Store which is not comparable.
+type mapStore struct {
+	entries map[string]*entry.Entry
+	mu      *sync.RWMutex
+}
+
+func (s mapStore) Get(key string) *entry.Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.entries[key]
+}
+
+func (s mapStore) Set(key string, e *entry.Entry) {
+	s.mu.Lock()
+	s.entries[key] = e
+	s.mu.Unlock()
+}
+
+func (s mapStore) Delete(key string) {
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+}
@@ -0,0 +1,132 @@
+// Package badger provides a badger-based, persistent, store for the server-side cache entries.
+package badger
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/kataras/iris/v12/cache/entry"
+	"github.com/kataras/iris/v12/context"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/kataras/golog"
+)
+
+// DefaultFileMode used as the default store's "fileMode"
+// for creating the cache directory path.
+var DefaultFileMode = 0755
+
+// Store is the badger(key-value file-based) store for the cache entries.
+// Entries survive application restarts.
+type Store struct {
+	// Service is the underline badger database connection,
+	// it's initialized at `New` or `NewFromDB`.
+	// Can be used to get stats.
+	Service *badger.DB
+	logger  *golog.Logger
+}
+
+var _ entry.Store = (*Store)(nil)
+
+// New creates and returns a new badger(key-value file-based) cache store
+// instance based on the "directoryPath".
+func New(directoryPath string) (*Store, error) {
+	if directoryPath == "" {
+		return nil, errors.New("directoryPath is empty")
+	}
+
+	lindex := directoryPath[len(directoryPath)-1]
+	if lindex != os.PathSeparator && lindex != '/' {
+		directoryPath += string(os.PathSeparator)
+	}
+	// create directories if necessary
+	if err := os.MkdirAll(directoryPath, os.FileMode(DefaultFileMode)); err != nil {
+		return nil, err
+	}
+
+	opts := badger.DefaultOptions(directoryPath)
+	badgerLogger := context.DefaultLogger("cachedb.badger").DisableNewLine()
+	opts.Logger = badgerLogger
+
+	service, err := badger.Open(opts)
+	if err != nil {
+		badgerLogger.Errorf("unable to initialize the badger-based cache store: %v\n", err)
+		return nil, err
+	}
+
+	return NewFromDB(service), nil
+}
+
+// NewFromDB same as `New` but accepts an already-created custom badger connection instead.
+func NewFromDB(service *badger.DB) *Store {
+	return &Store{Service: service, logger: context.DefaultLogger("cachedb.badger")}
+}
+
+// SetLogger sets the logger which store errors are reported to.
+func (s *Store) SetLogger(logger *golog.Logger) {
+	s.logger = logger
+}
+
+// Get returns an entry based on its key.
+func (s *Store) Get(key string) *entry.Entry {
+	var e *entry.Entry
+
+	err := s.Service.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(valueBytes []byte) error {
+			e, err = entry.Decode(valueBytes)
+			return err
+		})
+	})
+
+	if err != nil {
+		if err != badger.ErrKeyNotFound {
+			s.logger.Error(err)
+		}
+		return nil
+	}
+
+	return e
+}
+
+// Set sets an entry based on its key.
+// The badger item expires when the entry expires.
+func (s *Store) Set(key string, e *entry.Entry) {
+	data, err := entry.Encode(e)
+	if err != nil {
+		s.logger.Error(err)
+		return
+	}
+
+	badgerEntry := badger.NewEntry([]byte(key), data)
+	if expiresAt := e.ExpiresAt(); !expiresAt.IsZero() {
+		badgerEntry = badgerEntry.WithTTL(time.Until(expiresAt))
+	}
+
+	err = s.Service.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(badgerEntry)
+	})
+	if err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// Delete deletes an entry based on its key.
+func (s *Store) Delete(key string) {
+	err := s.Service.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(key))
+	})
+	if err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// Close shutdowns the badger connection.
+func (s *Store) Close() error {
+	return s.Service.Close()
+}
@@ -0,0 +1,57 @@
+package badger_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12/cache/cachedb/badger"
+	"github.com/kataras/iris/v12/cache/cfg"
+	"github.com/kataras/iris/v12/cache/entry"
+
+	badgerdb "github.com/dgraph-io/badger/v4"
+)
+
+func TestStore(t *testing.T) {
+	service, err := badgerdb.Open(badgerdb.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := badger.NewFromDB(service)
+	defer store.Close()
+
+	pool := entry.NewPool()
+	r := entry.NewResponse(http.StatusOK, http.Header{"Content-Type": {"text/plain"}}, []byte("body"))
+	store.Set("persistent", pool.AcquireStale(-1, 0, 0, r, func(*entry.Entry) {}))
+	store.Set("short", pool.AcquireStale(cfg.MinimumCacheDuration, 0, 0, r, func(*entry.Entry) {}))
+
+	for _, key := range []string{"persistent", "short"} {
+		e := store.Get(key)
+		if e == nil {
+			t.Fatalf("%s: expected the stored entry", key)
+		}
+
+		if expected, got := "body", string(e.Response().Body()); expected != got {
+			t.Fatalf("%s: expected body: %q but got: %q", key, expected, got)
+		}
+	}
+
+	if e := store.Get("unknown"); e != nil {
+		t.Fatalf("expected no entry but got one")
+	}
+
+	// the badger item expires with the entry.
+	time.Sleep(cfg.MinimumCacheDuration + time.Second)
+	if e := store.Get("short"); e != nil {
+		t.Fatalf("expected the entry to be expired")
+	}
+	if e := store.Get("persistent"); e == nil {
+		t.Fatalf("expected the entry without a max age to be kept")
+	}
+
+	store.Delete("persistent")
+	if e := store.Get("persistent"); e != nil {
+		t.Fatalf("expected the deleted entry to be removed")
+	}
+}
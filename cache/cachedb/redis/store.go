@@ -0,0 +1,173 @@
+// Package redis provides a redis-based, distributed, store for the server-side cache entries.
+// It uses the same configuration and drivers as the redis sessions database.
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kataras/iris/v12/cache/entry"
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/sessions/sessiondb/redis"
+
+	"github.com/kataras/golog"
+)
+
+type (
+	// Config is the redis configuration, see the sessions/sessiondb/redis.Config for details.
+	Config = redis.Config
+	// Driver is the redis client driver, see the sessions/sessiondb/redis.Driver for details.
+	Driver = redis.Driver
+)
+
+// ttlSetter is implemented by the drivers which can store a value
+// and set the expiration of its key atomically, e.g. the GoRedisDriver.
+type ttlSetter interface {
+	SetWithTTL(sid, key string, value interface{}, lifetime time.Duration) error
+}
+
+// DefaultConfig returns the default configuration for the redis cache store.
+var DefaultConfig = redis.DefaultConfig
+
+// entryField is the hash field which the encoded entry is stored at.
+const entryField = "entry"
+
+// Store is the redis-based store for the cache entries.
+// Entries are shared between all the servers connected to the same redis
+// and they survive application restarts.
+type Store struct {
+	c      Config
+	logger *golog.Logger
+}
+
+var _ entry.Store = (*Store)(nil)
+
+// New returns a new redis cache store.
+// It panics if the connection to the redis server failed.
+func New(cfg ...Config) *Store {
+	c := DefaultConfig()
+	if len(cfg) > 0 {
+		c = cfg[0]
+
+		if c.Timeout < 0 {
+			c.Timeout = redis.DefaultRedisTimeout
+		}
+
+		if c.Network == "" {
+			c.Network = redis.DefaultRedisNetwork
+		}
+
+		if c.Addr == "" {
+			c.Addr = redis.DefaultRedisAddr
+		}
+
+		if c.Driver == nil {
+			c.Driver = redis.GoRedis()
+		}
+	}
+
+	if err := c.Driver.Connect(c); err != nil {
+		panic(err)
+	}
+
+	if _, err := c.Driver.PingPong(); err != nil {
+		panic(err)
+	}
+
+	return &Store{c: c, logger: context.DefaultLogger("cachedb.redis")}
+}
+
+// SetLogger sets the logger which store errors are reported to.
+func (s *Store) SetLogger(logger *golog.Logger) {
+	s.logger = logger
+}
+
+func (s *Store) makeKey(key string) string {
+	return s.c.Prefix + key
+}
+
+// Get returns an entry based on its key.
+func (s *Store) Get(key string) *entry.Entry {
+	data, err := s.c.Driver.Get(s.makeKey(key), entryField)
+	if err != nil {
+		// not found.
+		return nil
+	}
+
+	var b []byte
+	switch v := data.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		s.logger.Debugf("unknown value type of %T for key: '%s'", data, key)
+		return nil
+	}
+
+	e, err := entry.Decode(b)
+	if err != nil {
+		s.logger.Debugf("unable to decode entry of key: '%s': %v", key, err)
+		return nil
+	}
+
+	return e
+}
+
+// Set sets an entry based on its key.
+// The redis key expires when the entry expires.
+// The entry and its expiration are stored atomically if the driver
+// supports it, like the default one does, otherwise the entry is removed
+// when its expiration could not be set.
+func (s *Store) Set(key string, e *entry.Entry) {
+	data, err := entry.Encode(e)
+	if err != nil {
+		s.logger.Error(err)
+		return
+	}
+
+	redisKey := s.makeKey(key)
+	expiresAt := e.ExpiresAt()
+	if expiresAt.IsZero() {
+		if err = s.c.Driver.Set(redisKey, entryField, data); err != nil {
+			s.logger.Debug(err)
+		}
+		return
+	}
+
+	if setter, ok := s.c.Driver.(ttlSetter); ok {
+		if err = setter.SetWithTTL(redisKey, entryField, data, time.Until(expiresAt)); err != nil {
+			s.logger.Debug(err)
+		}
+		return
+	}
+
+	if err = s.c.Driver.Set(redisKey, entryField, data); err != nil {
+		s.logger.Debug(err)
+		return
+	}
+
+	if err = s.c.Driver.UpdateTTL(redisKey, time.Until(expiresAt)); err != nil {
+		s.logger.Debug(err)
+		// do not keep an entry which never expires.
+		if err = s.c.Driver.Delete(redisKey, ""); err != nil {
+			s.logger.Debug(err)
+		}
+	}
+}
+
+// Delete deletes an entry based on its key.
+func (s *Store) Delete(key string) {
+	if err := s.c.Driver.Delete(s.makeKey(key), ""); err != nil {
+		s.logger.Debug(err)
+	}
+}
+
+// Close terminates the redis connection.
+func (s *Store) Close() error {
+	if err := s.c.Driver.CloseConnection(); err != nil {
+		return fmt.Errorf("cachedb.redis: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/kataras/iris/v12/cache/entry"
+)
+
+// flightCall is an in-flight or completed execution of a route handler
+// for a specific cache key.
+type flightCall struct {
+	wg sync.WaitGroup
+	e  *entry.Entry
+}
+
+// flightGroup makes sure that only one execution of a route handler
+// is in-flight for a given cache key at a time, any other callers
+// for the same key wait for that execution and share its result.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do executes "fn" for the given "key" and returns its result.
+// If an execution for the same key is already in-flight
+// it waits for it and returns the same result instead.
+// The second return value reports whether the caller executed "fn" itself.
+func (g *flightGroup) do(key string, fn func() *entry.Entry) (*entry.Entry, bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.e, false
+	}
+
+	c := new(flightCall)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	// release the waiters even if "fn" panics,
+	// they will execute their own handler instead.
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+
+	c.e = fn()
+	return c.e, true
+}
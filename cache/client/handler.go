@@ -2,7 +2,9 @@ package client
 
 import (
 	"net/http"
+	"net/textproto"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kataras/iris/v12/cache/client/rule"
@@ -27,6 +29,29 @@ type Handler struct {
 	// entries the memory cache stored responses.
 	entryPool  *entry.Pool
 	entryStore entry.Store
+	// storeID identifies the entryStore on the invalidator.
+	storeID uint64
+	// coalesce reports whether concurrent misses on the same key
+	// should wait for a single execution of the route handler.
+	coalesce bool
+	flight   flightGroup
+	// staleWhileRevalidate and staleIfError are the default durations
+	// that a stale response can be served, see the `StaleWhileRevalidate`
+	// and `StaleIfError` methods.
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+	// revalidating holds the keys which are currently revalidated on the background.
+	revalidating sync.Map
+	// varyHeaders and varyCookies are the request header and cookie names
+	// that are always part of the entry key, see `VaryByHeader` and `VaryByCookie`.
+	varyHeaders []string
+	varyCookies []string
+	// varies holds the request header names, per entry key,
+	// collected from the "Vary" header of the recorded responses.
+	variesMu sync.RWMutex
+	varies   map[string]*varyEntry
+	// invalidator tracks the stored entries by their tags and paths.
+	invalidator *Invalidator
 }
 
 type MaxAgeFunc func(*context.Context) time.Duration
@@ -38,8 +63,10 @@ func NewHandler(maxAgeFunc MaxAgeFunc) *Handler {
 		rule:       DefaultRuleSet,
 		maxAgeFunc: maxAgeFunc,
 
-		entryPool:  entry.NewPool(),
-		entryStore: entry.NewMemStore(),
+		entryPool:   entry.NewPool(),
+		entryStore:  entry.NewMemStore(),
+		storeID:     nextStoreID(),
+		invalidator: DefaultInvalidator,
 	}
 }
 
@@ -71,6 +98,15 @@ func (h *Handler) AddRule(r rule.Rule) *Handler {
 // Store sets a custom store for this handler.
 func (h *Handler) Store(store entry.Store) *Handler {
 	h.entryStore = store
+	h.storeID = nextStoreID()
+	return h
+}
+
+// Invalidator sets a custom Invalidator which tracks
+// the entries of this handler by their tags and paths.
+// Defaults to the `DefaultInvalidator`.
+func (h *Handler) Invalidator(inv *Invalidator) *Handler {
+	h.invalidator = inv
 	return h
 }
 
@@ -80,6 +116,68 @@ func (h *Handler) MaxAge(fn MaxAgeFunc) *Handler {
 	return h
 }
 
+// Coalesce enables or disables request coalescing for this handler.
+// When enabled, concurrent requests that miss the cache for the same key
+// wait for one in-flight execution of the route handler
+// and all of them are served with its recorded response,
+// instead of executing the route handler for each one of them.
+// If the recorded response is not valid to be cached
+// then the waiting requests execute the route handler by themselves.
+//
+// Defaults to false.
+func (h *Handler) Coalesce(enable bool) *Handler {
+	h.coalesce = enable
+	return h
+}
+
+// StaleWhileRevalidate sets the duration, after the entry's expiration,
+// that a stale response can be served immediately
+// while a fresh one is fetched on the background.
+// The background fetch executes the route handlers which are registered
+// after the cache handler, the middlewares before it are not executed again.
+// A "stale-while-revalidate=<seconds>" Cache-Control directive
+// emitted by the route handler overrides this value.
+//
+// Defaults to zero, a stale response is never served.
+func (h *Handler) StaleWhileRevalidate(d time.Duration) *Handler {
+	h.staleWhileRevalidate = d
+	return h
+}
+
+// StaleIfError sets the duration, after the entry's expiration,
+// that a stale response can be served
+// when the route handler responds with a server (5xx) error.
+// A "stale-if-error=<seconds>" Cache-Control directive
+// emitted by the route handler overrides this value.
+//
+// Defaults to zero, a stale response is never served.
+func (h *Handler) StaleIfError(d time.Duration) *Handler {
+	h.staleIfError = d
+	return h
+}
+
+// VaryByHeader adds the values of the given request headers to the entry key,
+// so a separate response is cached for each combination of their values.
+// Note that the request headers listed on the response's "Vary" header
+// are respected automatically.
+func (h *Handler) VaryByHeader(headerNames ...string) *Handler {
+	for _, name := range headerNames {
+		h.varyHeaders = appendVaryName(h.varyHeaders, textproto.CanonicalMIMEHeaderKey(name))
+	}
+
+	return h
+}
+
+// VaryByCookie adds the values of the given request cookies to the entry key,
+// so a separate response is cached for each combination of their values.
+func (h *Handler) VaryByCookie(cookieNames ...string) *Handler {
+	for _, name := range cookieNames {
+		h.varyCookies = appendVaryName(h.varyCookies, name)
+	}
+
+	return h
+}
+
 var emptyHandler = func(ctx *context.Context) {
 	ctx.StopWithText(500, "cache: empty body handler")
 }
@@ -144,61 +242,160 @@ func (h *Handler) ServeHTTP(ctx *context.Context) {
 	}
 
 	key := getOrSetKey(ctx) // unique per subdomains and paths with different url query.
+	key = h.variantKey(ctx, key)
 
 	e := h.entryStore.Get(key)
 	if e == nil {
-		// if it's expired, then execute the original handler
-		// with our custom response recorder response writer
-		// because the net/http doesn't give us
-		// a builtin way to get the status code & body
-		recorder := ctx.Recorder()
-		bodyHandler(ctx)
+		h.serveMiss(ctx, bodyHandler, key)
+		return
+	}
+
+	if e.IsStale() {
+		switch {
+		case e.StaleWhileRevalidate():
+			writeEntry(ctx, e)
+			h.revalidate(ctx, key)
+		case e.StaleIfError():
+			h.serveAndStore(ctx, bodyHandler, e)
+		default:
+			h.serveMiss(ctx, bodyHandler, key)
+		}
+
+		return
+	}
 
-		// now that we have recordered the response,
-		// we are ready to check if that specific response is valid to be stored.
+	// if it's valid then just write the cached results
+	writeEntry(ctx, e)
+}
 
-		// check if it's a valid response, if it's not then just return.
-		if !h.rule.Valid(ctx) {
+// serveMiss executes the route handler, or waits for an in-flight
+// execution when coalescing is enabled, on a cache miss.
+func (h *Handler) serveMiss(ctx *context.Context, bodyHandler context.Handler, key string) {
+	if !h.coalesce {
+		h.serveAndStore(ctx, bodyHandler, nil)
+		return
+	}
+
+	e, executed := h.flight.do(key, func() *entry.Entry {
+		return h.serveAndStore(ctx, bodyHandler, nil)
+	})
+	if executed {
+		return
+	}
+
+	if e == nil {
+		// the shared response was not valid to be cached,
+		// execute the route handler for this request too.
+		h.serveAndStore(ctx, bodyHandler, nil)
+		return
+	}
+
+	if variantKey := h.variantKey(ctx, GetKey(ctx)); variantKey != key {
+		// the shared response varies on request headers
+		// which were not known before its execution.
+		if e = h.entryStore.Get(variantKey); e == nil {
+			h.serveAndStore(ctx, bodyHandler, nil)
 			return
 		}
+	}
 
-		// no need to copy the body, its already done inside
-		body := recorder.Body()
-		if len(body) == 0 {
-			// if no body then just exit.
-			return
+	writeEntry(ctx, e)
+}
+
+// serveAndStore executes the route handler and stores its
+// recorded response to the entry store if it's valid to be cached.
+// The entry key is resolved after the route handler's execution
+// so the response's "Vary" header is respected.
+// If the route handler responds with a server error and a "stale" entry
+// is given then the stale response is written instead.
+// Returns the stored entry or nil.
+func (h *Handler) serveAndStore(ctx *context.Context, bodyHandler context.Handler, stale *entry.Entry) *entry.Entry {
+	// if it's expired, then execute the original handler
+	// with our custom response recorder response writer
+	// because the net/http doesn't give us
+	// a builtin way to get the status code & body
+	recorder := ctx.Recorder()
+	bodyHandler(ctx)
+
+	if stale != nil && recorder.StatusCode() >= http.StatusInternalServerError {
+		// keep serving the stale response.
+		recorder.Reset()
+		writeEntry(ctx, stale)
+		return stale
+	}
+
+	// now that we have recordered the response,
+	// we are ready to check if that specific response is valid to be stored.
+
+	// check if it's a valid response, if it's not then just return.
+	if !h.rule.Valid(ctx) {
+		return nil
+	}
+
+	// no need to copy the body, its already done inside
+	body := recorder.Body()
+	if len(body) == 0 {
+		// if no body then just exit.
+		return nil
+	}
+
+	varyHeaders, ok := parseVary(recorder.Header())
+	if !ok {
+		// "Vary: *", the response can't be reused.
+		return nil
+	}
+
+	baseKey := GetKey(ctx)
+	h.learnVary(baseKey, varyHeaders)
+	key := h.variantKey(ctx, baseKey)
+
+	staleWhileRevalidate, staleIfError := h.staleWhileRevalidate, h.staleIfError
+	if cacheControl := recorder.Header().Get(cacheControlHeaderKey); cacheControl != "" {
+		if d, ok := parseCacheControlDuration(cacheControl, "stale-while-revalidate"); ok {
+			staleWhileRevalidate = d
 		}
+		if d, ok := parseCacheControlDuration(cacheControl, "stale-if-error"); ok {
+			staleIfError = d
+		}
+	}
 
-		// fmt.Printf("reset cache entry\n")
-		// fmt.Printf("key: %s\n", key)
-		// fmt.Printf("content type: %s\n", recorder.Header().Get(cfg.ContentTypeHeader))
-		// fmt.Printf("body len: %d\n", len(body))
+	r := entry.NewResponse(recorder.StatusCode(), recorder.Header(), body)
+	store, storeID, invalidator := h.entryStore, h.storeID, h.invalidator
+	e := h.entryPool.AcquireStale(h.maxAgeFunc(ctx), staleWhileRevalidate, staleIfError, r, func(expired *entry.Entry) {
+		if current := store.Get(key); current != nil && !current.Same(expired) {
+			// it was replaced, e.g. by a revalidation, before its expiration.
+			return
+		}
 
-		r := entry.NewResponse(recorder.StatusCode(), recorder.Header(), body)
-		e = h.entryPool.Acquire(h.maxAgeFunc(ctx), r, func() {
-			h.entryStore.Delete(key)
+		store.Delete(key)
+		h.removeVariant(baseKey, key)
+		if invalidator != nil {
+			invalidator.remove(storeID, key)
+		}
+	})
+
+	store.Set(key, e)
+	h.addVariant(baseKey, key)
+	if invalidator != nil {
+		invalidator.add(storeID, key, invalidatorItem{
+			store:    store,
+			entry:    e,
+			path:     ctx.Path(),
+			tags:     GetTags(ctx),
+			onRemove: func() { h.removeVariant(baseKey, key) },
 		})
-
-		h.entryStore.Set(key, e)
-		return
 	}
+	return e
+}
 
-	// if it's valid then just write the cached results
+// writeEntry writes the cached response of "e" to the client.
+func writeEntry(ctx *context.Context, e *entry.Entry) {
 	r := e.Response()
-	// if !ok {
-	// 	// it shouldn't be happen because if it's not valid (= expired)
-	// 	// then it shouldn't be found on the store, we return as it is, the body was written.
-	// 	return
-	// }
 
 	copyHeaders(ctx.ResponseWriter().Header(), r.Headers())
 	ctx.SetLastModified(e.LastModified)
 	ctx.StatusCode(r.StatusCode())
 	ctx.Write(r.Body())
-
-	// fmt.Printf("key: %s\n", key)
-	// fmt.Printf("write content type: %s\n", response.Headers()["ContentType"])
-	// fmt.Printf("write body len: %d\n", len(response.Body()))
 }
 
 func copyHeaders(dst, src http.Header) {
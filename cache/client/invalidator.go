@@ -0,0 +1,188 @@
+package client
+
+import (
+	"path"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kataras/iris/v12/cache/entry"
+	"github.com/kataras/iris/v12/context"
+)
+
+const entryTagsContextKey = "iris.cache.server.entry.tags"
+
+// AddTags attaches one or more tags, e.g. "user:42", "products",
+// to the entry which will be cached for the current request.
+// Tagged entries can be removed at once through `Invalidator.PurgeTags`.
+func AddTags(ctx *context.Context, tags ...string) {
+	ctx.Values().Set(entryTagsContextKey, append(GetTags(ctx), tags...))
+}
+
+// GetTags returns the tags attached to the entry of the current request.
+func GetTags(ctx *context.Context) []string {
+	if tags, ok := ctx.Values().Get(entryTagsContextKey).([]string); ok {
+		return tags
+	}
+
+	return nil
+}
+
+type (
+	// invalidatorKey identifies an entry stored by a cache handler.
+	// The store is identified by its id, stores may not be comparable.
+	invalidatorKey struct {
+		storeID uint64
+		key     string
+	}
+
+	invalidatorItem struct {
+		store entry.Store
+		entry *entry.Entry
+		path  string
+		tags  []string
+		// onRemove is called after the entry is purged from its store.
+		onRemove func()
+	}
+)
+
+// storeIDs generates the ids of the cache handlers' stores, see `Handler.Store`.
+var storeIDs uint64
+
+func nextStoreID() uint64 {
+	return atomic.AddUint64(&storeIDs, 1)
+}
+
+// Invalidator keeps track of the cached entries by their tags and request paths,
+// so they can be removed from their stores without knowing their keys.
+//
+// Note that it only knows the entries stored by the current process,
+// entries of a shared store are not tracked across servers.
+type Invalidator struct {
+	mu    sync.RWMutex
+	items map[invalidatorKey]invalidatorItem
+	tags  map[string]map[invalidatorKey]struct{}
+}
+
+// DefaultInvalidator is the Invalidator which all cache handlers use by default.
+var DefaultInvalidator = NewInvalidator()
+
+// NewInvalidator returns a new empty Invalidator.
+// See `Handler.Invalidator` method too.
+func NewInvalidator() *Invalidator {
+	return &Invalidator{
+		items: make(map[invalidatorKey]invalidatorItem),
+		tags:  make(map[string]map[invalidatorKey]struct{}),
+	}
+}
+
+// add tracks the entry "key" of the store of "storeID".
+func (inv *Invalidator) add(storeID uint64, key string, item invalidatorItem) {
+	k := invalidatorKey{storeID, key}
+
+	inv.mu.Lock()
+	inv.removeLocked(k)
+	inv.items[k] = item
+	for _, tag := range item.tags {
+		keys, ok := inv.tags[tag]
+		if !ok {
+			keys = make(map[invalidatorKey]struct{})
+			inv.tags[tag] = keys
+		}
+		keys[k] = struct{}{}
+	}
+	inv.mu.Unlock()
+}
+
+// remove stops tracking the entry "key" of the store of "storeID".
+func (inv *Invalidator) remove(storeID uint64, key string) {
+	inv.mu.Lock()
+	inv.removeLocked(invalidatorKey{storeID, key})
+	inv.mu.Unlock()
+}
+
+func (inv *Invalidator) removeLocked(k invalidatorKey) (invalidatorItem, bool) {
+	item, ok := inv.items[k]
+	if !ok {
+		return item, false
+	}
+
+	delete(inv.items, k)
+	for _, tag := range item.tags {
+		if keys, ok := inv.tags[tag]; ok {
+			delete(keys, k)
+			if len(keys) == 0 {
+				delete(inv.tags, tag)
+			}
+		}
+	}
+
+	return item, true
+}
+
+// purge removes the given entries from their stores and returns their count.
+// The expiration of the purged entries is stopped,
+// so it does not remove the entries which are stored later on with the same keys.
+func (inv *Invalidator) purge(keys []invalidatorKey) int {
+	purged := make(map[invalidatorKey]invalidatorItem, len(keys))
+
+	inv.mu.Lock()
+	for _, k := range keys {
+		if item, ok := inv.removeLocked(k); ok {
+			purged[k] = item
+		}
+	}
+	inv.mu.Unlock()
+
+	for k, item := range purged {
+		item.store.Delete(k.key)
+		item.entry.Stop()
+		if item.onRemove != nil {
+			item.onRemove()
+		}
+	}
+
+	return len(purged)
+}
+
+// PurgeTags removes all the cached entries tagged with at least one of the given "tags".
+// Returns the number of the removed entries.
+func (inv *Invalidator) PurgeTags(tags ...string) int {
+	var keys []invalidatorKey
+
+	inv.mu.RLock()
+	seen := make(map[invalidatorKey]struct{})
+	for _, tag := range tags {
+		for k := range inv.tags[tag] {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				keys = append(keys, k)
+			}
+		}
+	}
+	inv.mu.RUnlock()
+
+	return inv.purge(keys)
+}
+
+// PurgePath removes all the cached entries which their request path
+// matches the given "pattern", e.g. "/products/*".
+// The pattern syntax is the same as the standard `path.Match` one.
+// Returns the number of the removed entries and a non-nil error
+// only when the pattern is malformed.
+func (inv *Invalidator) PurgePath(pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, err
+	}
+
+	var keys []invalidatorKey
+
+	inv.mu.RLock()
+	for k, item := range inv.items {
+		if ok, _ := path.Match(pattern, item.path); ok {
+			keys = append(keys, k)
+		}
+	}
+	inv.mu.RUnlock()
+
+	return inv.purge(keys), nil
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	stdContext "context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/memstore"
+)
+
+const cacheControlHeaderKey = "Cache-Control"
+
+// revalidate executes the route handler of the current request again, on the background,
+// and replaces the stale entry of "key" with its recorded response.
+// The middlewares registered before the cache handler are not executed again.
+// Only one background revalidation per key runs at the same time.
+func (h *Handler) revalidate(ctx *context.Context, key string) {
+	if _, loaded := h.revalidating.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	// copy the request's state now, the context is released after this handler.
+	var (
+		app      = ctx.Application()
+		r        = ctx.Request().Clone(stdContext.WithoutCancel(ctx.Request().Context()))
+		route    = ctx.GetCurrentRoute()
+		handlers = ctx.Handlers()
+		index    = ctx.HandlerIndex(-1) // the route handler's index, this cache handler is skipped.
+		params   = append(memstore.Store(nil), ctx.Params().Store...)
+		values   = append(memstore.Store(nil), *ctx.Values()...)
+	)
+
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				app.Logger().Errorf("cache: background revalidation of %s: %v", r.URL.String(), err)
+			}
+			h.revalidating.Delete(key)
+		}()
+
+		pool := app.GetContextPool()
+		bgCtx := pool.Acquire(&discardResponseWriter{header: make(http.Header)}, r)
+		defer pool.Release(bgCtx)
+
+		bgCtx.SetCurrentRoute(route)
+		bgCtx.Params().Store = params
+		*bgCtx.Values() = values
+		bgCtx.SetHandlers(handlers)
+		bgCtx.HandlerIndex(index)
+
+		// the stale response is kept on server errors.
+		h.serveAndStore(bgCtx, handlers[index], h.entryStore.Get(key))
+	}()
+}
+
+// discardResponseWriter is the response writer of the background revalidations,
+// their response is recorded by the cache handler and it's not sent anywhere.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+var _ http.ResponseWriter = (*discardResponseWriter)(nil)
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
+
+// parseCacheControlDuration returns the seconds value of the "directive"
+// of a Cache-Control header value, e.g. "stale-while-revalidate=60".
+func parseCacheControlDuration(cacheControl, directive string) (time.Duration, bool) {
+	for _, part := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !strings.EqualFold(name, directive) {
+			continue
+		}
+
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		if err != nil || seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+// varyEntry holds the request header names that the responses of an entry key
+// vary on, collected from their "Vary" header, and the keys of their stored variants.
+// It's removed when all of its variants are removed from the store.
+type varyEntry struct {
+	headerNames []string
+	variants    map[string]struct{}
+}
+
+// variantKey returns the "key" extended with the values of the request headers
+// and cookies that the cached response varies on.
+func (h *Handler) variantKey(ctx *context.Context, key string) string {
+	headerNames := h.varyHeaders
+
+	h.variesMu.RLock()
+	if v, ok := h.varies[key]; ok {
+		for _, name := range v.headerNames {
+			headerNames = appendVaryName(headerNames, name)
+		}
+	}
+	h.variesMu.RUnlock()
+
+	if len(headerNames) == 0 && len(h.varyCookies) == 0 {
+		return key
+	}
+
+	var b strings.Builder
+	b.WriteString(key)
+
+	header := ctx.Request().Header
+	for _, name := range headerNames {
+		b.WriteString("|h:")
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strings.Join(header.Values(name), ","))
+	}
+
+	for _, name := range h.varyCookies {
+		b.WriteString("|c:")
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(ctx.GetCookie(name))
+	}
+
+	return b.String()
+}
+
+// learnVary saves the request header names that the responses of "key" vary on.
+func (h *Handler) learnVary(key string, headerNames []string) {
+	if len(headerNames) == 0 {
+		return
+	}
+
+	h.variesMu.Lock()
+	defer h.variesMu.Unlock()
+
+	v, ok := h.varies[key]
+	if !ok {
+		if h.varies == nil {
+			h.varies = make(map[string]*varyEntry)
+		}
+
+		v = &varyEntry{variants: make(map[string]struct{})}
+		h.varies[key] = v
+	}
+
+	names := append([]string(nil), v.headerNames...)
+	for _, name := range headerNames {
+		names = appendVaryName(names, name)
+	}
+	sort.Strings(names)
+
+	v.headerNames = names
+}
+
+// addVariant records that the "variantKey" entry of "key" is stored.
+func (h *Handler) addVariant(key, variantKey string) {
+	h.variesMu.Lock()
+	if v, ok := h.varies[key]; ok {
+		v.variants[variantKey] = struct{}{}
+	}
+	h.variesMu.Unlock()
+}
+
+// removeVariant records that the "variantKey" entry of "key" is removed from the store,
+// the header names of "key" are forgotten when it has no more variants.
+func (h *Handler) removeVariant(key, variantKey string) {
+	h.variesMu.Lock()
+	if v, ok := h.varies[key]; ok {
+		delete(v.variants, variantKey)
+		if len(v.variants) == 0 {
+			delete(h.varies, key)
+		}
+	}
+	h.variesMu.Unlock()
+}
+
+// parseVary returns the canonical request header names of the "Vary" response header.
+// It reports false if the response varies on everything ("Vary: *").
+func parseVary(header http.Header) ([]string, bool) {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			if name == "*" {
+				return nil, false
+			}
+
+			names = appendVaryName(names, textproto.CanonicalMIMEHeaderKey(name))
+		}
+	}
+
+	return names, true
+}
+
+func appendVaryName(names []string, name string) []string {
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+
+	return append(names, name)
+}
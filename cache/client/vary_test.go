@@ -0,0 +1,26 @@
+package client
+
+import "testing"
+
+func TestVaryEntries(t *testing.T) {
+	h := NewHandler(nil)
+
+	key := "GET/products"
+	gzipKey, brKey := key+"|h:Accept-Encoding=gzip", key+"|h:Accept-Encoding=br"
+
+	h.learnVary(key, []string{"Accept-Encoding"})
+	h.addVariant(key, gzipKey)
+	h.addVariant(key, brKey)
+
+	h.removeVariant(key, gzipKey)
+	if _, ok := h.varies[key]; !ok {
+		t.Fatalf("expected the vary entry of %s to be kept while it has stored variants", key)
+	}
+
+	// the header names are forgotten when the last variant
+	// expires or it's purged, so they don't outlive the cached responses.
+	h.removeVariant(key, brKey)
+	if n := len(h.varies); n != 0 {
+		t.Fatalf("expected no vary entries but got %d", n)
+	}
+}
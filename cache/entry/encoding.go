@@ -0,0 +1,135 @@
+package entry
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"net/http"
+	"time"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Response)(nil)
+	_ encoding.BinaryUnmarshaler = (*Response)(nil)
+	_ encoding.BinaryMarshaler   = (*Entry)(nil)
+	_ encoding.BinaryUnmarshaler = (*Entry)(nil)
+)
+
+// responseData is the serializable form of a Response.
+type responseData struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+}
+
+// entryData is the serializable form of an Entry.
+type entryData struct {
+	Response             responseData
+	LastModified         time.Time
+	ExpiresAt            time.Time
+	StaleAt              time.Time
+	StaleWhileRevalidate time.Duration
+	StaleIfError         time.Duration
+}
+
+func (r *Response) data() responseData {
+	return responseData{
+		StatusCode: r.statusCode,
+		Headers:    r.headers,
+		Body:       r.body,
+	}
+}
+
+func (r *Response) setData(d responseData) {
+	r.statusCode = d.StatusCode
+	r.headers = d.Headers
+	r.body = d.Body
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// It encodes the status code, headers and body of the response
+// so it can be saved to a persistent or distributed store.
+func (r *Response) MarshalBinary() ([]byte, error) {
+	return encode(r.data())
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It decodes a response encoded by `MarshalBinary`.
+func (r *Response) UnmarshalBinary(data []byte) error {
+	var d responseData
+	if err := decode(data, &d); err != nil {
+		return err
+	}
+
+	r.setData(d)
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// It encodes the entry's response, its expiration and stale information
+// so it can be saved to a persistent or distributed store.
+func (e *Entry) MarshalBinary() ([]byte, error) {
+	d := entryData{
+		LastModified:         e.LastModified,
+		ExpiresAt:            e.expiresAt,
+		StaleAt:              e.staleAt,
+		StaleWhileRevalidate: e.staleWhileRevalidate,
+		StaleIfError:         e.staleIfError,
+	}
+
+	if e.response != nil {
+		d.Response = e.response.data()
+	}
+
+	return encode(d)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It decodes an entry encoded by `MarshalBinary`.
+// The decoded entry is not tracked by a Pool,
+// its store is responsible to remove it when expires.
+func (e *Entry) UnmarshalBinary(data []byte) error {
+	var d entryData
+	if err := decode(data, &d); err != nil {
+		return err
+	}
+
+	r := new(Response)
+	r.setData(d.Response)
+
+	e.response = r
+	e.LastModified = d.LastModified
+	e.expiresAt = d.ExpiresAt
+	e.staleAt = d.StaleAt
+	e.staleWhileRevalidate = d.StaleWhileRevalidate
+	e.staleIfError = d.StaleIfError
+	return nil
+}
+
+// Encode returns the binary form of "e", see `Entry.MarshalBinary`.
+func Encode(e *Entry) ([]byte, error) {
+	return e.MarshalBinary()
+}
+
+// Decode returns a new Entry from its binary form, see `Entry.UnmarshalBinary`.
+func Decode(data []byte) (*Entry, error) {
+	e := new(Entry)
+	if err := e.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func encode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decode(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
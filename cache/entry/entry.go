@@ -17,6 +17,16 @@ type Entry struct {
 	// some clients may need it.
 	LastModified time.Time
 
+	// expiresAt is the time which this entry is removed from the store.
+	expiresAt time.Time
+	// staleAt is the time which this entry's response is no longer fresh,
+	// it may still be served until the stale windows below are passed.
+	staleAt time.Time
+	// staleWhileRevalidate and staleIfError are the durations, after staleAt,
+	// that this entry's response is allowed to be served stale.
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+
 	// Response the response should be served to the client
 	response *Response
 	// but we need the key to invalidate manually...xmm
@@ -26,8 +36,58 @@ type Entry struct {
 
 // reset called each time a new entry is acquired from the pool.
 func (e *Entry) reset(lt *memstore.LifeTime, r *Response) {
+	e.lifeTime = lt
 	e.response = r
 	e.LastModified = lt.Begun
+	e.expiresAt = lt.Time
+	e.staleAt = time.Time{}
+	e.staleWhileRevalidate = 0
+	e.staleIfError = 0
+}
+
+// ExpiresAt returns the time which this entry should be removed from the store,
+// stale windows included. It returns a zero time if the entry never expires.
+func (e *Entry) ExpiresAt() time.Time {
+	return e.expiresAt
+}
+
+// IsStale reports whether this entry's response is no longer fresh.
+// A stale entry may still live in the store
+// when stale-while-revalidate or stale-if-error windows are set.
+func (e *Entry) IsStale() bool {
+	return !e.staleAt.IsZero() && !memstore.Clock().Before(e.staleAt)
+}
+
+// StaleWhileRevalidate reports whether this entry's response is stale
+// but it can still be served while it's being revalidated on the background.
+func (e *Entry) StaleWhileRevalidate() bool {
+	return e.IsStale() && e.staleWhileRevalidate > 0 &&
+		memstore.Clock().Before(e.staleAt.Add(e.staleWhileRevalidate))
+}
+
+// StaleIfError reports whether this entry's response is stale
+// but it can still be served when the origin handler fails with a server error.
+func (e *Entry) StaleIfError() bool {
+	return e.IsStale() && e.staleIfError > 0 &&
+		memstore.Clock().Before(e.staleAt.Add(e.staleIfError))
+}
+
+// Stop stops the expiration timer of this entry, so its expiration callback is not fired.
+// It's called when the entry is removed from its store before it expires, e.g. on purge.
+func (e *Entry) Stop() {
+	if e.lifeTime != nil {
+		e.lifeTime.ExpireNow()
+	}
+}
+
+// Same reports whether "e" and "other" are the same stored entry,
+// including copies of it decoded from a persistent or distributed store.
+func (e *Entry) Same(other *Entry) bool {
+	if e == other {
+		return true
+	}
+
+	return other != nil && e.LastModified.Equal(other.LastModified) && e.expiresAt.Equal(other.expiresAt)
 }
 
 // Response returns the cached response as it's.
@@ -0,0 +1,81 @@
+package entry
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEntryEncoding(t *testing.T) {
+	r := NewResponse(http.StatusCreated, http.Header{"Content-Type": {"text/plain"}}, []byte("body"))
+	e := NewPool().AcquireStale(time.Minute, time.Second, 0, r, func(*Entry) {})
+
+	data, err := Encode(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := r.StatusCode(), got.Response().StatusCode(); expected != got {
+		t.Fatalf("expected status code: %d but got: %d", expected, got)
+	}
+
+	if expected, got := r.Headers().Get("Content-Type"), got.Response().Headers().Get("Content-Type"); expected != got {
+		t.Fatalf("expected content type: %q but got: %q", expected, got)
+	}
+
+	if expected, got := r.Body(), got.Response().Body(); !bytes.Equal(expected, got) {
+		t.Fatalf("expected body: %q but got: %q", expected, got)
+	}
+
+	if !e.ExpiresAt().Equal(got.ExpiresAt()) {
+		t.Fatalf("expected expiration: %s but got: %s", e.ExpiresAt(), got.ExpiresAt())
+	}
+
+	if got.IsStale() {
+		t.Fatalf("expected a fresh entry")
+	}
+
+	if !got.Same(e) {
+		t.Fatalf("expected the decoded entry to be the same as the encoded one")
+	}
+}
+
+func TestLRUStore(t *testing.T) {
+	newEntry := func(body string) *Entry {
+		return &Entry{response: NewResponse(http.StatusOK, nil, []byte(body))}
+	}
+
+	s := NewLRUStore(LRUOptions{MaxEntries: 2})
+	s.Set("a", newEntry("a"))
+	s.Set("b", newEntry("b"))
+	s.Get("a") // "b" is the least recently used now.
+	s.Set("c", newEntry("c"))
+
+	if s.Get("b") != nil {
+		t.Fatalf("expected entry 'b' to be evicted")
+	}
+
+	if s.Get("a") == nil || s.Get("c") == nil {
+		t.Fatalf("expected entries 'a' and 'c' to exist")
+	}
+
+	stats := s.Stats()
+	if stats.Entries != 2 || stats.Evictions != 1 || stats.Hits != 3 || stats.Misses != 1 {
+		t.Fatalf("unexpected stats: %#+v", stats)
+	}
+
+	s = NewLRUStore(LRUOptions{MaxBytes: 10})
+	s.Set("a", newEntry("1234"))  // 5 bytes.
+	s.Set("b", newEntry("1234"))  // 10 bytes.
+	s.Set("c", newEntry("12345")) // 16 bytes, evicts both "a" and "b".
+
+	if stats = s.Stats(); stats.Entries != 1 || stats.Bytes != 6 || stats.Evictions != 2 {
+		t.Fatalf("unexpected stats: %#+v", stats)
+	}
+}
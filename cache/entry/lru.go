@@ -0,0 +1,143 @@
+package entry
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRUOptions holds the limits of an LRU store.
+// Zero values mean no limit.
+type LRUOptions struct {
+	// MaxEntries is the maximum number of entries to keep.
+	MaxEntries int
+	// MaxBytes is the maximum total size, in bytes,
+	// of the stored keys, response headers and bodies.
+	MaxBytes int64
+}
+
+// LRUStats holds the metrics of an LRU store.
+type LRUStats struct {
+	Entries   int
+	Bytes     int64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type lruItem struct {
+	key   string
+	entry *Entry
+	size  int64
+}
+
+// LRUStore is an in-memory store for the cache entries,
+// bounded by size or number of entries.
+// When a limit is exceeded the least recently used entries are evicted.
+type LRUStore struct {
+	opts LRUOptions
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	stats LRUStats
+}
+
+var _ Store = (*LRUStore)(nil)
+
+// NewLRUStore returns a new in-memory store for the cache entries
+// which evicts the least recently used entries when the given limits are exceeded.
+func NewLRUStore(opts LRUOptions) *LRUStore {
+	return &LRUStore{
+		opts:  opts,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get returns an entry based on its key.
+func (s *LRUStore) Get(key string) *Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		s.stats.Misses++
+		return nil
+	}
+
+	s.stats.Hits++
+	s.ll.MoveToFront(el)
+	return el.Value.(*lruItem).entry
+}
+
+// Set sets an entry based on its key.
+func (s *LRUStore) Set(key string, e *Entry) {
+	item := &lruItem{key: key, entry: e, size: entrySize(key, e)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		s.removeElement(el)
+	}
+
+	s.items[key] = s.ll.PushFront(item)
+	s.stats.Entries++
+	s.stats.Bytes += item.size
+
+	for s.exceeds() {
+		el := s.ll.Back()
+		if el == nil || el == s.items[key] {
+			// do not evict the new entry itself.
+			break
+		}
+
+		s.removeElement(el)
+		s.stats.Evictions++
+	}
+}
+
+// Delete deletes an entry based on its key.
+func (s *LRUStore) Delete(key string) {
+	s.mu.Lock()
+	if el, ok := s.items[key]; ok {
+		s.removeElement(el)
+	}
+	s.mu.Unlock()
+}
+
+// Stats returns the current metrics of the store.
+func (s *LRUStore) Stats() LRUStats {
+	s.mu.Lock()
+	stats := s.stats
+	s.mu.Unlock()
+	return stats
+}
+
+func (s *LRUStore) exceeds() bool {
+	return (s.opts.MaxEntries > 0 && s.stats.Entries > s.opts.MaxEntries) ||
+		(s.opts.MaxBytes > 0 && s.stats.Bytes > s.opts.MaxBytes)
+}
+
+func (s *LRUStore) removeElement(el *list.Element) {
+	item := s.ll.Remove(el).(*lruItem)
+	delete(s.items, item.key)
+	s.stats.Entries--
+	s.stats.Bytes -= item.size
+}
+
+// entrySize returns the approximate memory size of a stored entry.
+func entrySize(key string, e *Entry) int64 {
+	size := int64(len(key))
+	if r := e.Response(); r != nil {
+		size += int64(len(r.Body()))
+		for k, values := range r.Headers() {
+			size += int64(len(k))
+			for _, v := range values {
+				size += int64(len(v))
+			}
+		}
+	}
+
+	return size
+}
@@ -25,21 +25,44 @@ func NewPool() *Pool {
 // Acquire returns an Entry from pool.
 // See Release.
 func (c *Pool) Acquire(lifeDuration time.Duration, r *Response, onExpire func()) *Entry {
+	return c.AcquireStale(lifeDuration, 0, 0, r, func(*Entry) { onExpire() })
+}
+
+// AcquireStale like Acquire but the returned Entry is kept,
+// after "lifeDuration" is passed, as stale for the longest of the
+// "staleWhileRevalidate" and "staleIfError" durations.
+// See Entry.StaleWhileRevalidate and Entry.StaleIfError.
+//
+// The "onExpire" receives the expired entry, so it can check that
+// the entry was not replaced in its store before it removes it.
+func (c *Pool) AcquireStale(lifeDuration, staleWhileRevalidate, staleIfError time.Duration, r *Response, onExpire func(e *Entry)) *Entry {
 	// If the given duration is not <=0 (which means finds from the headers)
 	// then we should check for the MinimumCacheDuration here
 	if lifeDuration >= 0 && lifeDuration < cfg.MinimumCacheDuration {
 		lifeDuration = cfg.MinimumCacheDuration
 	}
 
+	staleDuration := max(staleWhileRevalidate, staleIfError, 0)
+	if lifeDuration <= 0 {
+		// never expires, so it's never stale.
+		staleDuration = 0
+	}
+
 	e := c.pool.Get().(*Entry)
 
 	lt := memstore.NewLifeTime()
-	lt.Begin(lifeDuration, func() {
-		onExpire()
+	lt.Begin(lifeDuration+staleDuration, func() {
+		onExpire(e)
 		c.release(e)
 	})
 
 	e.reset(lt, r)
+	if staleDuration > 0 {
+		e.staleAt = lt.Begun.Add(lifeDuration)
+		e.staleWhileRevalidate = staleWhileRevalidate
+		e.staleIfError = staleIfError
+	}
+
 	return e
 }
 
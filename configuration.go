@@ -365,6 +365,13 @@ var WithResetOnFireErrorCode = func(app *Application) {
 	app.config.ResetOnFireErrorCode = true
 }
 
+// WithProblemDetails sets the EnableProblemDetails setting to true.
+//
+// See `Configuration`.
+var WithProblemDetails = func(app *Application) {
+	app.config.EnableProblemDetails = true
+}
+
 // WithURLParamSeparator sets the URLParamSeparator setting to "sep".
 //
 // See `Configuration`.
@@ -783,6 +790,14 @@ type Configuration struct {
 	//
 	// Defaults to false.
 	ResetOnFireErrorCode bool `ini:"reset_on_fire_error_code" json:"resetOnFireErrorCode,omitempty" yaml:"ResetOnFireErrorCode" toml:"ResetOnFireErrorCode"`
+	// EnableProblemDetails if true then the default HTTP error handler, the x/errors package
+	// and the recover middleware render the errors as RFC 9457 Problem Details,
+	// "application/problem+json" or "application/problem+xml" when the client prefers XML,
+	// so clients can parse a single error format.
+	// See `Context.WriteProblemDetails` for more details.
+	//
+	// Defaults to false.
+	EnableProblemDetails bool `ini:"enable_problem_details" json:"enableProblemDetails,omitempty" yaml:"EnableProblemDetails" toml:"EnableProblemDetails"`
 
 	// URLParamSeparator defines the character(s) separator for Context.URLParamSlice.
 	// If empty or null then request url parameters with comma separated values will be retrieved as one.
@@ -1091,6 +1106,11 @@ func (c *Configuration) GetResetOnFireErrorCode() bool {
 	return c.ResetOnFireErrorCode
 }
 
+// GetEnableProblemDetails returns the EnableProblemDetails field.
+func (c *Configuration) GetEnableProblemDetails() bool {
+	return c.EnableProblemDetails
+}
+
 // GetURLParamSeparator returns URLParamSeparator field.
 func (c *Configuration) GetURLParamSeparator() *string {
 	return c.URLParamSeparator
@@ -1287,6 +1307,10 @@ func WithConfiguration(c Configuration) Configurator {
 			main.ResetOnFireErrorCode = v
 		}
 
+		if v := c.EnableProblemDetails; v {
+			main.EnableProblemDetails = v
+		}
+
 		if v := c.URLParamSeparator; v != nil {
 			main.URLParamSeparator = v
 		}
@@ -1412,6 +1436,7 @@ func DefaultConfiguration() Configuration {
 		FireEmptyFormError:                false,
 		DisableAutoFireStatusCode:         false,
 		ResetOnFireErrorCode:              false,
+		EnableProblemDetails:              false,
 		URLParamSeparator:                 toStringPtr(","),
 		TimeFormat:                        "Mon, 02 Jan 2006 15:04:05 GMT",
 		Charset:                           "utf-8",
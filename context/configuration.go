@@ -44,6 +44,8 @@ type ConfigurationReadOnly interface {
 	GetDisableAutoFireStatusCode() bool
 	// GetResetOnFireErrorCode returns the ResetOnFireErrorCode field.
 	GetResetOnFireErrorCode() bool
+	// GetEnableProblemDetails returns the EnableProblemDetails field.
+	GetEnableProblemDetails() bool
 	// GetURLParamSeparator returns URLParamSeparator field.
 	GetURLParamSeparator() *string
 	// GetEnableOptimizations returns the EnableOptimizations field.
@@ -482,6 +482,15 @@ func (ctx *Context) ResetResponseWriter(newResponseWriter ResponseWriter) {
 	ctx.writer = newResponseWriter
 }
 
+// SwapResponseWriter sets a new ResponseWriter to this Context and returns the previous one.
+// Unlike `ResetResponseWriter`, a previous response recorder is not released,
+// so it can be set back later on, e.g. after the next handlers are executed.
+func (ctx *Context) SwapResponseWriter(newResponseWriter ResponseWriter) ResponseWriter {
+	w := ctx.writer
+	ctx.writer = newResponseWriter
+	return w
+}
+
 // Request returns the original *http.Request, as expected.
 func (ctx *Context) Request() *http.Request {
 	return ctx.request
@@ -820,6 +829,12 @@ func (ctx *Context) StopWithError(statusCode int, err error) {
 		return
 	}
 
+	if ctx.app.ConfigurationReadOnly().GetEnableProblemDetails() {
+		ctx.StopWithStatus(statusCode)
+		ctx.WriteProblemDetails(statusCode, NewProblem().DetailErr(err))
+		return
+	}
+
 	ctx.StopWithText(statusCode, err.Error())
 }
 
@@ -4025,6 +4040,8 @@ const (
 	ContentMultipartRelatedHeaderValue = "multipart/related"
 	// ContentGRPCHeaderValue Content-Type header value for gRPC.
 	ContentGRPCHeaderValue = "application/grpc"
+	// ContentEventStreamHeaderValue Content-Type header value for Server-Sent Events.
+	ContentEventStreamHeaderValue = "text/event-stream"
 )
 
 // Binary writes out the raw bytes as binary data.
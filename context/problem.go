@@ -336,3 +336,37 @@ func (o *ProblemOptions) Apply(ctx *Context) {
 		ctx.Header("Retry-After", retryAfterHeaderValue)
 	}
 }
+
+// WriteProblemDetails writes the "problem" with the "statusCode" as the response.
+// It is rendered as "application/problem+xml" when the client prefers XML
+// through its "Accept" request header, otherwise as "application/problem+json".
+// A missing "title" is set to the status code's text.
+//
+// The framework itself renders its errors through this method
+// when the `Configuration.EnableProblemDetails` setting is true.
+func (ctx *Context) WriteProblemDetails(statusCode int, problem Problem) error {
+	if problem == nil {
+		problem = NewProblem()
+	}
+
+	problem.Status(statusCode)
+
+	options := DefaultProblemOptions
+	options.RenderXML = ctx.prefersXML()
+	return ctx.Problem(problem, options)
+}
+
+// prefersXML reports whether the first of the accepted content types
+// of the client, which is either XML or JSON, is XML.
+func (ctx *Context) prefersXML() bool {
+	for _, accept := range parseHeader(ctx.GetHeader("Accept")) {
+		switch {
+		case strings.HasSuffix(accept, "xml"):
+			return true
+		case strings.HasSuffix(accept, "json"):
+			return false
+		}
+	}
+
+	return false
+}
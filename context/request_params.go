@@ -2,6 +2,7 @@ package context
 
 import (
 	"fmt"
+	"net/netip"
 	"reflect"
 	"strconv"
 	"strings"
@@ -365,6 +366,36 @@ var ParamResolvers = map[reflect.Type]func(paramIndex int) interface{}{
 			return v
 		}
 	},
+	reflect.TypeOf(time.Duration(0)): func(paramIndex int) interface{} {
+		return func(ctx *Context) time.Duration {
+			if ctx.Params().Len() <= paramIndex {
+				return 0
+			}
+
+			v, _ := ctx.Params().GetEntryAt(paramIndex).ValueRaw.(time.Duration)
+			return v
+		}
+	},
+	reflect.TypeOf([]byte{}): func(paramIndex int) interface{} {
+		return func(ctx *Context) []byte {
+			if ctx.Params().Len() <= paramIndex {
+				return nil
+			}
+
+			v, _ := ctx.Params().GetEntryAt(paramIndex).ValueRaw.([]byte)
+			return v
+		}
+	},
+	reflect.TypeOf(netip.Addr{}): func(paramIndex int) interface{} {
+		return func(ctx *Context) netip.Addr {
+			if ctx.Params().Len() <= paramIndex {
+				return netip.Addr{}
+			}
+
+			v, _ := ctx.Params().GetEntryAt(paramIndex).ValueRaw.(netip.Addr)
+			return v
+		}
+	},
 }
 
 // ParamResolverByTypeAndIndex will return a function that can be used to bind path parameter's exact value by its Go std type
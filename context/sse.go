@@ -0,0 +1,502 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LastEventIDHeaderKey is the request header key which a Server-Sent Events client
+// sends on reconnection, its value is the id of the last event it received.
+const LastEventIDHeaderKey = "Last-Event-ID"
+
+// SSEEvent is a Server-Sent Event.
+// Read more at: https://html.spec.whatwg.org/multipage/server-sent-events.html.
+type SSEEvent struct {
+	// ID is the optional event id, the client sends it back
+	// through the "Last-Event-ID" request header on reconnection.
+	ID string `json:"id,omitempty"`
+	// Name is the optional event name (the "event" field),
+	// clients listen to it through addEventListener(name, ...).
+	// Defaults to "message" on the client-side.
+	Name string `json:"event,omitempty"`
+	// Data is the event's payload. A string or a []byte value is sent as it is,
+	// any other value is encoded as JSON.
+	Data interface{} `json:"data,omitempty"`
+	// Retry, if not zero, is the reconnection time hint to the client.
+	Retry time.Duration `json:"retry,omitempty"`
+}
+
+// encode writes the event in the text/event-stream format.
+func (e SSEEvent) encode(b *bytes.Buffer) error {
+	if e.ID != "" {
+		b.WriteString("id: ")
+		b.WriteString(sseSanitize(e.ID))
+		b.WriteByte('\n')
+	}
+
+	if e.Name != "" {
+		b.WriteString("event: ")
+		b.WriteString(sseSanitize(e.Name))
+		b.WriteByte('\n')
+	}
+
+	if e.Retry > 0 {
+		b.WriteString("retry: ")
+		b.WriteString(strconv.FormatInt(e.Retry.Milliseconds(), 10))
+		b.WriteByte('\n')
+	}
+
+	if e.Data != nil {
+		var data string
+		switch v := e.Data.(type) {
+		case string:
+			data = v
+		case []byte:
+			data = string(v)
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			data = string(encoded)
+		}
+
+		data = strings.ReplaceAll(data, "\r\n", "\n")
+		for _, line := range strings.Split(data, "\n") {
+			b.WriteString("data: ")
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+
+	b.WriteByte('\n')
+	return nil
+}
+
+func sseSanitize(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// ErrSSEClosed is returned by the SSEWriter's methods
+// when the client has been disconnected.
+var ErrSSEClosed = errors.New("sse: client disconnected")
+
+// SSEWriter writes Server-Sent Events to a client.
+// It's safe for concurrent use.
+// Initialize it through the `Context.SSE` method.
+type SSEWriter struct {
+	ctx *Context
+
+	mu          sync.Mutex
+	buf         bytes.Buffer
+	lastEventID string
+}
+
+// SSE prepares the response for streaming Server-Sent Events and returns a writer for them.
+// It sets the "text/event-stream" content type, disables the response compression
+// and any proxy buffering and sends the response headers to the client.
+//
+// Example Code:
+//
+//	app.Get("/events", func(ctx iris.Context) {
+//		sse, err := ctx.SSE()
+//		if err != nil {
+//			ctx.StopWithError(iris.StatusInternalServerError, err)
+//			return
+//		}
+//
+//		stop := sse.Heartbeat(15 * time.Second)
+//		defer stop()
+//
+//		for {
+//			select {
+//			case <-sse.Done():
+//				return
+//			case msg := <-messages:
+//				sse.Send(iris.SSEEvent{Name: "message", Data: msg})
+//			}
+//		}
+//	})
+//
+// See `SSEBroker` and `EventStream` too.
+func (ctx *Context) SSE() (*SSEWriter, error) {
+	if err := ctx.CompressWriter(false); err != nil {
+		return nil, err
+	}
+
+	header := ctx.ResponseWriter().Header()
+	header.Set(ContentTypeHeaderKey, ContentEventStreamHeaderValue)
+	header.Set(CacheControlHeaderKey, "no-cache")
+	header.Set("X-Accel-Buffering", "no") // disable nginx buffering.
+	header.Del(ContentLengthHeaderKey)
+
+	ctx.StatusCode(http.StatusOK)
+	ctx.writer.Flush()
+
+	return &SSEWriter{
+		ctx:         ctx,
+		lastEventID: ctx.GetHeader(LastEventIDHeaderKey),
+	}, nil
+}
+
+// LastEventID returns the id of the last event the client received
+// before a reconnection, as sent by the "Last-Event-ID" request header.
+// Resume the stream from the next event.
+func (w *SSEWriter) LastEventID() string {
+	return w.lastEventID
+}
+
+// Done returns a channel which is closed when the client is disconnected.
+func (w *SSEWriter) Done() <-chan struct{} {
+	return w.ctx.Request().Context().Done()
+}
+
+func (w *SSEWriter) isClosed() bool {
+	select {
+	case <-w.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// Send writes an event to the client and flushes it.
+func (w *SSEWriter) Send(e SSEEvent) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.isClosed() {
+		return ErrSSEClosed
+	}
+
+	w.buf.Reset()
+	if err := e.encode(&w.buf); err != nil {
+		return err
+	}
+
+	return w.flush()
+}
+
+// Retry sends a reconnection time hint to the client.
+func (w *SSEWriter) Retry(d time.Duration) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.isClosed() {
+		return ErrSSEClosed
+	}
+
+	w.buf.Reset()
+	w.buf.WriteString("retry: ")
+	w.buf.WriteString(strconv.FormatInt(d.Milliseconds(), 10))
+	w.buf.WriteString("\n\n")
+	return w.flush()
+}
+
+// Comment sends a comment line, clients ignore it.
+// It's mostly used to keep the connection alive, see `Heartbeat`.
+func (w *SSEWriter) Comment(text string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.isClosed() {
+		return ErrSSEClosed
+	}
+
+	w.buf.Reset()
+	w.buf.WriteString(": ")
+	w.buf.WriteString(sseSanitize(text))
+	w.buf.WriteString("\n\n")
+	return w.flush()
+}
+
+// Heartbeat sends an empty comment every "interval" to keep the connection alive
+// through proxies which close idle connections.
+// It returns a function which stops the heartbeats and waits
+// for a pending one to be written, call it before the handler returns.
+func (w *SSEWriter) Heartbeat(interval time.Duration) (stop func()) {
+	var (
+		done = make(chan struct{})
+		once sync.Once
+		wg   sync.WaitGroup
+	)
+	stop = func() {
+		once.Do(func() { close(done) })
+		wg.Wait()
+	}
+
+	if interval <= 0 {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-w.Done():
+				return
+			case <-ticker.C:
+				if err := w.Comment(""); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return
+}
+
+func (w *SSEWriter) flush() error {
+	if _, err := w.ctx.writer.Write(w.buf.Bytes()); err != nil {
+		return err
+	}
+
+	w.ctx.writer.Flush()
+	return nil
+}
+
+// EventStream is a result type, e.g. for hero and MVC handlers,
+// which streams the events of a channel as Server-Sent Events
+// until the channel is closed or the client is disconnected.
+//
+// Example Code:
+//
+//	app.ConfigureContainer().Get("/events", func() iris.EventStream {
+//		events := make(chan iris.SSEEvent)
+//		go produce(events)
+//		return iris.EventStream{Events: events, Heartbeat: 15 * time.Second}
+//	})
+type EventStream struct {
+	// Events is the channel of the events to send.
+	Events <-chan SSEEvent
+	// Heartbeat, if not zero, sends a comment on this interval to keep the connection alive.
+	Heartbeat time.Duration
+	// Retry, if not zero, sends a reconnection time hint to the client on connect.
+	Retry time.Duration
+}
+
+// Dispatch streams the events to the client.
+// It completes the hero.Result interface.
+func (s EventStream) Dispatch(ctx *Context) {
+	w, err := ctx.SSE()
+	if err != nil {
+		ctx.StopWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if s.Retry > 0 {
+		if err = w.Retry(s.Retry); err != nil {
+			return
+		}
+	}
+
+	s.stream(w)
+}
+
+func (s EventStream) stream(w *SSEWriter) {
+	stop := w.Heartbeat(s.Heartbeat)
+	defer stop()
+
+	for {
+		select {
+		case <-w.Done():
+			return
+		case e, ok := <-s.Events:
+			if !ok {
+				return
+			}
+
+			if err := w.Send(e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// SSEBroker broadcasts Server-Sent Events to all of its subscribers.
+// It keeps a bounded history of the published events so clients
+// which reconnect with a "Last-Event-ID" header receive the events they missed.
+//
+// Initialize it through the `NewSSEBroker` package-level function
+// and register its `Handler` method as a route handler.
+type SSEBroker struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []SSEEvent
+	historySize int
+	subscribers map[chan SSEEvent]struct{}
+	closed      bool
+
+	// Heartbeat, if not zero, is the interval of the keep-alive comments
+	// sent by the `Handler` method. Defaults to 15 seconds.
+	Heartbeat time.Duration
+	// BufferSize is the number of the pending events per subscriber.
+	// A subscriber which falls behind this number of events is disconnected
+	// and it should reconnect to resume from its last event.
+	// Defaults to 32.
+	BufferSize int
+}
+
+// NewSSEBroker returns a new Server-Sent Events broker
+// which keeps the last "historySize" published events for resumption.
+//
+// Example Code:
+//
+//	broker := iris.NewSSEBroker(100)
+//	app.Get("/events", broker.Handler)
+//	app.Post("/messages", func(ctx iris.Context) {
+//		broker.Publish(iris.SSEEvent{Name: "message", Data: ctx.FormValue("text")})
+//	})
+func NewSSEBroker(historySize int) *SSEBroker {
+	if historySize < 0 {
+		historySize = 0
+	}
+
+	return &SSEBroker{
+		historySize: historySize,
+		subscribers: make(map[chan SSEEvent]struct{}),
+		Heartbeat:   15 * time.Second,
+		BufferSize:  32,
+	}
+}
+
+// Publish sends the event "e" to all subscribers.
+// An empty event ID is set to the next sequence number of the broker.
+// Returns the event's ID.
+func (b *SSEBroker) Publish(e SSEEvent) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ""
+	}
+
+	b.nextID++
+	if e.ID == "" {
+		e.ID = strconv.FormatUint(b.nextID, 10)
+	}
+
+	if b.historySize > 0 {
+		if len(b.history) >= b.historySize {
+			copy(b.history, b.history[1:])
+			b.history = b.history[:len(b.history)-1]
+		}
+		b.history = append(b.history, e)
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			// slow subscriber, disconnect it.
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return e.ID
+}
+
+// Subscribe registers a new subscriber and returns its events channel.
+// If "lastEventID" is not empty and it exists in the history,
+// the events published after it are sent first.
+// The channel is closed when the subscriber is removed,
+// call `Unsubscribe` when the subscriber is no longer interested in the events.
+func (b *SSEBroker) Subscribe(lastEventID string) <-chan SSEEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []SSEEvent
+	if lastEventID != "" {
+		for i, e := range b.history {
+			if e.ID == lastEventID {
+				missed = b.history[i+1:]
+				break
+			}
+		}
+	}
+
+	bufferSize := b.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 32
+	}
+
+	ch := make(chan SSEEvent, max(bufferSize, len(missed)))
+	for _, e := range missed {
+		ch <- e
+	}
+
+	if b.closed {
+		close(ch)
+		return ch
+	}
+
+	b.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel.
+func (b *SSEBroker) Unsubscribe(events <-chan SSEEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		if (<-chan SSEEvent)(ch) == events {
+			delete(b.subscribers, ch)
+			close(ch)
+			return
+		}
+	}
+}
+
+// Len returns the number of the active subscribers.
+func (b *SSEBroker) Len() int {
+	b.mu.Lock()
+	n := len(b.subscribers)
+	b.mu.Unlock()
+	return n
+}
+
+// Close disconnects all subscribers. Publish is a no-op after Close.
+func (b *SSEBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Handler is a route handler which subscribes the client
+// and streams the published events to it, resuming from its "Last-Event-ID".
+func (b *SSEBroker) Handler(ctx *Context) {
+	w, err := ctx.SSE()
+	if err != nil {
+		ctx.StopWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	events := b.Subscribe(w.LastEventID())
+	defer b.Unsubscribe(events)
+
+	EventStream{Events: events, Heartbeat: b.Heartbeat}.stream(w)
+}
@@ -0,0 +1,76 @@
+package context_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestSSE(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		sse, err := ctx.SSE()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sse.Retry(2 * time.Second)
+		sse.Comment("hello")
+		sse.Send(iris.SSEEvent{ID: "1", Name: "greet", Data: "first\nsecond"})
+		sse.Send(iris.SSEEvent{Data: iris.Map{"last": sse.LastEventID()}})
+	})
+
+	app.Get("/heartbeat", func(ctx iris.Context) {
+		sse, err := ctx.SSE()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stop := sse.Heartbeat(time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		stop()
+		// no heartbeat is written after stop.
+		sse.Send(iris.SSEEvent{Data: "end"})
+	})
+
+	broker := iris.NewSSEBroker(2)
+	app.Get("/broker", broker.Handler)
+
+	app.ConfigureContainer().Get("/stream", func() iris.EventStream {
+		events := make(chan iris.SSEEvent, 1)
+		events <- iris.SSEEvent{Name: "tick", Data: []byte("1")}
+		close(events)
+		return iris.EventStream{Events: events}
+	})
+
+	e := httptest.New(t, app)
+	resp := e.GET("/").WithHeader("Last-Event-ID", "0").Expect().Status(httptest.StatusOK)
+	resp.ContentType("text/event-stream")
+	resp.Header("Cache-Control").IsEqual("no-cache")
+	resp.Body().IsEqual("retry: 2000\n\n: hello\n\nid: 1\nevent: greet\ndata: first\ndata: second\n\ndata: {\"last\":\"0\"}\n\n")
+
+	body := e.GET("/heartbeat").Expect().Status(httptest.StatusOK).Body()
+	body.HasPrefix(": \n\n")
+	body.HasSuffix(": \n\ndata: end\n\n")
+
+	e.GET("/stream").Expect().Status(httptest.StatusOK).
+		Body().IsEqual("event: tick\ndata: 1\n\n")
+
+	for _, data := range []string{"a", "b", "c"} {
+		broker.Publish(iris.SSEEvent{Data: data})
+	}
+
+	go func() {
+		for broker.Len() == 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		broker.Close()
+	}()
+
+	// "1" is out of the history, "2" is the last received event,
+	// the client resumes from the event "3".
+	e.GET("/broker").WithHeader("Last-Event-ID", "2").Expect().Status(httptest.StatusOK).
+		Body().IsEqual("id: 3\ndata: c\n\n")
+}
@@ -8,6 +8,7 @@ import (
 	"path"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kataras/iris/v12/context"
@@ -47,11 +48,15 @@ func RegisterMethods(newCustomHTTPVerbs ...string) {
 // repository passed to all parties(subrouters), it's the object witch keeps
 // all the routes.
 type repository struct {
+	mu     sync.RWMutex // the routes can be replaced at serve-time, see `RoutesTransaction`.
 	routes []*Route
 	paths  map[string]*Route // only the fullname path part, required at CreateRoutes for registering index page.
 }
 
 func (repo *repository) get(routeName string) *Route {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
 	for _, r := range repo.routes {
 		if r.Name == routeName {
 			return r
@@ -65,14 +70,11 @@ func (repo *repository) getRelative(r *Route) *Route {
 		return nil
 	}
 
-	for _, route := range repo.routes {
-		if r.tmpl.Src == route.tmpl.Src { // No topLink on the same route syntax.
-			// Fixes #2008, because of APIBuilder.handle, repo.getRelative and repo.register replacement but with a toplink of the old route.
-			continue
-		}
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
 
-		if r.Subdomain == route.Subdomain && r.StatusCode == route.StatusCode && r.Method == route.Method &&
-			r.FormattedPath == route.FormattedPath && !route.tmpl.IsTrailing() {
+	for _, route := range repo.routes {
+		if isRelative(r, route) {
 			return route
 		}
 	}
@@ -80,7 +82,22 @@ func (repo *repository) getRelative(r *Route) *Route {
 	return nil
 }
 
+// isRelative reports whether the "route" can handle the path parameters of "r" too,
+// i.e. they have the same path with different types of parameters.
+func isRelative(r, route *Route) bool {
+	if r.tmpl.Src == route.tmpl.Src { // No topLink on the same route syntax.
+		// Fixes #2008, because of APIBuilder.handle, repo.getRelative and repo.register replacement but with a toplink of the old route.
+		return false
+	}
+
+	return r.Subdomain == route.Subdomain && r.StatusCode == route.StatusCode && r.Method == route.Method &&
+		r.FormattedPath == route.FormattedPath && !route.tmpl.IsTrailing()
+}
+
 func (repo *repository) getByPath(tmplPath string) *Route {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
 	if r, ok := repo.paths[tmplPath]; ok {
 		return r
 	}
@@ -89,10 +106,16 @@ func (repo *repository) getByPath(tmplPath string) *Route {
 }
 
 func (repo *repository) getAll() []*Route {
-	return repo.routes
+	repo.mu.RLock()
+	routes := repo.routes
+	repo.mu.RUnlock()
+	return routes
 }
 
 func (repo *repository) remove(routeName string) bool {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
 	for i, r := range repo.routes {
 		if r.Name == routeName {
 			lastIdx := len(repo.routes) - 1
@@ -111,7 +134,80 @@ func (repo *repository) remove(routeName string) bool {
 	return false
 }
 
+// removeFunc removes the routes which "match" and returns their number.
+func (repo *repository) removeFunc(match func(*Route) bool) int {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	routes := make([]*Route, 0, len(repo.routes))
+	for _, r := range repo.routes {
+		if !match(r) {
+			routes = append(routes, r)
+			continue
+		}
+
+		if repo.paths[r.tmpl.Src] == r {
+			delete(repo.paths, r.tmpl.Src)
+		}
+	}
+
+	n := len(repo.routes) - len(routes)
+	repo.routes = routes
+	return n
+}
+
+// clone returns a new repository with copies of the routes, see `RoutesTransaction`.
+func (repo *repository) clone() *repository {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	clones := make(map[*Route]*Route, len(repo.routes))
+	cp := &repository{
+		routes: make([]*Route, 0, len(repo.routes)),
+		paths:  make(map[string]*Route, len(repo.paths)),
+	}
+
+	for _, r := range repo.routes {
+		route := r.clone()
+		clones[r] = route
+		cp.routes = append(cp.routes, route)
+	}
+
+	for _, route := range cp.routes {
+		if route.topLink != nil {
+			route.topLink = clones[route.topLink] // nil if it's removed.
+		}
+	}
+
+	for tmplPath, r := range repo.paths {
+		if route, ok := clones[r]; ok {
+			cp.paths[tmplPath] = route
+		}
+	}
+
+	return cp
+}
+
+// replace replaces the routes with a copy of the "other" repository's ones.
+func (repo *repository) replace(other *repository) {
+	other.mu.RLock()
+	routes := make([]*Route, len(other.routes))
+	copy(routes, other.routes)
+	paths := make(map[string]*Route, len(other.paths))
+	for tmplPath, r := range other.paths {
+		paths[tmplPath] = r
+	}
+	other.mu.RUnlock()
+
+	repo.mu.Lock()
+	repo.routes, repo.paths = routes, paths
+	repo.mu.Unlock()
+}
+
 func (repo *repository) register(route *Route, rule RouteRegisterRule) (*Route, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
 	for i, r := range repo.routes {
 		// 14 August 2019 allow register same path pattern with different macro functions,
 		// see #1058
@@ -1330,7 +1426,7 @@ func (api *APIBuilder) UseOnce(handlers ...context.Handler) {
 // Use of `ctx.Next()` of those handler(s) is necessary to call the main handler or the next middleware.
 // It's always a good practise to call it right before the `Application#Run` function.
 func (api *APIBuilder) UseGlobal(handlers ...context.Handler) {
-	for _, r := range api.routes.routes {
+	for _, r := range api.routes.getAll() {
 		// r.beginHandlers = append(handlers, r.beginHandlers...)
 		// ^ this is correct but we act global begin handlers as one chain, so
 		// if called last more than one time, after all routes registered, we must somehow
@@ -1363,7 +1459,7 @@ func (api *APIBuilder) Done(handlers ...context.Handler) {
 // Use of `ctx.Next()` at the previous handler is necessary.
 // It's always a good practise to call it right before the `Application#Run` function.
 func (api *APIBuilder) DoneGlobal(handlers ...context.Handler) {
-	for _, r := range api.routes.routes {
+	for _, r := range api.routes.getAll() {
 		r.Done(handlers...) // append the handlers to the existing routes
 	}
 	// set as done handlers for the next routes as well.
@@ -1614,7 +1710,7 @@ func (api *APIBuilder) HandleServer(path string, server ServerHandler) {
 	//
 
 	if pathParameterName == "" {
-		pathParameterName = fmt.Sprintf("iris_wildcard_path_parameter%d", len(api.routes.routes))
+		pathParameterName = fmt.Sprintf("iris_wildcard_path_parameter%d", len(api.routes.getAll()))
 		path = fmt.Sprintf("%s/{%s:path}", path, pathParameterName)
 	}
 
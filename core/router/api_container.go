@@ -140,6 +140,7 @@ func fixRouteInfo(route *Route, handlersFn []interface{}) {
 	// Fix main handler name and source modified by execution rules wrapper.
 	route.MainHandlerName, route.MainHandlerIndex = context.MainHandlerName(handlersFn...)
 	if len(handlersFn) > route.MainHandlerIndex {
+		route.MainHandlerFunc = handlersFn[route.MainHandlerIndex]
 		route.SourceFileName, route.SourceLineNumber = context.HandlerFileLineRel(handlersFn[route.MainHandlerIndex])
 	}
 }
@@ -204,6 +204,32 @@ func NewDefaultHandler(config context.ConfigurationReadOnly, logger *golog.Logge
 	return handler
 }
 
+// newRequestHandlerLike returns a new, empty, request handler
+// with the same settings as "h". It reports false for custom request handlers.
+// See `RoutesTransaction`.
+func newRequestHandlerLike(h RequestHandler) (RequestHandler, bool) {
+	switch v := h.(type) {
+	case *routerHandler:
+		return &routerHandler{
+			disablePathCorrection:            v.disablePathCorrection,
+			disablePathCorrectionRedirection: v.disablePathCorrectionRedirection,
+			fireMethodNotAllowed:             v.fireMethodNotAllowed,
+			enablePathIntelligence:           v.enablePathIntelligence,
+			forceLowercaseRouting:            v.forceLowercaseRouting,
+			logger:                           v.logger,
+		}, true
+	case *routerHandlerDynamic:
+		handler, ok := newRequestHandlerLike(v.RequestHandler)
+		if !ok {
+			return nil, false
+		}
+
+		return wrapDynamicHandler(handler), true
+	default:
+		return nil, false
+	}
+}
+
 func (h *routerHandler) getTree(statusCode int, method, subdomain string) *trie {
 	if statusCode > 0 {
 		for i := range h.errorTrees {
@@ -268,6 +294,16 @@ type RoutesProvider interface { // api builder
 }
 
 func defaultErrorHandler(ctx *context.Context) {
+	if ctx.Application().ConfigurationReadOnly().GetEnableProblemDetails() {
+		problem := context.NewProblem()
+		if ok, err := ctx.GetErrPublic(); ok {
+			problem.DetailErr(err)
+		}
+
+		ctx.WriteProblemDetails(ctx.GetStatusCode(), problem)
+		return
+	}
+
 	if ok, err := ctx.GetErrPublic(); ok {
 		// If an error is stored and it's not a private one
 		// write it to the response body.
@@ -409,6 +445,7 @@ func bindMultiParamTypesHandler(r *Route) { // like overlap feature but specific
 	}
 
 	r.topLink.builtinBeginHandlers = append(context.Handlers{decisionHandler}, r.topLink.builtinBeginHandlers...)
+	r.topLink.multiParamHandlers++
 }
 
 func canHandleSubdomain(ctx *context.Context, subdomain string) bool {
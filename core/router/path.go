@@ -3,15 +3,31 @@ package router
 import (
 	"net/http"
 	"path"
+	"reflect"
 	"strconv"
 	"strings"
 
+	"github.com/kataras/iris/v12/context"
 	"github.com/kataras/iris/v12/core/netutil"
 	"github.com/kataras/iris/v12/macro"
 	"github.com/kataras/iris/v12/macro/interpreter/ast"
 	"github.com/kataras/iris/v12/macro/interpreter/lexer"
 )
 
+func init() {
+	// bind the "semver" parameters to the macro.SemanticVersion inputs of the dependency injection handlers.
+	context.ParamResolvers[reflect.TypeOf(macro.SemanticVersion{})] = func(paramIndex int) interface{} {
+		return func(ctx *context.Context) macro.SemanticVersion {
+			if ctx.Params().Len() <= paramIndex {
+				return macro.SemanticVersion{}
+			}
+
+			v, _ := ctx.Params().GetEntryAt(paramIndex).ValueRaw.(macro.SemanticVersion)
+			return v
+		}
+	}
+}
+
 // Param receives a parameter name prefixed with the ParamStart symbol.
 func Param(name string) string {
 	return prefix(name, ParamStart)
@@ -44,6 +44,11 @@ type Route struct {
 	Handlers         context.Handlers `json:"-"`
 	MainHandlerName  string           `json:"mainHandlerName"`
 	MainHandlerIndex int              `json:"mainHandlerIndex"`
+	// MainHandlerFunc is the original main handler function
+	// of a route registered through the dependency injection container,
+	// e.g. func(id uint64) (User, error), nil for common handlers.
+	// It's used to describe the route, see the x/openapi package.
+	MainHandlerFunc interface{} `json:"-"`
 	// temp storage, they're appended to the Handlers on build.
 	// Execution happens after Begin and main Handler(s), can be empty.
 	doneHandlers context.Handlers
@@ -64,6 +69,9 @@ type Route struct {
 	// see APIBuilder.handle, routerHandler.bindMultiParamTypesHandler and routerHandler.Build,
 	// it's the parent route of the last registered of the same path parameter. Specifically for path parameters.
 	topLink *Route
+	// the number of the bindMultiParamTypesHandler's decision handlers
+	// prepended to the Handlers of a top link route on build.
+	multiParamHandlers int
 	// overlappedLink specifically for overlapRoute feature.
 	// keeps the second route of the same path pattern registered.
 	// It's used ONLY for logging.
@@ -270,6 +278,22 @@ func (r *Route) BuildHandlers() {
 	r.doneHandlers = r.doneHandlers[0:0]
 }
 
+// clone returns a copy of the route, without the decision handlers
+// of the routes linked to it, they are added back on the next build.
+// See `RoutesTransaction`.
+func (r *Route) clone() *Route {
+	cp := new(Route)
+	*cp = *r
+
+	cp.Handlers = context.CopyHandlers(r.Handlers[r.multiParamHandlers:])
+	cp.multiParamHandlers = 0
+	cp.beginHandlers = context.CopyHandlers(r.beginHandlers)
+	cp.builtinBeginHandlers = context.CopyHandlers(r.builtinBeginHandlers)
+	cp.doneHandlers = context.CopyHandlers(r.doneHandlers)
+	cp.ReadOnly = routeReadOnlyWrapper{cp}
+	return cp
+}
+
 // String returns the form of METHOD, SUBDOMAIN, TMPL PATH.
 func (r *Route) String() string {
 	start := r.GetTitle()
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kataras/iris/v12/context"
@@ -40,6 +41,45 @@ type Router struct {
 	// key = subdomain
 	// value = closest of static routes, filled on `BuildRouter/RefreshRouter`.
 	closestPaths map[string]*closestmatch.ClosestMatch
+
+	// the timeout handler settings, see `SetTimeoutHandler`,
+	// re-applied on `RoutesTransaction.Commit`.
+	timeout        time.Duration
+	timeoutMessage string
+
+	// served holds the handlers which serve the requests,
+	// stored on build and swapped on `RoutesTransaction.Commit`
+	// while requests are in flight.
+	served atomic.Pointer[servedRouter]
+	// txMu serializes the transactions commits and
+	// version is increased on each commit to detect conflicts.
+	txMu    sync.Mutex
+	version uint64
+}
+
+// servedRouter is the state of a built router.
+type servedRouter struct {
+	requestHandler RequestHandler
+	mainHandler    http.HandlerFunc
+	closestPaths   map[string]*closestmatch.ClosestMatch
+}
+
+// serve stores the current handlers as the ones which serve the requests.
+func (router *Router) serve() {
+	router.served.Store(&servedRouter{
+		requestHandler: router.requestHandler,
+		mainHandler:    router.mainHandler,
+		closestPaths:   router.closestPaths,
+	})
+}
+
+// currentRequestHandler returns the request handler which serves the requests.
+func (router *Router) currentRequestHandler() RequestHandler {
+	if served := router.served.Load(); served != nil {
+		return served.requestHandler
+	}
+
+	return router.requestHandler
 }
 
 // NewRouter returns a new empty Router.
@@ -53,6 +93,9 @@ func NewRouter() *Router {
 // Note that in order to use RefreshRouter while in serve-time,
 // you have to set the `EnableDynamicHandler` Iris Application setting to true,
 // e.g. `app.Listen(":8080", iris.WithEnableDynamicHandler)`
+//
+// To add, remove or replace routes while serving
+// prefer the `RoutesTransaction` (see `Application.BeginRoutes`) instead.
 func (router *Router) RefreshRouter() error {
 	return router.BuildRouter(router.cPool, router.requestHandler, router.routesProvider, true)
 }
@@ -77,11 +120,16 @@ func (router *Router) AddRouteUnsafe(routes ...*Route) error {
 //
 // Order may change.
 func (router *Router) FindClosestPaths(subdomain, searchPath string, n int) []string {
-	if router.closestPaths == nil {
+	closestPaths := router.closestPaths
+	if served := router.served.Load(); served != nil {
+		closestPaths = served.closestPaths
+	}
+
+	if closestPaths == nil {
 		return nil
 	}
 
-	cm, ok := router.closestPaths[subdomain]
+	cm, ok := closestPaths[subdomain]
 	if !ok {
 		return nil
 	}
@@ -95,27 +143,41 @@ func (router *Router) FindClosestPaths(subdomain, searchPath string, n int) []st
 	return list
 }
 
-func (router *Router) buildMainHandler(cPool *context.Pool, requestHandler RequestHandler) {
-	router.mainHandler = func(w http.ResponseWriter, r *http.Request) {
+// newMainHandler returns the main handler which serves the requests through the "requestHandler",
+// the "routerFilters" run before it.
+func newMainHandler(routerFilters map[Party]*Filter, cPool *context.Pool, requestHandler RequestHandler) http.HandlerFunc {
+	if len(routerFilters) > 0 {
+		return newMainHandlerWithFilters(routerFilters, cPool, requestHandler)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := cPool.Acquire(w, r)
-		router.requestHandler.HandleRequest(ctx)
+		requestHandler.HandleRequest(ctx)
 		cPool.Release(ctx)
 	}
 }
 
-func (router *Router) buildMainHandlerWithFilters(routerFilters map[Party]*Filter, cPool *context.Pool, requestHandler RequestHandler) {
+func newMainHandlerWithFilters(routerFilters map[Party]*Filter, cPool *context.Pool, requestHandler RequestHandler) http.HandlerFunc {
 	sortedFilters := make([]*Filter, 0, len(routerFilters))
 	// key was just there to enforce uniqueness on API level.
 	for _, f := range routerFilters {
+		// copy it, so a re-build does not append the request handler twice.
+		f = &Filter{
+			Matcher:   f.Matcher,
+			Skippers:  f.Skippers,
+			Subdomain: f.Subdomain,
+			Path:      f.Path,
+			// append it as one handlers so execution rules are being respected in that step too.
+			Handlers: append(f.Handlers[0:len(f.Handlers):len(f.Handlers)], func(ctx *context.Context) {
+				// set the handler index back to 0 so the route's handlers can be executed as expected.
+				ctx.HandlerIndex(0)
+				// execute the main request handler, this will fire the found route's handlers
+				// or if error the error code's associated handler.
+				requestHandler.HandleRequest(ctx)
+			}),
+		}
+
 		sortedFilters = append(sortedFilters, f)
-		// append it as one handlers so execution rules are being respected in that step too.
-		f.Handlers = append(f.Handlers, func(ctx *context.Context) {
-			// set the handler index back to 0 so the route's handlers can be executed as expected.
-			ctx.HandlerIndex(0)
-			// execute the main request handler, this will fire the found route's handlers
-			// or if error the error code's associated handler.
-			router.requestHandler.HandleRequest(ctx)
-		})
 	}
 
 	sort.SliceStable(sortedFilters, func(i, j int) bool {
@@ -149,7 +211,7 @@ func (router *Router) buildMainHandlerWithFilters(routerFilters map[Party]*Filte
 		return len(left.Path) > len(right.Path)
 	})
 
-	router.mainHandler = func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := cPool.Acquire(w, r)
 
 		filterExecuted := false
@@ -167,7 +229,7 @@ func (router *Router) buildMainHandlerWithFilters(routerFilters map[Party]*Filte
 		if !filterExecuted {
 			// If not at least one match filter found and executed,
 			// then just run the router.
-			router.requestHandler.HandleRequest(ctx)
+			requestHandler.HandleRequest(ctx)
 		}
 
 		cPool.Release(ctx)
@@ -218,11 +280,7 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 	}
 
 	// the important stuff.
-	if routerFilters := routesProvider.GetRouterFilters(); len(routerFilters) > 0 {
-		router.buildMainHandlerWithFilters(routerFilters, cPool, requestHandler)
-	} else {
-		router.buildMainHandler(cPool, requestHandler)
-	}
+	router.mainHandler = newMainHandler(routesProvider.GetRouterFilters(), cPool, router.requestHandler)
 
 	for i := len(router.wrapperFuncs) - 1; i >= 0; i-- {
 		w := router.wrapperFuncs[i]
@@ -236,9 +294,15 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 		router.mainHandler = newWrapper(router.wrapperFunc, router.mainHandler).ServeHTTP
 	}
 
-	// build closest.
+	router.closestPaths = newClosestPaths(router.routesProvider.GetRoutes())
+	router.serve()
+	return nil
+}
+
+// newClosestPaths returns the closest matchers of the static routes paths per subdomain.
+func newClosestPaths(routes []*Route) map[string]*closestmatch.ClosestMatch {
 	subdomainPaths := make(map[string][]string)
-	for _, r := range router.routesProvider.GetRoutes() {
+	for _, r := range routes {
 		if !r.IsStatic() {
 			continue
 		}
@@ -246,12 +310,12 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 		subdomainPaths[r.Subdomain] = append(subdomainPaths[r.Subdomain], r.Path)
 	}
 
-	router.closestPaths = make(map[string]*closestmatch.ClosestMatch)
+	closestPaths := make(map[string]*closestmatch.ClosestMatch)
 	for subdomain, paths := range subdomainPaths {
-		router.closestPaths[subdomain] = closestmatch.New(paths, []int{3, 4, 6})
+		closestPaths[subdomain] = closestmatch.New(paths, []int{3, 4, 6})
 	}
 
-	return nil
+	return closestPaths
 }
 
 // Downgrade "downgrades", alters the router supervisor service(Router.mainHandler)
@@ -266,6 +330,7 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 func (router *Router) Downgrade(newMainHandler http.HandlerFunc) {
 	router.mu.Lock()
 	router.mainHandler = newMainHandler
+	router.serve()
 	router.mu.Unlock()
 }
 
@@ -285,12 +350,24 @@ func (router *Router) SetTimeoutHandler(timeout time.Duration, msg string) {
 		return
 	}
 
-	mainHandler := router.mainHandler
+	router.mu.Lock()
+	router.timeout, router.timeoutMessage = timeout, msg
+	router.mainHandler = router.withTimeout(router.mainHandler)
+	router.serve()
+	router.mu.Unlock()
+}
+
+// withTimeout wraps the "mainHandler" with the timeout handler, if any.
+func (router *Router) withTimeout(mainHandler http.HandlerFunc) http.HandlerFunc {
+	if router.timeout <= 0 {
+		return mainHandler
+	}
+
 	h := func(w http.ResponseWriter, r *http.Request) {
 		mainHandler(w, r)
 	}
 
-	router.mainHandler = http.TimeoutHandler(http.HandlerFunc(h), timeout, msg).ServeHTTP
+	return http.TimeoutHandler(http.HandlerFunc(h), router.timeout, router.timeoutMessage).ServeHTTP
 }
 
 // WrapRouter adds a wrapper on the top of the main router.
@@ -334,15 +411,35 @@ func (router *Router) PrependRouterWrapper(wrapperFunc WrapperFunc) {
 
 // ServeHTTPC serves the raw context, useful if we have already a context, it by-pass the wrapper.
 func (router *Router) ServeHTTPC(ctx *context.Context) {
-	router.requestHandler.HandleRequest(ctx)
+	router.currentRequestHandler().HandleRequest(ctx)
 }
 
 func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if served := router.served.Load(); served != nil {
+		served.mainHandler(w, r)
+		return
+	}
+
 	router.mainHandler(w, r)
 }
 
+// ErrorHandler returns an HTTPErrorHandler which fires the error handlers
+// through the request handler that serves the requests,
+// so it keeps working after a `RoutesTransaction.Commit`.
+func (router *Router) ErrorHandler() HTTPErrorHandler {
+	return servedErrorHandler{router}
+}
+
+type servedErrorHandler struct {
+	router *Router
+}
+
+func (h servedErrorHandler) FireErrorCode(ctx *context.Context) {
+	h.router.currentRequestHandler().FireErrorCode(ctx)
+}
+
 // RouteExists reports whether a particular route exists
 // It will search from the current subdomain of context's host, if not inside the root domain.
 func (router *Router) RouteExists(ctx *context.Context, method, path string) bool {
-	return router.requestHandler.RouteExists(ctx, method, path)
+	return router.currentRequestHandler().RouteExists(ctx, method, path)
 }
@@ -0,0 +1,297 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/errgroup"
+
+	"github.com/kataras/golog"
+)
+
+var (
+	// ErrRoutesTransactionDone is returned by the `RoutesTransaction` Commit and Rollback
+	// methods when the transaction has already been committed or rolled back.
+	ErrRoutesTransactionDone = errors.New("routes transaction has already been committed or rolled back")
+	// ErrRoutesTransactionConflict is returned by the `RoutesTransaction.Commit` method
+	// when another transaction was committed after this one began.
+	ErrRoutesTransactionConflict = errors.New("routes transaction conflicts with a transaction committed after it began")
+	// ErrRouterNotBuilt is returned by the `RoutesTransaction.Commit` method
+	// when the router is not built yet, register the routes directly instead.
+	ErrRouterNotBuilt = errors.New("router is not built yet")
+	// ErrRoutesTransactionNotSupported is returned by the `RoutesTransaction.Commit` method
+	// when the router is downgraded or it uses a custom request handler.
+	ErrRoutesTransactionNotSupported = errors.New("routes transactions are not supported by the router's request handler")
+)
+
+// RoutesTransaction builds a new set of routes, starting from a copy of the registered ones,
+// and swaps it in atomically, while requests are in flight, on `Commit`.
+// The requests are served by the previous routes until then.
+//
+// Routes are added or replaced through its Party methods (e.g. Get, Party, PartyFunc),
+// which act like the root Party ones, and removed through its `RemoveRoute`
+// and `RemoveParty` methods. Route registration errors (e.g. a path parameter
+// of an unknown type) and build errors make the `Commit` fail,
+// and the previous routes keep serving, nothing is applied.
+//
+// The Party middleware registered on the transaction (e.g. Use, UseGlobal) apply
+// to its routes only, the router filters (UseRouter) are committed with the routes.
+//
+// The transaction starts from copies of the registered routes, so after a `Commit`
+// the previously returned *Route values are no longer the served ones.
+// Code which keeps details per route should identify a route by its method,
+// subdomain and path instead, as the x/openapi Generator does.
+//
+// Use the `Application.BeginRoutes` method to create a new RoutesTransaction.
+//
+// Example Code:
+//
+//	tx := app.BeginRoutes()
+//	tx.RemoveParty("/tenants/acme")
+//	tx.PartyFunc("/tenants/acme", func(tenant iris.Party) {
+//		tenant.Get("/", tenantIndex)
+//	})
+//
+//	if err := tx.Commit(); err != nil {
+//		// the previous routes are still served.
+//	}
+type RoutesTransaction struct {
+	// Party is the root Party of the new routes.
+	Party
+
+	router  *Router
+	api     *APIBuilder
+	staging *APIBuilder
+	version uint64
+
+	mu   sync.Mutex
+	done bool
+
+	errMu  sync.Mutex
+	errors *errgroup.Group
+}
+
+// NewRoutesTransaction returns a new RoutesTransaction for the routes of the "api"
+// root Party which are served by the "router".
+// See `Application.BeginRoutes` too.
+func NewRoutesTransaction(router *Router, api *APIBuilder) *RoutesTransaction {
+	router.txMu.Lock()
+	version := router.version
+	router.txMu.Unlock()
+
+	tx := &RoutesTransaction{
+		router:  router,
+		api:     api,
+		version: version,
+		errors:  errgroup.New("Routes Transaction"),
+	}
+
+	tx.staging = newStagingAPIBuilder(api, tx.newLogger())
+	tx.Party = tx.staging
+	return tx
+}
+
+// newLogger returns a copy of the application's logger
+// which collects the route registration errors.
+func (tx *RoutesTransaction) newLogger() *golog.Logger {
+	logger := tx.api.logger.Clone()
+
+	print := logger.Level >= golog.ErrorLevel
+	if !print {
+		logger.Level = golog.ErrorLevel
+	}
+
+	logger.Handle(func(l *golog.Log) bool {
+		if l.Level == golog.ErrorLevel {
+			tx.errMu.Lock()
+			tx.errors.Add(errors.New(l.Message))
+			tx.errMu.Unlock()
+		}
+
+		return !print
+	})
+
+	return logger
+}
+
+// newStagingAPIBuilder returns a copy of the "api" root Party
+// with a copy of its routes and router filters.
+func newStagingAPIBuilder(api *APIBuilder, logger *golog.Logger) *APIBuilder {
+	staging := new(APIBuilder)
+	*staging = *api
+
+	staging.logger = logger
+	staging.routes = api.routes.clone()
+	staging.apiBuilderDI = &APIContainer{Container: api.apiBuilderDI.Container.Clone()}
+
+	staging.properties = make(context.Map, len(api.properties))
+	for k, v := range api.properties {
+		staging.properties[k] = v
+	}
+
+	// the next appends should not modify the original ones.
+	staging.middleware = api.middleware[0:len(api.middleware):len(api.middleware)]
+	staging.middlewareErrorCode = api.middlewareErrorCode[0:len(api.middlewareErrorCode):len(api.middlewareErrorCode)]
+	staging.beginGlobalHandlers = api.beginGlobalHandlers[0:len(api.beginGlobalHandlers):len(api.beginGlobalHandlers)]
+	staging.doneHandlers = api.doneHandlers[0:len(api.doneHandlers):len(api.doneHandlers)]
+	staging.doneGlobalHandlers = api.doneGlobalHandlers[0:len(api.doneGlobalHandlers):len(api.doneGlobalHandlers)]
+	staging.allowMethods = api.allowMethods[0:len(api.allowMethods):len(api.allowMethods)]
+	staging.routerFilterHandlers = api.routerFilterHandlers[0:len(api.routerFilterHandlers):len(api.routerFilterHandlers)]
+	staging.routerFilters = copyRouterFilters(api.routerFilters, api, staging)
+
+	return staging
+}
+
+// copyRouterFilters returns a copy of the "filters",
+// the "from" Party's filter is moved to the "to" Party.
+func copyRouterFilters(filters map[Party]*Filter, from, to *APIBuilder) map[Party]*Filter {
+	cp := make(map[Party]*Filter, len(filters))
+	for p, f := range filters {
+		filter := *f
+		if p == Party(from) {
+			p = to
+			filter.Matcher = to
+		}
+
+		cp[p] = &filter
+	}
+
+	return cp
+}
+
+// RemoveParty removes the routes, including the error handlers,
+// of the Party of the "relativePath" and its children Parties.
+// Returns the number of the removed routes.
+func (tx *RoutesTransaction) RemoveParty(relativePath string) int {
+	subdomain, path := splitSubdomainAndPath(tx.staging.Party(relativePath).GetRelPath())
+	path = strings.TrimSuffix(path, "/")
+
+	return tx.staging.routes.removeFunc(func(r *Route) bool {
+		if r.Subdomain != subdomain {
+			return false
+		}
+
+		src := r.tmpl.Src
+		return path == "" || src == path || strings.HasPrefix(src, path+"/")
+	})
+}
+
+// ReplaceParty removes the routes of the Party of the "relativePath", see `RemoveParty`,
+// and registers its new routes through the "partyBuilderFunc".
+func (tx *RoutesTransaction) ReplaceParty(relativePath string, partyBuilderFunc func(p Party)) Party {
+	tx.RemoveParty(relativePath)
+	return tx.staging.PartyFunc(relativePath, partyBuilderFunc)
+}
+
+// Commit builds the new routes and swaps them in atomically,
+// the next requests are served by them.
+// On failure the previous routes are kept, nothing is applied.
+func (tx *RoutesTransaction) Commit() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrRoutesTransactionDone
+	}
+	tx.done = true
+
+	tx.errMu.Lock()
+	err := errgroup.Check(tx.errors)
+	tx.errMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	router := tx.router
+	router.txMu.Lock()
+	defer router.txMu.Unlock()
+
+	if router.version != tx.version {
+		return ErrRoutesTransactionConflict
+	}
+
+	served := router.served.Load()
+	if served == nil {
+		return ErrRouterNotBuilt
+	}
+
+	requestHandler, ok := newRequestHandlerLike(served.requestHandler)
+	if !ok {
+		return ErrRoutesTransactionNotSupported
+	}
+
+	routes := tx.staging.GetRoutes()
+	relinkRoutes(routes)
+
+	if err = requestHandler.Build(tx.staging); err != nil {
+		return err
+	}
+
+	// The new routes are ready, swap them.
+	tx.api.routes.replace(tx.staging.routes)
+	committedFilters := copyRouterFilters(tx.staging.routerFilters, tx.staging, tx.api)
+	for p := range tx.api.routerFilters {
+		delete(tx.api.routerFilters, p)
+	}
+	for p, f := range committedFilters {
+		tx.api.routerFilters[p] = f
+	}
+	tx.api.routerFilterHandlers = tx.staging.routerFilterHandlers
+
+	router.mu.Lock()
+	mainHandler := newMainHandler(tx.api.routerFilters, router.cPool, requestHandler)
+	if router.wrapperFunc != nil {
+		mainHandler = newWrapper(router.wrapperFunc, mainHandler).ServeHTTP
+	}
+
+	router.requestHandler = requestHandler
+	router.mainHandler = router.withTimeout(mainHandler)
+	router.closestPaths = newClosestPaths(routes)
+	router.serve()
+	router.mu.Unlock()
+
+	router.version++
+	return nil
+}
+
+// Rollback discards the transaction's routes.
+func (tx *RoutesTransaction) Rollback() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrRoutesTransactionDone
+	}
+
+	tx.done = true
+	return nil
+}
+
+// relinkRoutes links the routes which were linked to a removed route,
+// see `repository.getRelative`, to their first relative route left, if any.
+func relinkRoutes(routes []*Route) {
+	set := make(map[*Route]struct{}, len(routes))
+	for _, r := range routes {
+		set[r] = struct{}{}
+	}
+
+	for i, r := range routes {
+		if r.topLink == nil {
+			continue
+		}
+
+		if _, ok := set[r.topLink]; ok {
+			continue
+		}
+
+		r.topLink = nil
+		for _, route := range routes[:i] {
+			if isRelative(r, route) {
+				r.topLink = route
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package router_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+	irisHttptest "github.com/kataras/iris/v12/httptest"
+)
+
+func TestRoutesTransaction(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("index")
+	})
+	app.PartyFunc("/tenants/acme", func(acme iris.Party) {
+		acme.Get("/", func(ctx iris.Context) {
+			ctx.WriteString("acme")
+		})
+		acme.Get("/users/{id:uint64}", func(ctx iris.Context) {
+			ctx.Writef("acme user %d", ctx.Params().GetUint64Default("id", 0))
+		})
+		acme.Get("/users/{name:string}", func(ctx iris.Context) {
+			ctx.Writef("acme user %s", ctx.Params().Get("name"))
+		})
+	})
+	app.Get("/tenants/globex", func(ctx iris.Context) {
+		ctx.WriteString("globex")
+	})
+	app.Get("/items/{id:uint64}", func(ctx iris.Context) {
+		ctx.WriteString("item by id")
+	})
+	app.Get("/items/{name:string}", func(ctx iris.Context) {
+		ctx.WriteString("item by name")
+	})
+
+	e := irisHttptest.New(t, app, irisHttptest.LogLevel("disable"))
+	e.GET("/tenants/acme/users/42").Expect().Status(irisHttptest.StatusOK).Body().IsEqual("acme user 42")
+
+	// Add, remove and replace routes and parties.
+	tx := app.BeginRoutes()
+	tx.Get("/health", func(ctx iris.Context) {
+		ctx.WriteString("OK")
+	})
+	if n := tx.RemoveParty("/tenants/globex"); n != 1 {
+		t.Fatalf("expected 1 removed route but got: %d", n)
+	}
+	tx.ReplaceParty("/tenants/acme", func(acme iris.Party) {
+		acme.Get("/", func(ctx iris.Context) {
+			ctx.WriteString("acme v2")
+		})
+		acme.Get("/users/{name:string}", func(ctx iris.Context) {
+			ctx.Writef("acme v2 user %s", ctx.Params().Get("name"))
+		})
+	})
+
+	// Not applied before commit.
+	e.GET("/health").Expect().Status(irisHttptest.StatusNotFound)
+	e.GET("/tenants/acme").Expect().Status(irisHttptest.StatusOK).Body().IsEqual("acme")
+
+	// The routes of the same path with different parameter types are re-linked.
+	tx.RemoveRoute("GET/items/{id:uint64}")
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	e.GET("/items/42").Expect().Status(irisHttptest.StatusOK).Body().IsEqual("item by name")
+	e.GET("/").Expect().Status(irisHttptest.StatusOK).Body().IsEqual("index")
+	e.GET("/health").Expect().Status(irisHttptest.StatusOK).Body().IsEqual("OK")
+	e.GET("/tenants/globex").Expect().Status(irisHttptest.StatusNotFound)
+	e.GET("/tenants/acme").Expect().Status(irisHttptest.StatusOK).Body().IsEqual("acme v2")
+	e.GET("/tenants/acme/users/42").Expect().Status(irisHttptest.StatusOK).Body().IsEqual("acme v2 user 42")
+
+	if app.GetRoute("GET/health") == nil || app.GetRoute("GET/tenants/globex") != nil {
+		t.Fatalf("expected the committed routes to be registered")
+	}
+
+	if err := tx.Commit(); !errors.Is(err, router.ErrRoutesTransactionDone) {
+		t.Fatalf("expected error: %v but got: %v", router.ErrRoutesTransactionDone, err)
+	}
+
+	// Rollback on registration errors.
+	tx = app.BeginRoutes()
+	tx.RemoveRoute("GET/health")
+	tx.Get("/users/{id:unknown}", func(ctx iris.Context) {})
+	if err := tx.Commit(); err == nil {
+		t.Fatalf("expected a commit error")
+	}
+	e.GET("/health").Expect().Status(irisHttptest.StatusOK)
+
+	// Conflicts.
+	tx1, tx2 := app.BeginRoutes(), app.BeginRoutes()
+	tx1.Get("/one", func(ctx iris.Context) {})
+	tx2.Get("/two", func(ctx iris.Context) {})
+	if err := tx1.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx2.Commit(); !errors.Is(err, router.ErrRoutesTransactionConflict) {
+		t.Fatalf("expected error: %v but got: %v", router.ErrRoutesTransactionConflict, err)
+	}
+	e.GET("/one").Expect().Status(irisHttptest.StatusOK)
+	e.GET("/two").Expect().Status(irisHttptest.StatusNotFound)
+
+	tx = app.BeginRoutes()
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); !errors.Is(err, router.ErrRoutesTransactionDone) {
+		t.Fatalf("expected error: %v but got: %v", router.ErrRoutesTransactionDone, err)
+	}
+}
+
+func TestRoutesTransactionInFlight(t *testing.T) {
+	app := iris.New()
+	app.Logger().SetLevel("disable")
+	app.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("index")
+	})
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				rec := httptest.NewRecorder()
+				app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+				if rec.Code != http.StatusOK || rec.Body.String() != "index" {
+					t.Errorf("unexpected response: %d: %s", rec.Code, rec.Body.String())
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		tx := app.BeginRoutes()
+		if i%2 == 0 {
+			tx.Get("/tenant", func(ctx iris.Context) {})
+		} else {
+			tx.RemoveRoute("GET/tenant")
+		}
+
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
@@ -8,8 +8,12 @@ import (
 
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/middleware/recover"
+	"github.com/kataras/iris/v12/x/errors"
 
 	"github.com/kataras/iris/v12/httptest"
+
+	"github.com/iris-contrib/httpexpect/v2"
 )
 
 var defaultErrHandler = func(ctx *context.Context) {
@@ -158,3 +162,40 @@ func TestPartyOnErrorCode(t *testing.T) {
 	e.GET("/users/badrequest").Expect().Status(iris.StatusBadRequest).
 		Body().IsEqual(http.StatusText(iris.StatusBadRequest))
 }
+
+func TestProblemDetails(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithProblemDetails)
+	app.Use(recover.New())
+
+	app.Get("/public", func(ctx iris.Context) {
+		ctx.StopWithError(iris.StatusBadRequest, errors.New("missing id"))
+	})
+	app.Get("/panic", func(ctx iris.Context) {
+		panic("secret")
+	})
+	app.Get("/wire", func(ctx iris.Context) {
+		errors.NotFound.Details(ctx, "user not found", "user with id: %d was not found", 42)
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/notfound").Expect().Status(iris.StatusNotFound).
+		ContentType(context.ContentJSONProblemHeaderValue).
+		JSON(httpexpect.ContentOpts{MediaType: context.ContentJSONProblemHeaderValue}).IsEqual(iris.Map{"title": "Not Found", "status": iris.StatusNotFound})
+	e.GET("/notfound").WithHeader("Accept", "application/xml").Expect().Status(iris.StatusNotFound).
+		ContentType(context.ContentXMLProblemHeaderValue).
+		Body().Contains("<Title>Not Found</Title>")
+	e.GET("/public").Expect().Status(iris.StatusBadRequest).
+		JSON(httpexpect.ContentOpts{MediaType: context.ContentJSONProblemHeaderValue}).IsEqual(iris.Map{"title": "Bad Request", "status": iris.StatusBadRequest, "detail": "missing id"})
+	e.GET("/panic").Expect().Status(iris.StatusInternalServerError).
+		JSON(httpexpect.ContentOpts{MediaType: context.ContentJSONProblemHeaderValue}).IsEqual(iris.Map{"title": "Internal Server Error", "status": iris.StatusInternalServerError})
+	e.GET("/wire").Expect().Status(iris.StatusNotFound).
+		ContentType(context.ContentJSONProblemHeaderValue).
+		JSON(httpexpect.ContentOpts{MediaType: context.ContentJSONProblemHeaderValue}).IsEqual(iris.Map{
+		"title":  "user not found",
+		"detail": "user with id: 42 was not found",
+		"status": iris.StatusNotFound,
+		"code":   "NOT_FOUND",
+	})
+}
@@ -77,7 +77,11 @@ var (
 				ctx.StatusCode(DefaultErrStatusCode)
 			}
 
-			_, _ = ctx.WriteString(err.Error())
+			if ctx.Application().ConfigurationReadOnly().GetEnableProblemDetails() {
+				_ = ctx.WriteProblemDetails(ctx.GetStatusCode(), context.NewProblem().DetailErr(err))
+			} else {
+				_, _ = ctx.WriteString(err.Error())
+			}
 		}
 
 		ctx.StopExecution()
@@ -2,11 +2,14 @@ package hero_test
 
 import (
 	"fmt"
+	"net/netip"
 	"testing"
+	"time"
 
 	"github.com/kataras/iris/v12"
 	. "github.com/kataras/iris/v12/hero"
 	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/macro"
 )
 
 // dynamic func
@@ -266,6 +269,39 @@ func TestHandlerPathParams(t *testing.T) {
 	}
 }
 
+func TestHandlerPathParamsMacros(t *testing.T) {
+	app := iris.New()
+	api := app.ConfigureContainer()
+	api.Get("/versions/{v:semver}", func(v macro.SemanticVersion) string {
+		return fmt.Sprintf("%d-%d-%d", v.Major, v.Minor, v.Patch)
+	})
+	api.Get("/hosts/{ip:ipv4}", func(ip netip.Addr) string {
+		return ip.Next().String()
+	})
+	api.Get("/blobs/{data:hex}", func(data []byte) string {
+		return string(data)
+	})
+	api.Get("/ttl/{d:duration}", func(d time.Duration) string {
+		return fmt.Sprintf("%d", d.Milliseconds())
+	})
+	api.Get("/events/{at:iso8601}", func(at time.Time) string {
+		return fmt.Sprintf("%d", at.Year())
+	})
+	api.Get("/colors/{color:enum(red,green)}", func(color string) string {
+		return color
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/versions/v1.2.3").Expect().Status(httptest.StatusOK).Body().IsEqual("1-2-3")
+	e.GET("/hosts/10.0.0.1").Expect().Status(httptest.StatusOK).Body().IsEqual("10.0.0.2")
+	e.GET("/hosts/::1").Expect().Status(httptest.StatusNotFound)
+	e.GET("/blobs/69726973").Expect().Status(httptest.StatusOK).Body().IsEqual("iris")
+	e.GET("/ttl/1m30s").Expect().Status(httptest.StatusOK).Body().IsEqual("90000")
+	e.GET("/events/2024-04-25T10:00:00Z").Expect().Status(httptest.StatusOK).Body().IsEqual("2024")
+	e.GET("/colors/green").Expect().Status(httptest.StatusOK).Body().IsEqual("green")
+	e.GET("/colors/blue").Expect().Status(httptest.StatusNotFound)
+}
+
 func TestRegisterDependenciesFromContext(t *testing.T) {
 	// Tests serve-time struct dependencies through a common Iris middleware.
 	app := iris.New()
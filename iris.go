@@ -257,6 +257,25 @@ func (app *Application) SubdomainRedirect(from, to router.Party) router.Party {
 	return to
 }
 
+// BeginRoutes starts a transaction which builds a new set of routes,
+// starting from a copy of the registered ones, and swaps it in atomically on its `Commit`,
+// while the server is running and requests are in flight.
+// On build errors nothing is applied and the previous routes keep serving.
+//
+// Usage:
+//
+//	tx := app.BeginRoutes()
+//	tx.ReplaceParty("/tenants/acme", func(tenant iris.Party) {
+//		tenant.Get("/", tenantIndex)
+//	})
+//	tx.RemoveParty("/tenants/globex")
+//	err := tx.Commit()
+//
+// See `core/router#RoutesTransaction` for more.
+func (app *Application) BeginRoutes() *router.RoutesTransaction {
+	return router.NewRoutesTransaction(app.Router, app.APIBuilder)
+}
+
 // Configure can called when modifications to the framework instance needed.
 // It accepts the framework instance
 // and returns an error which if it's not nil it's printed to the logger.
@@ -766,7 +785,7 @@ func (app *Application) Build() error {
 		if err != nil {
 			return fmt.Errorf("build: router: %w", err)
 		}
-		app.HTTPErrorHandler = routerHandler
+		app.HTTPErrorHandler = app.Router.ErrorHandler()
 
 		if app.config.Timeout > 0 {
 			app.Router.SetTimeoutHandler(app.config.Timeout, app.config.TimeoutMessage)
@@ -0,0 +1,220 @@
+package macro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrParamConstraint is fired when the parameter value
+// does not satisfy the constraints of its defined parameter type, see `Definition`.
+var ErrParamConstraint = errors.New("parameter does not satisfy its type constraints")
+
+// Definition describes a parameter type which is based on a registered one,
+// e.g. "string" or "int", plus its constraints.
+// The definitions can be declared in a YAML or JSON file, so route contracts can be shared,
+// see the `Macros.Load` and `Macros.Define` methods.
+//
+// The YAML and JSON files share the same keys.
+//
+// Example YAML:
+//
+//   - name: username
+//     regexp: ^[a-z0-9_]+$
+//     minLength: 3
+//     maxLength: 20
+//   - name: page
+//     type: int
+//     min: 1
+//     max: 1000
+//   - name: color
+//     enum: [red, green, blue]
+//
+// Usage:
+// {name:username}, {page:page} and {color:color}.
+type Definition struct {
+	// Name is the name of the new parameter type.
+	Name string `json:"name" yaml:"name"`
+	// Alias is the optional alias of the new parameter type.
+	Alias string `json:"alias,omitempty" yaml:"alias,omitempty"`
+	// Type is the name of the base parameter type,
+	// its value type and functions are inherited.
+	// Defaults to "string".
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Regexp is a regular expression which the parameter value should match.
+	Regexp string `json:"regexp,omitempty" yaml:"regexp,omitempty"`
+	// Enum is the list of the allowed parameter values.
+	Enum []string `json:"enum,omitempty" yaml:"enum,omitempty"`
+	// MinLength and MaxLength limit the characters of the parameter value.
+	MinLength *int `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength *int `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	// Min and Max limit the value of a numeric base type, e.g. "int".
+	Min *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// Load reads the parameter type definitions of a YAML (.yml or .yaml) or JSON file
+// and registers them. See `Define` too.
+func (ms *Macros) Load(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var definitions []Definition
+	if ext := strings.ToLower(filepath.Ext(filename)); ext == ".yml" || ext == ".yaml" {
+		err = yaml.Unmarshal(data, &definitions)
+	} else {
+		err = json.Unmarshal(data, &definitions)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return ms.Define(definitions...)
+}
+
+// Define registers new parameter types based on their definitions.
+// It returns the first error of an invalid definition,
+// the definitions before that are registered.
+func (ms *Macros) Define(definitions ...Definition) error {
+	for _, def := range definitions {
+		m, err := ms.newDefinedMacro(def)
+		if err != nil {
+			return err
+		}
+
+		if !ms.register(m) {
+			return fmt.Errorf("macro: %s: parameter type or alias is already registered", def.Name)
+		}
+	}
+
+	return nil
+}
+
+func (ms *Macros) newDefinedMacro(def Definition) (*Macro, error) {
+	if def.Name == "" {
+		return nil, errors.New("macro: definition: name is missing")
+	}
+
+	if def.Type == "" {
+		def.Type = String.Indent()
+	}
+
+	base := ms.Get(def.Type)
+	if base == nil {
+		return nil, fmt.Errorf("macro: %s: unknown base parameter type: %s", def.Name, def.Type)
+	}
+
+	var match func(string) bool
+	if def.Regexp != "" {
+		var err error
+		if match, err = Regexp(def.Regexp); err != nil {
+			return nil, fmt.Errorf("macro: %s: %w", def.Name, err)
+		}
+	}
+
+	if (def.Min != nil || def.Max != nil) && !isNumeric(base.GoType()) {
+		return nil, fmt.Errorf("macro: %s: min and max require a numeric base parameter type but got: %s", def.Name, def.Type)
+	}
+
+	baseEvaluator := base.Evaluator
+	evaluator := func(paramValue string) (interface{}, bool) {
+		if n := utf8.RuneCountInString(paramValue); def.MinLength != nil && n < *def.MinLength {
+			return fmt.Errorf("%s: %w: length must be at least %d", paramValue, ErrParamConstraint, *def.MinLength), false
+		} else if def.MaxLength != nil && n > *def.MaxLength {
+			return fmt.Errorf("%s: %w: length must be at most %d", paramValue, ErrParamConstraint, *def.MaxLength), false
+		}
+
+		if match != nil && !match(paramValue) {
+			return fmt.Errorf("%s: %w: must match %s", paramValue, ErrParamConstraint, def.Regexp), false
+		}
+
+		if len(def.Enum) > 0 && !isOneOf(paramValue, def.Enum) {
+			return fmt.Errorf("%s: %w: must be one of %v", paramValue, ErrParamConstraint, def.Enum), false
+		}
+
+		var value interface{} = paramValue
+		if baseEvaluator != nil {
+			v, ok := baseEvaluator(paramValue)
+			if !ok {
+				return v, false
+			}
+
+			value = v
+		}
+
+		if def.Min != nil || def.Max != nil {
+			n := toFloat64(value)
+			if def.Min != nil && n < *def.Min {
+				return fmt.Errorf("%s: %w: must be at least %v", paramValue, ErrParamConstraint, *def.Min), false
+			}
+
+			if def.Max != nil && n > *def.Max {
+				return fmt.Errorf("%s: %w: must be at most %v", paramValue, ErrParamConstraint, *def.Max), false
+			}
+		}
+
+		return value, true
+	}
+
+	m := NewMacro(def.Name, def.Alias, nil, false, base.Trailing(), evaluator)
+	m.goType = base.goType
+	m.handleError = base.handleError
+	m.funcs = append([]ParamFunc(nil), base.funcs...)
+	m.definition = &def
+	return m, nil
+}
+
+// Definition returns the definition of a parameter type
+// which was registered through `Macros.Define` or `Macros.Load`, otherwise nil.
+func (m *Macro) Definition() *Definition {
+	return m.definition
+}
+
+func isOneOf(s string, values []string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNumeric(typ reflect.Type) bool {
+	if typ == nil {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func toFloat64(v interface{}) float64 {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	default:
+		return 0
+	}
+}
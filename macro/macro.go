@@ -201,12 +201,12 @@ func convertBuilderFunc(fn interface{}) ParamFuncBuilder {
 				panicIfErr(i, err)
 				val = v
 			case reflect.Slice:
-				if len(arg) > 1 {
-					if arg[0] == '[' && arg[len(arg)-1] == ']' {
-						// it is a single argument but as slice.
-						val = strings.Split(arg[1:len(arg)-1], ",") // only string slices.
-					}
+				if len(arg) > 1 && arg[0] == '[' && arg[len(arg)-1] == ']' {
+					// it is a single argument but as slice.
+					arg = arg[1 : len(arg)-1]
 				}
+				// or the comma separated arguments, e.g. eqor(a,b,c).
+				val = strings.Split(arg, ",") // only string slices.
 			default:
 				val = arg
 			}
@@ -257,7 +257,8 @@ type (
 		handleError interface{}
 		funcs       []ParamFunc
 
-		goType reflect.Type
+		goType     reflect.Type
+		definition *Definition
 	}
 
 	// ParamFuncBuilder is a func
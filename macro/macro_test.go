@@ -1,8 +1,12 @@
 package macro
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -544,6 +548,305 @@ func TestWeekdayEvaluatorRaw(t *testing.T) {
 	}
 }
 
+func TestULIDEvaluatorRaw(t *testing.T) {
+	tests := []struct {
+		pass  bool
+		input string
+	}{
+		{true, "01ARZ3NDEKTSV4RRFFQ69G5FAV"},   // 0
+		{true, "01arz3ndektsv4rrffq69g5fav"},   // 1
+		{false, "81ARZ3NDEKTSV4RRFFQ69G5FAV"},  // 2
+		{false, "01ARZ3NDEKTSV4RRFFQ69G5FAI"},  // 3
+		{false, "01ARZ3NDEKTSV4RRFFQ69G5FA"},   // 4
+		{false, "01ARZ3NDEKTSV4RRFFQ69G5FAVV"}, // 5
+	}
+	for i, tt := range tests {
+		testEvaluatorRaw(t, ULID, tt.input, reflect.String, tt.pass, i)
+	}
+}
+
+func TestSemverEvaluatorRaw(t *testing.T) {
+	tests := []struct {
+		pass     bool
+		input    string
+		expected SemanticVersion
+	}{
+		{true, "1.2.3", SemanticVersion{Major: 1, Minor: 2, Patch: 3}},                                // 0
+		{true, "v0.10.0", SemanticVersion{Minor: 10}},                                                 // 1
+		{true, "1.0.0-rc.1+build.5", SemanticVersion{Major: 1, PreRelease: "rc.1", Build: "build.5"}}, // 2
+		{false, "1.2", SemanticVersion{}},                                                             // 3
+		{false, "01.2.3", SemanticVersion{}},                                                          // 4
+		{false, "1.2.3-", SemanticVersion{}},                                                          // 5
+	}
+	for i, tt := range tests {
+		testEvaluatorRaw(t, Semver, tt.input, reflect.Struct, tt.pass, i)
+
+		if v, ok := Semver.Evaluator(tt.input); ok {
+			if got := v.(SemanticVersion); got != tt.expected {
+				t.Fatalf("[%d] expected: %#+v but got: %#+v", i, tt.expected, got)
+			}
+
+			if expected, got := strings.TrimPrefix(tt.input, "v"), v.(SemanticVersion).String(); expected != got {
+				t.Fatalf("[%d] expected: %s but got: %s", i, expected, got)
+			}
+		}
+	}
+}
+
+func TestBytesEvaluatorRaw(t *testing.T) {
+	tests := []struct {
+		macro *Macro
+		pass  bool
+		input string
+	}{
+		{Base64, true, "aXJpcw=="}, // 0
+		{Base64, true, "aXJpcw"},   // 1
+		{Base64, true, "-_8"},      // 2
+		{Base64, true, "+/8="},     // 3
+		{Base64, false, "aXJpcw!"}, // 4
+		{Hex, true, "69726973"},    // 5
+		{Hex, false, "6972697"},    // 6
+		{Hex, false, "iris"},       // 7
+	}
+	for i, tt := range tests {
+		testEvaluatorRaw(t, tt.macro, tt.input, reflect.Slice, tt.pass, i)
+	}
+
+	if v, _ := Base64.Evaluator("aXJpcw"); string(v.([]byte)) != "iris" {
+		t.Fatalf("expected the decoded value but got: %v", v)
+	}
+}
+
+func TestSlugEvaluatorRaw(t *testing.T) {
+	tests := []struct {
+		pass  bool
+		input string
+	}{
+		{true, "hello-world-2"}, // 0
+		{true, "iris"},          // 1
+		{false, "Hello-World"},  // 2
+		{false, "hello--world"}, // 3
+		{false, "-hello"},       // 4
+		{false, "hello_world"},  // 5
+	}
+	for i, tt := range tests {
+		testEvaluatorRaw(t, Slug, tt.input, reflect.String, tt.pass, i)
+	}
+}
+
+func TestIPEvaluatorRaw(t *testing.T) {
+	tests := []struct {
+		macro *Macro
+		pass  bool
+		input string
+	}{
+		{IPv4, true, "192.168.1.1"}, // 0
+		{IPv4, false, "::1"},        // 1
+		{IPv4, false, "256.0.0.1"},  // 2
+		{IPv6, true, "::1"},         // 3
+		{IPv6, true, "2001:db8::1"}, // 4
+		{IPv6, false, "127.0.0.1"},  // 5
+		{IPv6, false, "localhost"},  // 6
+	}
+	for i, tt := range tests {
+		testEvaluatorRaw(t, tt.macro, tt.input, reflect.Struct, tt.pass, i)
+	}
+}
+
+func TestDurationEvaluatorRaw(t *testing.T) {
+	tests := []struct {
+		pass     bool
+		input    string
+		expected time.Duration
+	}{
+		{true, "300ms", 300 * time.Millisecond},       // 0
+		{true, "2h45m", 2*time.Hour + 45*time.Minute}, // 1
+		{false, "2 hours", 0},                         // 2
+		{false, "10", 0},                              // 3
+	}
+	for i, tt := range tests {
+		testEvaluatorRaw(t, Duration, tt.input, reflect.Int64, tt.pass, i)
+
+		if v, ok := Duration.Evaluator(tt.input); ok && v.(time.Duration) != tt.expected {
+			t.Fatalf("[%d] expected: %s but got: %s", i, tt.expected, v)
+		}
+	}
+}
+
+func TestISO8601EvaluatorRaw(t *testing.T) {
+	tests := []struct {
+		pass  bool
+		input string
+	}{
+		{true, "2024-04-25T10:00:00Z"},          // 0
+		{true, "2024-04-25T10:00:00.123+03:00"}, // 1
+		{false, "2024-04-25"},                   // 2
+		{false, "2024/04/25"},                   // 3
+		{false, "2024-04-25 10:00:00"},          // 4
+	}
+	for i, tt := range tests {
+		testEvaluatorRaw(t, ISO8601, tt.input, reflect.Struct, tt.pass, i)
+	}
+}
+
+func TestEnumParse(t *testing.T) {
+	tmpl, err := Parse("/colors/{color:enum(red,green,blue)}", *Defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := tmpl.Params[0]
+	for _, tt := range []struct {
+		input string
+		pass  bool
+	}{
+		{"red", true},
+		{"blue", true},
+		{"black", false},
+	} {
+		if _, passed := p.Eval(tt.input); passed != tt.pass {
+			t.Fatalf("%s: expected pass: %v but got: %v", tt.input, tt.pass, passed)
+		}
+	}
+}
+
+func TestEnumParseWithoutValues(t *testing.T) {
+	for _, src := range []string{"/colors/{color:enum}", "/colors/{color:enum()}"} {
+		if _, err := Parse(src, *Defaults); err == nil {
+			t.Fatalf("%s: expected an error for an enum without values", src)
+		}
+	}
+}
+
+func TestRegisterReplacesExtensions(t *testing.T) {
+	macros := Macros{String, Int, Slug, ISO8601}
+
+	slug := macros.Register("slug", "", "", false, false, nil)
+	if slug == nil {
+		t.Fatalf("expected a custom slug to replace the builtin one")
+	}
+
+	if got := macros.Get("slug"); got != slug {
+		t.Fatalf("expected the custom slug but got: %v", got)
+	}
+
+	if macros.Register("datetime", "", "", false, false, nil) == nil || macros.Get("iso8601") != nil {
+		t.Fatalf("expected a custom datetime to replace the builtin iso8601")
+	}
+
+	if len(macros) != 4 {
+		t.Fatalf("expected 4 macros but got %d", len(macros))
+	}
+
+	if macros.Register("int", "", 0, false, false, nil) != nil {
+		t.Fatalf("expected the builtin int to not be replaced")
+	}
+}
+
+func TestDefine(t *testing.T) {
+	macros := Macros{String, Int, Uint64}
+
+	err := macros.Define(
+		Definition{Name: "username", Regexp: "^[a-z0-9_]+$", MinLength: intPtr(3), MaxLength: intPtr(10)},
+		Definition{Name: "page", Type: "int", Min: floatPtr(1), Max: floatPtr(100)},
+		Definition{Name: "color", Alias: "colour", Enum: []string{"red", "green"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		indent       string
+		input        string
+		pass         bool
+		expectedType reflect.Kind
+	}{
+		{"username", "kataras", true, reflect.String},  // 0
+		{"username", "ka", false, reflect.String},      // 1
+		{"username", "Kataras", false, reflect.String}, // 2
+		{"page", "42", true, reflect.Int},              // 3
+		{"page", "0", false, reflect.Int},              // 4
+		{"page", "x", false, reflect.Int},              // 5
+		{"colour", "red", true, reflect.String},        // 6
+		{"color", "blue", false, reflect.String},       // 7
+	}
+	for i, tt := range tests {
+		testEvaluatorRaw(t, macros.Get(tt.indent), tt.input, tt.expectedType, tt.pass, i)
+	}
+
+	if v, ok := macros.Get("page").Evaluator("0"); ok || !errors.Is(v.(error), ErrParamConstraint) {
+		t.Fatalf("expected error: %v but got: %v", ErrParamConstraint, v)
+	}
+
+	// The functions of the base type are inherited.
+	tmpl, err := Parse("/{p:page max(10)}", macros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, passed := tmpl.Params[0].Eval("11"); passed {
+		t.Fatalf("expected the max function to fail")
+	}
+
+	for _, def := range []Definition{
+		{Name: "username"},
+		{Name: "unknown", Type: "float"},
+		{Name: "invalid", Regexp: "["},
+		{Name: "length", Min: floatPtr(1)},
+		{Type: "string"},
+	} {
+		if err = macros.Define(def); err == nil {
+			t.Fatalf("expected an error for definition: %#+v", def)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	// YAML and JSON files share the same keys.
+	files := map[string]string{
+		"macros.yml": `
+- name: slugid
+  type: uint64
+  max: 1000
+- name: locale
+  enum: [en-US, el-GR]
+  maxLength: 5
+`,
+		"macros.json": `[
+	{"name": "slugid", "type": "uint64", "max": 1000},
+	{"name": "locale", "enum": ["en-US", "el-GR"], "maxLength": 5}
+]`,
+	}
+
+	for name, data := range files {
+		filename := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(filename, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		macros := Macros{String, Uint64}
+		if err := macros.Load(filename); err != nil {
+			t.Fatal(err)
+		}
+
+		testEvaluatorRaw(t, macros.Get("slugid"), "1000", reflect.Uint64, true, 0)
+		testEvaluatorRaw(t, macros.Get("slugid"), "1001", reflect.Uint64, false, 1)
+		testEvaluatorRaw(t, macros.Get("locale"), "el-GR", reflect.String, true, 2)
+
+		def := macros.Get("locale").Definition()
+		if def == nil || def.Type != "string" {
+			t.Fatalf("%s: expected the definition of the base type string but got: %#+v", name, def)
+		}
+
+		if def.MaxLength == nil || *def.MaxLength != 5 {
+			t.Fatalf("%s: expected max length 5 but got: %#+v", name, def.MaxLength)
+		}
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func floatPtr(n float64) *float64 { return &n }
+
 func TestConvertBuilderFunc(t *testing.T) {
 	fn := func(min uint64, slice []string) func(string) bool {
 		return func(paramValue string) bool {
@@ -1,10 +1,13 @@
 package macro
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
 	"net/mail"
+	"net/netip"
 	"strconv"
 	"strings"
 	"time"
@@ -511,6 +514,133 @@ var (
 		return d, true
 	})
 
+	// ErrParamNotULID is fired when the parameter value is not a form of a ULID.
+	ErrParamNotULID = errors.New("parameter is not a valid ULID")
+	// ULID string type for validating a ULID path parameter, e.g. "01ARZ3NDEKTSV4RRFFQ69G5FAV".
+	// Read more at: https://github.com/ulid/spec.
+	ULID = NewMacro("ulid", "", "", false, false, func(paramValue string) (interface{}, bool) {
+		if !isULID(paramValue) {
+			return fmt.Errorf("%s: %w", paramValue, ErrParamNotULID), false
+		}
+
+		return paramValue, true
+	})
+
+	// ErrParamNotSemver is fired when the parameter value is not a form of a semantic version.
+	ErrParamNotSemver = errors.New("parameter is not a valid semantic version")
+	// Semver type, returns a type of macro.SemanticVersion.
+	// Valid values: "1.2.3", "v1.2.3", "1.0.0-rc.1+build.5" and e.t.c.
+	// Read more at: https://semver.org.
+	Semver = NewMacro("semver", "", SemanticVersion{}, false, false, func(paramValue string) (interface{}, bool) {
+		v, err := ParseSemanticVersion(paramValue)
+		if err != nil {
+			return err, false
+		}
+
+		return v, true
+	})
+
+	// Base64 type, returns the decoded []byte value.
+	// It accepts the standard and the URL-safe encodings, with or without padding.
+	Base64 = NewMacro("base64", "", []byte{}, false, false, func(paramValue string) (interface{}, bool) {
+		s := strings.TrimRight(paramValue, "=")
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			if b, err = base64.RawStdEncoding.DecodeString(s); err != nil {
+				return fmt.Errorf("%s: %w", paramValue, err), false
+			}
+		}
+
+		return b, true
+	})
+
+	// Hex type, returns the decoded []byte value of a hexadecimal string.
+	Hex = NewMacro("hex", "", []byte{}, false, false, func(paramValue string) (interface{}, bool) {
+		b, err := hex.DecodeString(paramValue)
+		if err != nil {
+			return fmt.Errorf("%s: %w", paramValue, err), false
+		}
+
+		return b, true
+	})
+
+	// ErrParamNotSlug is fired when the parameter value is not a form of a slug.
+	ErrParamNotSlug = errors.New("parameter is not a valid slug")
+	slugEval        = MustRegexp("^[a-z0-9]+(?:-[a-z0-9]+)*$")
+	// Slug string type
+	// lowercase letters and numbers (a-z, 0-9)
+	// separated by single dashes, e.g. "hello-world-2".
+	Slug = NewMacro("slug", "", "", false, false, func(paramValue string) (interface{}, bool) {
+		if !slugEval(paramValue) {
+			return fmt.Errorf("%s: %w", paramValue, ErrParamNotSlug), false
+		}
+
+		return paramValue, true
+	})
+
+	// ErrParamNotIPv4 is fired when the parameter value is not a form of an IPv4 address.
+	ErrParamNotIPv4 = errors.New("parameter is not a valid IPv4 address")
+	// IPv4 type, returns a type of netip.Addr.
+	IPv4 = NewMacro("ipv4", "", netip.Addr{}, false, false, func(paramValue string) (interface{}, bool) {
+		addr, err := netip.ParseAddr(paramValue)
+		if err != nil || !addr.Is4() {
+			return fmt.Errorf("%s: %w", paramValue, ErrParamNotIPv4), false
+		}
+
+		return addr, true
+	})
+
+	// ErrParamNotIPv6 is fired when the parameter value is not a form of an IPv6 address.
+	ErrParamNotIPv6 = errors.New("parameter is not a valid IPv6 address")
+	// IPv6 type, returns a type of netip.Addr.
+	IPv6 = NewMacro("ipv6", "", netip.Addr{}, false, false, func(paramValue string) (interface{}, bool) {
+		addr, err := netip.ParseAddr(paramValue)
+		if err != nil || !addr.Is6() {
+			return fmt.Errorf("%s: %w", paramValue, ErrParamNotIPv6), false
+		}
+
+		return addr, true
+	})
+
+	// Enum string type
+	// its values are given inline, e.g. {color:enum(red,green,blue)},
+	// a path without them is rejected.
+	Enum = NewMacro("enum", "", "", false, false, nil).
+		// checks if param value's matches one of the 'values'.
+		RegisterFunc("enum", func(values []string) func(string) bool {
+			return func(paramValue string) bool {
+				for _, s := range values {
+					if paramValue == s {
+						return true
+					}
+				}
+
+				return false
+			}
+		})
+
+	// Duration type, returns a type of time.Duration.
+	// Valid values: "300ms", "1.5h", "2h45m" and e.t.c, see time.ParseDuration.
+	Duration = NewMacro("duration", "", time.Duration(0), false, false, func(paramValue string) (interface{}, bool) {
+		d, err := time.ParseDuration(paramValue)
+		if err != nil {
+			return fmt.Errorf("%s: %w", paramValue, err), false
+		}
+
+		return d, true
+	})
+
+	// ISO8601 type, returns a type of time.Time
+	// of an ISO 8601 (RFC 3339) date-time, e.g. "2024-04-25T10:00:00Z".
+	ISO8601 = NewMacro("iso8601", "datetime", time.Time{}, false, false, func(paramValue string) (interface{}, bool) {
+		tt, err := time.Parse(time.RFC3339, paramValue)
+		if err != nil {
+			return fmt.Errorf("%s: %w", paramValue, err), false
+		}
+
+		return tt, true
+	})
+
 	// Defaults contains the defaults macro and parameters types for the router.
 	//
 	// Read https://github.com/kataras/iris/tree/main/_examples/routing/macros for more details.
@@ -535,6 +665,16 @@ var (
 		Email,
 		Date,
 		Weekday,
+		ULID,
+		Semver,
+		Base64,
+		Hex,
+		Slug,
+		IPv4,
+		IPv6,
+		Enum,
+		Duration,
+		ISO8601,
 	}
 )
 
@@ -544,6 +684,8 @@ type Macros []*Macro
 
 // Register registers a custom Macro.
 // The "indent" should not be empty and should be unique, it is the parameter type's name, i.e "string".
+// A custom Macro replaces a builtin one of the "ulid", "semver", "base64", "hex", "slug", "ipv4",
+// "ipv6", "enum", "duration" and "iso8601" ("datetime") parameter types of the same name.
 // The "alias" is optionally and it should be unique, it is the alias of the parameter type.
 // The "valueType" should be the zero value of the parameter type, i.e "" for string, 0 for int and etc.
 // "isMaster" and "isTrailing" is for default parameter type and wildcard respectfully.
@@ -559,6 +701,21 @@ func (ms *Macros) Register(indent, alias string, valueType any, isMaster, isTrai
 	return nil
 }
 
+// extensions are the builtin parameter types which were added to the `Defaults` later on.
+// A custom macro with the same name replaces them instead of being rejected,
+// so the applications which already registered one keep working.
+var extensions = []*Macro{ULID, Semver, Base64, Hex, Slug, IPv4, IPv6, Enum, Duration, ISO8601}
+
+func isExtension(m *Macro) bool {
+	for _, ext := range extensions {
+		if m == ext {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (ms *Macros) register(macro *Macro) bool {
 	if macro.Indent() == "" {
 		return false
@@ -566,23 +723,32 @@ func (ms *Macros) register(macro *Macro) bool {
 
 	cp := *ms
 
+	var replaced []string
 	for _, m := range cp {
 		// can't add more than one with the same ast characteristics.
-		if macro.Indent() == m.Indent() {
-			return false
+		conflict := macro.Indent() == m.Indent() || (macro.Master() && m.Master())
+		if alias := macro.Alias(); alias != "" {
+			conflict = conflict || alias == m.Alias() || alias == m.Indent()
 		}
 
-		if alias := macro.Alias(); alias != "" {
-			if alias == m.Alias() || alias == m.Indent() {
-				return false
+		if isExtension(m) {
+			// a custom "datetime" would be hidden by the alias of the builtin "iso8601".
+			if conflict || macro.Indent() == m.Alias() {
+				replaced = append(replaced, m.Indent())
 			}
+
+			continue
 		}
 
-		if macro.Master() && m.Master() {
+		if conflict {
 			return false
 		}
 	}
 
+	for _, indent := range replaced {
+		cp.Unregister(indent)
+	}
+
 	cp = append(cp, macro)
 
 	*ms = cp
@@ -678,3 +844,21 @@ func (ms *Macros) SetErrorHandler(fnHandler interface{}) {
 		m.HandleError(fnHandler)
 	}
 }
+
+// ulidAlphabet is the Crockford's base32 alphabet of the ULIDs.
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZabcdefghjkmnpqrstvwxyz"
+
+func isULID(s string) bool {
+	// 26 characters, the first one is at most "7" because ULIDs are 128 bits.
+	if len(s) != 26 || s[0] > '7' {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(ulidAlphabet, s[i]) == -1 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,58 @@
+package macro
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// SemanticVersion is the value type of the "semver" parameter type.
+type SemanticVersion struct {
+	Major      uint64 `json:"major"`
+	Minor      uint64 `json:"minor"`
+	Patch      uint64 `json:"patch"`
+	PreRelease string `json:"preRelease,omitempty"`
+	Build      string `json:"build,omitempty"`
+}
+
+// The official expression of https://semver.org, with an optional "v" prefix.
+var semverExpr = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// ParseSemanticVersion parses a semantic version, e.g. "1.2.3" or "v1.0.0-rc.1+build.5".
+func ParseSemanticVersion(s string) (SemanticVersion, error) {
+	matches := semverExpr.FindStringSubmatch(s)
+	if matches == nil {
+		return SemanticVersion{}, fmt.Errorf("%s: %w", s, ErrParamNotSemver)
+	}
+
+	var (
+		v   SemanticVersion
+		err error
+	)
+
+	for i, n := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		if *n, err = strconv.ParseUint(matches[i+1], 10, 64); err != nil {
+			return SemanticVersion{}, fmt.Errorf("%s: %w", s, err)
+		}
+	}
+
+	v.PreRelease = matches[4]
+	v.Build = matches[5]
+	return v, nil
+}
+
+// String returns the text form of the version, without the "v" prefix.
+func (v SemanticVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+
+	return s
+}
@@ -1,6 +1,7 @@
 package macro
 
 import (
+	"fmt"
 	"reflect"
 
 	"github.com/kataras/iris/v12/macro/interpreter/ast"
@@ -142,6 +143,11 @@ func Parse(src string, macros Macros) (Template, error) {
 
 	for idx, p := range params {
 		m := macros.Lookup(p.Type)
+		if m == Enum && !hasEnumValues(p.Funcs) {
+			// without values the parameter would accept anything.
+			return tmpl, fmt.Errorf("%s: enum parameter type requires its values, e.g. {%s:enum(a,b)}", p.Src, p.Name)
+		}
+
 		typEval := m.Evaluator
 
 		tmplParam := TemplateParam{
@@ -157,6 +163,12 @@ func Parse(src string, macros Macros) (Template, error) {
 		}
 
 		for _, paramfn := range p.Funcs {
+			if paramfn.Name == "" {
+				// the arguments of the type itself, e.g. {color:enum(red,green)},
+				// are passed to its function of the same name.
+				paramfn.Name = m.Indent()
+			}
+
 			tmplFn := m.getFunc(paramfn.Name)
 			if tmplFn == nil { // if not find on this type, check for Master's which is for global funcs too.
 				if m := macros.GetMaster(); m != nil {
@@ -181,6 +193,18 @@ func Parse(src string, macros Macros) (Template, error) {
 	return tmpl, nil
 }
 
+// hasEnumValues reports whether the values of an enum parameter are given,
+// e.g. {color:enum(red,green)} or {color:enum enum(red,green)}.
+func hasEnumValues(funcs []ast.ParamFunc) bool {
+	for _, fn := range funcs {
+		if (fn.Name == "" || fn.Name == Enum.Indent()) && len(fn.Args) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 // CountParams returns the length of the dynamic path's input parameters.
 func CountParams(fullpath string, macros Macros) int {
 	tmpl, _ := Parse(fullpath, macros)
@@ -0,0 +1,287 @@
+// Package apikey implements an API key authentication middleware
+// for machine-to-machine requests. The keys are in the form of "prefix_id_secret",
+// they are looked up by their id and their secret is verified against its stored hash
+// in constant time. The key's owner and scopes are attached to the request as its Context.User.
+package apikey
+
+import (
+	stdContext "context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+func init() {
+	context.SetHandlerName("iris/middleware/apikey.*", "iris.apikey")
+}
+
+const (
+	// DefaultPrefix is the default prefix of the API keys.
+	DefaultPrefix = "iris"
+	// DefaultHeader is the default request header which the API key is read from.
+	// The "Authorization: Bearer <key>" header is accepted as well.
+	DefaultHeader = "X-API-Key"
+	// DefaultTouchInterval is the default minimum interval between two updates
+	// of the last used time of a key.
+	DefaultTouchInterval = time.Minute
+)
+
+var (
+	// ErrMissing is fired when the request does not contain an API key.
+	ErrMissing = errors.New("apikey: missing")
+	// ErrInvalid is fired when the API key is malformed, it does not exist
+	// or its secret does not match.
+	ErrInvalid = errors.New("apikey: invalid")
+	// ErrExpired is fired when the API key has expired.
+	ErrExpired = errors.New("apikey: expired")
+	// ErrInsufficientScope is fired when the API key does not hold the required scopes.
+	ErrInsufficientScope = errors.New("apikey: insufficient scope")
+)
+
+// ErrorHandler handles the API key authentication and authorization failures.
+// See Options.ErrorHandler and DefaultErrorHandler.
+type ErrorHandler func(ctx *context.Context, err error)
+
+// DefaultErrorHandler responds with 403 Forbidden on ErrInsufficientScope
+// and with 401 Unauthorized on the rest of the errors.
+func DefaultErrorHandler(ctx *context.Context, err error) {
+	if errors.Is(err, ErrInsufficientScope) {
+		ctx.StopWithStatus(http.StatusForbidden)
+		return
+	}
+
+	ctx.StopWithStatus(http.StatusUnauthorized)
+}
+
+// Options holds the configuration of the API key middleware.
+// The only required value is the Store field.
+type Options struct {
+	// Store keeps the keys. Required.
+	// See the `NewMemoryStore` and `NewFileStore` package-level functions.
+	Store Store
+	// Prefix of the keys, see the `Generate` package-level function.
+	// Defaults to "iris".
+	Prefix string
+	// Header is the request header which the key is read from.
+	// Defaults to "X-API-Key".
+	Header string
+	// QueryParam, if not empty, is the URL query parameter which the key is read from,
+	// when the request does not contain the header.
+	// Keys in URLs may end up to logs, prefer the header.
+	QueryParam string
+	// TouchInterval is the minimum interval between two updates
+	// of the last used time of a key, to reduce the writes to the Store.
+	// Defaults to one minute, a negative value disables the tracking.
+	TouchInterval time.Duration
+	// ErrorHandler handles the failures.
+	// Defaults to the DefaultErrorHandler.
+	ErrorHandler ErrorHandler
+}
+
+// APIKey is the API key authentication middleware.
+// Initialize it through the `New` package-level function
+// and register its `Handler` method.
+type APIKey struct {
+	opts Options
+}
+
+// New returns a new API key middleware.
+//
+// Example Code:
+//
+//	keys := apikey.MustFileStore("keys.yml")
+//	a := apikey.New(apikey.Options{Store: keys})
+//	api := app.Party("/api", a.Handler)
+//	api.Post("/orders", a.RequireScopes("orders:write"), createOrder)
+//
+// Access the key in the route handler with: apikey.GetKey(ctx) or ctx.User().
+func New(opts Options) *APIKey {
+	if opts.Store == nil {
+		panic("apikey: store is required")
+	}
+
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultPrefix
+	}
+
+	if opts.Header == "" {
+		opts.Header = DefaultHeader
+	}
+
+	if opts.TouchInterval == 0 {
+		opts.TouchInterval = DefaultTouchInterval
+	}
+
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = DefaultErrorHandler
+	}
+
+	return &APIKey{opts: opts}
+}
+
+// Handler authenticates the request by its API key,
+// sets the Key as the Context.User and calls the next handler.
+func (a *APIKey) Handler(ctx *context.Context) {
+	apiKey := a.extract(ctx)
+	if apiKey == "" {
+		a.opts.ErrorHandler(ctx, ErrMissing)
+		return
+	}
+
+	key, err := a.Authenticate(ctx, apiKey)
+	if err != nil {
+		a.opts.ErrorHandler(ctx, err)
+		return
+	}
+
+	ctx.SetUser(key)
+	ctx.Values().Set(keyContextKey, key)
+	ctx.Next()
+}
+
+func (a *APIKey) extract(ctx *context.Context) string {
+	if v := ctx.GetHeader(a.opts.Header); v != "" {
+		return v
+	}
+
+	if v := ctx.GetHeader("Authorization"); len(v) > 7 && strings.EqualFold(v[:7], "Bearer ") {
+		return strings.TrimSpace(v[7:])
+	}
+
+	if a.opts.QueryParam != "" {
+		return ctx.URLParam(a.opts.QueryParam)
+	}
+
+	return ""
+}
+
+// Authenticate looks up the key of the "apiKey" and verifies its secret.
+// It returns ErrInvalid or ErrExpired on failure.
+// On success, it updates the last used time of the key, see Options.TouchInterval.
+func (a *APIKey) Authenticate(ctx stdContext.Context, apiKey string) (*Key, error) {
+	id, secret, ok := parse(a.opts.Prefix, apiKey)
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	key, err := a.opts.Store.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrInvalid
+		}
+
+		return nil, err
+	}
+
+	if !key.compare(secret) {
+		return nil, ErrInvalid
+	}
+
+	now := time.Now()
+	if key.Expired(now) {
+		return nil, ErrExpired
+	}
+
+	key.authorizedAt = now
+
+	if a.opts.TouchInterval > 0 && now.Sub(key.LastUsedAt) >= a.opts.TouchInterval {
+		if err = a.opts.Store.Touch(ctx, key.ID, now); err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = now
+	}
+
+	return key, nil
+}
+
+// Generate creates and saves a new API key of the "owner" with the given "scopes".
+// If "maxAge" is positive then the key expires after that duration.
+// The returned API key should be shown to its owner once, it can not be recovered.
+func (a *APIKey) Generate(ctx stdContext.Context, owner string, scopes []string, maxAge time.Duration) (string, *Key, error) {
+	apiKey, key, err := Generate(a.opts.Prefix, owner, scopes, maxAge)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err = a.opts.Store.Save(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	return apiKey, key, nil
+}
+
+// Rotate creates and saves a new API key with the owner, the scopes and the fields
+// of the key of the given "id" and it expires the old key after the "gracePeriod",
+// so its clients have the time to switch to the new key.
+// The new key keeps the lifetime of the old one, if any.
+func (a *APIKey) Rotate(ctx stdContext.Context, id string, gracePeriod time.Duration) (string, *Key, error) {
+	old, err := a.opts.Store.Get(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var maxAge time.Duration
+	if !old.ExpiresAt.IsZero() {
+		maxAge = old.ExpiresAt.Sub(old.CreatedAt)
+	}
+
+	apiKey, key, err := Generate(a.opts.Prefix, old.Owner, old.Scopes, maxAge)
+	if err != nil {
+		return "", nil, err
+	}
+	key.Fields = old.Fields
+
+	if err = a.opts.Store.Save(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	if expiresAt := time.Now().Add(gracePeriod); old.ExpiresAt.IsZero() || expiresAt.Before(old.ExpiresAt) {
+		old.ExpiresAt = expiresAt
+		if err = a.opts.Store.Save(ctx, old); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return apiKey, key, nil
+}
+
+// Revoke removes the key of the given "id".
+func (a *APIKey) Revoke(ctx stdContext.Context, id string) error {
+	return a.opts.Store.Delete(ctx, id)
+}
+
+// RequireScopes returns a middleware which allows the requests
+// that are authenticated by a key which holds all the "scopes".
+// It should be registered after the Handler.
+func (a *APIKey) RequireScopes(scopes ...string) context.Handler {
+	return func(ctx *context.Context) {
+		key := GetKey(ctx)
+		if key == nil {
+			a.opts.ErrorHandler(ctx, ErrMissing)
+			return
+		}
+
+		if !key.HasScopes(scopes...) {
+			a.opts.ErrorHandler(ctx, ErrInsufficientScope)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+const keyContextKey = "iris.apikey.key"
+
+// GetKey returns the Key of the request, if it's authenticated by the API key middleware.
+func GetKey(ctx *context.Context) *Key {
+	if v := ctx.Values().Get(keyContextKey); v != nil {
+		if key, ok := v.(*Key); ok {
+			return key
+		}
+	}
+
+	return nil
+}
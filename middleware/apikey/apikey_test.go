@@ -0,0 +1,111 @@
+package apikey_test
+
+import (
+	stdContext "context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/middleware/apikey"
+)
+
+func TestAPIKey(t *testing.T) {
+	ctx := stdContext.Background()
+	filename := filepath.Join(t.TempDir(), "keys.yml")
+	store, err := apikey.NewFileStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := apikey.New(apikey.Options{Store: store, QueryParam: "api_key"})
+
+	orders, ordersKey, err := a.Generate(ctx, "billing-service", []string{"orders:read", "orders:write"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, _, err := a.Generate(ctx, "reports-service", []string{"reports:read"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired, _, err := a.Generate(ctx, "old-service", []string{"*"}, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	api := app.Party("/api", a.Handler)
+	api.Get("/me", func(ctx iris.Context) {
+		id, _ := ctx.User().GetID()
+		ctx.WriteString(id)
+	})
+	api.Post("/orders", a.RequireScopes("orders:write"), func(ctx iris.Context) {
+		ctx.WriteString(apikey.GetKey(ctx).ID)
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/api/me").Expect().Status(iris.StatusUnauthorized)
+	e.GET("/api/me").WithHeader("X-API-Key", "iris_unknown_secret").Expect().Status(iris.StatusUnauthorized)
+	e.GET("/api/me").WithHeader("X-API-Key", orders+"x").Expect().Status(iris.StatusUnauthorized)
+	e.GET("/api/me").WithHeader("X-API-Key", expired).Expect().Status(iris.StatusUnauthorized)
+
+	e.GET("/api/me").WithHeader("X-API-Key", orders).Expect().Status(iris.StatusOK).Body().IsEqual("billing-service")
+	e.GET("/api/me").WithHeader("Authorization", "Bearer "+reports).Expect().Status(iris.StatusOK).Body().IsEqual("reports-service")
+	e.GET("/api/me").WithQuery("api_key", orders).Expect().Status(iris.StatusOK).Body().IsEqual("billing-service")
+
+	e.POST("/api/orders").WithHeader("X-API-Key", orders).Expect().Status(iris.StatusOK).Body().IsEqual(ordersKey.ID)
+	e.POST("/api/orders").WithHeader("X-API-Key", reports).Expect().Status(iris.StatusForbidden)
+
+	stored, err := store.Get(ctx, ordersKey.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.LastUsedAt.IsZero() {
+		t.Fatalf("expected last used time to be tracked")
+	}
+
+	// rotation keeps the old key valid during the grace period.
+	rotated, rotatedKey, err := a.Rotate(ctx, ordersKey.ID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "billing-service", rotatedKey.Owner; expected != got {
+		t.Fatalf("expected owner: %s but got: %s", expected, got)
+	}
+
+	e.POST("/api/orders").WithHeader("X-API-Key", rotated).Expect().Status(iris.StatusOK).Body().IsEqual(rotatedKey.ID)
+	e.POST("/api/orders").WithHeader("X-API-Key", orders).Expect().Status(iris.StatusOK)
+
+	if _, _, err = a.Rotate(ctx, rotatedKey.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	e.POST("/api/orders").WithHeader("X-API-Key", rotated).Expect().Status(iris.StatusUnauthorized)
+
+	if err = a.Revoke(ctx, ordersKey.ID); err != nil {
+		t.Fatal(err)
+	}
+	e.POST("/api/orders").WithHeader("X-API-Key", orders).Expect().Status(iris.StatusUnauthorized)
+
+	// the file keeps the hashed keys.
+	loaded, err := apikey.NewFileStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := len(store.Keys()), len(loaded.Keys()); expected != got {
+		t.Fatalf("expected %d keys on file but got %d", expected, got)
+	}
+
+	e = httptest.New(t, iris.New().Configure(func(app *iris.Application) {
+		app.Get("/", apikey.New(apikey.Options{Store: loaded}).Handler, func(ctx iris.Context) {
+			ctx.WriteString(apikey.GetKey(ctx).Owner)
+		})
+	}))
+	e.GET("/").WithHeader("X-API-Key", reports).Expect().Status(iris.StatusOK).Body().IsEqual("reports-service")
+}
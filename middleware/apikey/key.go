@@ -0,0 +1,211 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+// authorizationType is the value of the Key's GetAuthorization method.
+const authorizationType = "API Key"
+
+var secretEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// Key holds the information of an API key. The secret part of the key
+// is never stored, only its SHA-256 hash.
+//
+// A Key completes the iris.User interface, it's the Context.User
+// of the requests which are authenticated by it:
+// its Owner is the user's ID and username and its Scopes are the user's roles.
+type Key struct {
+	// ID is the public, unique, identifier of the key.
+	ID string `json:"id" yaml:"ID"`
+	// Hash is the hex-encoded SHA-256 hash of the key's secret.
+	Hash string `json:"hash" yaml:"Hash"`
+	// Owner is the identifier of the key's owner, e.g. a service name or a user id.
+	Owner string `json:"owner" yaml:"Owner"`
+	// Scopes of the key, see the `RequireScopes` method.
+	Scopes []string `json:"scopes,omitempty" yaml:"Scopes,omitempty"`
+	// Fields holds any custom data of the key, see the GetField method.
+	Fields context.Map `json:"fields,omitempty" yaml:"Fields,omitempty"`
+	// CreatedAt is the creation time of the key.
+	CreatedAt time.Time `json:"created_at" yaml:"CreatedAt"`
+	// ExpiresAt is the expiration time of the key, zero for no expiration.
+	ExpiresAt time.Time `json:"expires_at,omitempty" yaml:"ExpiresAt,omitempty"`
+	// LastUsedAt is the time of the last request which is authenticated by the key.
+	// It's updated at most once per Options.TouchInterval.
+	LastUsedAt time.Time `json:"last_used_at,omitempty" yaml:"LastUsedAt,omitempty"`
+
+	authorizedAt time.Time
+}
+
+var _ context.User = (*Key)(nil)
+
+// Expired reports whether the key has expired at the time "t".
+func (k *Key) Expired(t time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !t.Before(k.ExpiresAt)
+}
+
+// HasScopes reports whether the key holds all the "scopes".
+// The "*" scope holds them all.
+func (k *Key) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !k.hasScope(scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (k *Key) hasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compare reports whether the "secret" matches the key's hash, in constant time.
+func (k *Key) compare(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSecret(secret)), []byte(k.Hash)) == 1
+}
+
+// GetRaw returns the key itself.
+func (k *Key) GetRaw() (interface{}, error) {
+	return k, nil
+}
+
+// GetAuthorization returns the "API Key" authorization method.
+func (k *Key) GetAuthorization() (string, error) {
+	return authorizationType, nil
+}
+
+// GetAuthorizedAt returns the time that the current request was authenticated.
+func (k *Key) GetAuthorizedAt() (time.Time, error) {
+	return k.authorizedAt, nil
+}
+
+// GetID returns the Owner of the key.
+func (k *Key) GetID() (string, error) {
+	return k.Owner, nil
+}
+
+// GetUsername returns the Owner of the key.
+func (k *Key) GetUsername() (string, error) {
+	return k.Owner, nil
+}
+
+// GetPassword returns ErrNotSupported, the secret of the key is not stored.
+func (k *Key) GetPassword() (string, error) {
+	return "", context.ErrNotSupported
+}
+
+// GetEmail returns ErrNotSupported.
+func (k *Key) GetEmail() (string, error) {
+	return "", context.ErrNotSupported
+}
+
+// GetRoles returns the Scopes of the key.
+func (k *Key) GetRoles() ([]string, error) {
+	return k.Scopes, nil
+}
+
+// GetToken returns the ID of the key.
+func (k *Key) GetToken() ([]byte, error) {
+	return []byte(k.ID), nil
+}
+
+// GetField returns a custom field of the key.
+func (k *Key) GetField(key string) (interface{}, error) {
+	if k.Fields == nil {
+		return nil, context.ErrNotSupported
+	}
+
+	return k.Fields[key], nil
+}
+
+// clone returns a copy of the key, so stores do not share
+// their keys with the requests.
+func (k *Key) clone() *Key {
+	cp := *k
+	cp.Scopes = append([]string(nil), k.Scopes...)
+	if k.Fields != nil {
+		cp.Fields = make(context.Map, len(k.Fields))
+		for key, value := range k.Fields {
+			cp.Fields[key] = value
+		}
+	}
+
+	return &cp
+}
+
+// HashSecret returns the hex-encoded SHA-256 hash of an API key's secret.
+// The secrets are random, a slow password hashing function is not required.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Generate returns a new API key, in the form of "prefix_id_secret", and its Key.
+// The returned API key should be shown to its owner once, it can not be recovered.
+// The Key holds the ID and the hash of the secret and it should be saved to a Store.
+//
+// The "prefix" identifies the application's keys, e.g. on secret scanning,
+// it must not contain an underscore.
+func Generate(prefix, owner string, scopes []string, maxAge time.Duration) (string, *Key, error) {
+	if prefix == "" || strings.IndexByte(prefix, '_') != -1 {
+		return "", nil, errors.New("apikey: prefix is empty or contains an underscore")
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	k := &Key{
+		ID:        hex.EncodeToString(id),
+		Owner:     owner,
+		Scopes:    scopes,
+		CreatedAt: now,
+	}
+
+	if maxAge > 0 {
+		k.ExpiresAt = now.Add(maxAge)
+	}
+
+	secretText := secretEncoding.EncodeToString(secret)
+	k.Hash = HashSecret(secretText)
+
+	return prefix + "_" + k.ID + "_" + secretText, k, nil
+}
+
+// parse splits an API key to its id and secret.
+func parse(prefix, apiKey string) (id, secret string, ok bool) {
+	rest := strings.TrimPrefix(apiKey, prefix+"_")
+	if len(rest) == len(apiKey) {
+		return
+	}
+
+	idx := strings.IndexByte(rest, '_')
+	if idx <= 0 || idx == len(rest)-1 {
+		return
+	}
+
+	return rest[:idx], rest[idx+1:], true
+}
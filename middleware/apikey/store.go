@@ -0,0 +1,249 @@
+package apikey
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotFound is returned by a Store when a key does not exist.
+var ErrNotFound = errors.New("apikey: key not found")
+
+// Store is the interface which keeps the API keys.
+// Implement it to keep the keys on a database.
+//
+// See the `NewMemoryStore` and `NewFileStore` package-level functions.
+type Store interface {
+	// Get returns the key of the given id or ErrNotFound.
+	// The caller may modify the returned key.
+	Get(ctx stdContext.Context, id string) (*Key, error)
+	// Save creates or replaces a key.
+	Save(ctx stdContext.Context, key *Key) error
+	// Delete removes a key.
+	Delete(ctx stdContext.Context, id string) error
+	// Touch sets the last used time of a key.
+	Touch(ctx stdContext.Context, id string, lastUsedAt time.Time) error
+}
+
+// MemoryStore is an in-memory Store.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys map[string]*Key
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns a new in-memory Store of the given keys.
+func NewMemoryStore(keys ...*Key) *MemoryStore {
+	m := &MemoryStore{keys: make(map[string]*Key, len(keys))}
+	for _, k := range keys {
+		m.keys[k.ID] = k.clone()
+	}
+
+	return m
+}
+
+// Get returns a copy of the key of the given id or ErrNotFound.
+func (m *MemoryStore) Get(ctx stdContext.Context, id string) (*Key, error) {
+	m.mu.RLock()
+	k, ok := m.keys[id]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return k.clone(), nil
+}
+
+// Save creates or replaces a key.
+func (m *MemoryStore) Save(ctx stdContext.Context, key *Key) error {
+	m.mu.Lock()
+	m.keys[key.ID] = key.clone()
+	m.mu.Unlock()
+	return nil
+}
+
+// Delete removes a key.
+func (m *MemoryStore) Delete(ctx stdContext.Context, id string) error {
+	m.mu.Lock()
+	delete(m.keys, id)
+	m.mu.Unlock()
+	return nil
+}
+
+// Touch sets the last used time of a key.
+func (m *MemoryStore) Touch(ctx stdContext.Context, id string, lastUsedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k, ok := m.keys[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	k.LastUsedAt = lastUsedAt
+	return nil
+}
+
+// Keys returns a copy of all the keys, sorted by their creation time.
+func (m *MemoryStore) Keys() []*Key {
+	m.mu.RLock()
+	keys := make([]*Key, 0, len(m.keys))
+	for _, k := range m.keys {
+		keys = append(keys, k.clone())
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys
+}
+
+// ReadFile can be used to customize the way the
+// NewFileStore function is loading the filename from.
+// Example of usage: embedded keys.yml file.
+// Defaults to the `os.ReadFile` which reads the file from the physical disk.
+var ReadFile = os.ReadFile
+
+// FileStore is a Store which keeps the keys on a JSON or YAML file.
+// The keys are loaded on initialization and the file is written
+// on each Save and Delete. The last used times are kept in memory
+// and they are written to the file on the next write.
+type FileStore struct {
+	*MemoryStore
+	filename string
+	mu       sync.Mutex // protects the file writes.
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore returns a new Store which loads the keys from the "jsonOrYamlFilename"
+// and writes them back to it on changes. The file may not exist.
+//
+// The keys.yml file looks like the following:
+//   - ID: 3c4d5e6f7a8b9c0d
+//     Hash: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+//     Owner: billing-service
+//     Scopes: [orders:read, invoices:write]
+//     ExpiresAt: 2030-01-01T00:00:00Z
+//
+// See the `Generate` package-level function to create new keys.
+func NewFileStore(jsonOrYamlFilename string) (*FileStore, error) {
+	s := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		filename:    jsonOrYamlFilename,
+	}
+
+	data, err := ReadFile(jsonOrYamlFilename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+
+		return nil, err
+	}
+
+	var keys []*Key
+	if err = unmarshal(jsonOrYamlFilename, data, &keys); err != nil {
+		return nil, fmt.Errorf("apikey: %s: %w", jsonOrYamlFilename, err)
+	}
+
+	for _, k := range keys {
+		if k.ID == "" || k.Hash == "" {
+			return nil, fmt.Errorf("apikey: %s: key id and hash are required", jsonOrYamlFilename)
+		}
+
+		s.keys[k.ID] = k
+	}
+
+	return s, nil
+}
+
+// MustFileStore same as NewFileStore but it panics on error.
+func MustFileStore(jsonOrYamlFilename string) *FileStore {
+	s, err := NewFileStore(jsonOrYamlFilename)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// Save creates or replaces a key and writes the file.
+func (s *FileStore) Save(ctx stdContext.Context, key *Key) error {
+	if err := s.MemoryStore.Save(ctx, key); err != nil {
+		return err
+	}
+
+	return s.write()
+}
+
+// Delete removes a key and writes the file.
+func (s *FileStore) Delete(ctx stdContext.Context, id string) error {
+	if err := s.MemoryStore.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return s.write()
+}
+
+// write writes the keys to a temporary file and renames it to the filename.
+func (s *FileStore) write() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := marshal(s.filename, s.Keys())
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.filename), filepath.Base(s.filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.filename)
+}
+
+func isYAML(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+func unmarshal(filename string, data []byte, v interface{}) error {
+	if isYAML(filename) {
+		return yaml.Unmarshal(data, v)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func marshal(filename string, v interface{}) ([]byte, error) {
+	if isYAML(filename) {
+		return yaml.Marshal(v)
+	}
+
+	return json.MarshalIndent(v, "", "  ")
+}
@@ -0,0 +1,539 @@
+// Package authz implements a role and permission based (RBAC) authorization layer
+// with attribute based rules (ABAC) over the request and its user.
+// It answers "what is this user allowed to do" on top of the authentication middlewares
+// which answer "who is this", e.g. the `auth` and `middleware/basicauth` packages,
+// through the Context.User of the request.
+//
+// Everything that is not granted explicitly is denied.
+package authz
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+func init() {
+	context.SetHandlerName("iris/middleware/authz.*", "iris.authz")
+}
+
+var (
+	// ErrUnauthenticated is returned when the request has no user.
+	ErrUnauthenticated = errors.New("authz: unauthenticated")
+	// ErrForbidden is returned when the user is not granted a permission.
+	ErrForbidden = errors.New("authz: forbidden")
+)
+
+// Wildcard grants all the permissions, e.g. Role("admin", authz.Wildcard).
+// A permission with a ":*" suffix grants all the permissions of that resource,
+// e.g. "orders:*" grants "orders:read" and "orders:write".
+const Wildcard = "*"
+
+type (
+	// RuleFunc reports whether the "user" of the request passes a rule,
+	// e.g. it can compare a path parameter with the user's ID
+	// to check the ownership of a resource.
+	// See the `UserRule` package-level function for typed users.
+	// It must not modify the Policy.
+	RuleFunc func(ctx *context.Context, user context.User) bool
+
+	// RolesFunc returns the roles of the "user".
+	// See the `Policy.SetRolesFunc` method.
+	RolesFunc func(ctx *context.Context, user context.User) ([]string, error)
+
+	// AuditFunc receives the authorization decisions.
+	// See the `Policy.SetAudit` method.
+	AuditFunc func(ctx *context.Context, decision Decision)
+
+	// DenyHandler responds to a denied request.
+	// See the `Policy.SetDenyHandler` method.
+	DenyHandler func(ctx *context.Context, decision Decision)
+)
+
+// Decision holds the result of an authorization check.
+type Decision struct {
+	// Time of the decision.
+	Time time.Time `json:"time" yaml:"Time"`
+	// Permissions that were checked.
+	Permissions []string `json:"permissions,omitempty" yaml:"Permissions"`
+	// Roles that were checked, when the check is by roles,
+	// otherwise the roles of the user.
+	Roles []string `json:"roles,omitempty" yaml:"Roles"`
+	// User of the request, nil when the request is not authenticated.
+	User context.User `json:"-" yaml:"-"`
+	// Allowed reports whether the request is granted.
+	Allowed bool `json:"allowed" yaml:"Allowed"`
+	// Denied is the first permission or role that was not granted, if any.
+	Denied string `json:"denied,omitempty" yaml:"Denied"`
+	// Err is nil when the request is allowed,
+	// otherwise ErrUnauthenticated, ErrForbidden
+	// or the error of the RolesFunc.
+	Err error `json:"-" yaml:"-"`
+}
+
+// Policy holds the roles, their permissions and the rules of an application.
+// Its methods are safe for concurrent use, roles and rules
+// can be modified while the server is running.
+//
+// Initialize it through the `New` package-level function.
+type Policy struct {
+	mu        sync.RWMutex
+	roles     map[string]map[string]struct{} // role -> permissions.
+	parents   map[string][]string            // role -> inherited roles.
+	grants    map[string][]RuleFunc          // permission -> conditional grants.
+	restricts map[string][]RuleFunc          // permission -> conditions.
+
+	rolesFunc   RolesFunc
+	audit       AuditFunc
+	denyHandler DenyHandler
+}
+
+// New returns a new empty Policy, it denies everything
+// until roles and grants are registered.
+//
+// Usage:
+//
+//	policy := authz.New().
+//	  Role("admin", authz.Wildcard).
+//	  Role("clerk", "orders:read", "orders:write").
+//	  Inherit("manager", "clerk").
+//	  Restrict("orders:write", func(ctx iris.Context, user iris.User) bool {
+//	    return ctx.Params().Get("owner") == user.GetID()
+//	  })
+//
+//	app.Post("/orders", basicauth.Default(users), policy.Require("orders:write"), createOrder)
+func New() *Policy {
+	return &Policy{
+		roles:       make(map[string]map[string]struct{}),
+		parents:     make(map[string][]string),
+		grants:      make(map[string][]RuleFunc),
+		restricts:   make(map[string][]RuleFunc),
+		rolesFunc:   userRoles,
+		denyHandler: defaultDenyHandler,
+	}
+}
+
+func userRoles(ctx *context.Context, user context.User) ([]string, error) {
+	roles, err := user.GetRoles()
+	if err == context.ErrNotSupported {
+		return nil, nil
+	}
+
+	return roles, err
+}
+
+func defaultDenyHandler(ctx *context.Context, decision Decision) {
+	if decision.Err == ErrUnauthenticated {
+		ctx.StopWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx.StopWithStatus(http.StatusForbidden)
+}
+
+// Role grants the "permissions" to the role "name".
+// It can be called many times for the same role.
+func (p *Policy) Role(name string, permissions ...string) *Policy {
+	p.mu.Lock()
+	granted, ok := p.roles[name]
+	if !ok {
+		granted = make(map[string]struct{}, len(permissions))
+		p.roles[name] = granted
+	}
+
+	for _, permission := range permissions {
+		granted[permission] = struct{}{}
+	}
+	p.mu.Unlock()
+
+	return p
+}
+
+// Inherit grants the permissions of the "parents" roles to the "role".
+func (p *Policy) Inherit(role string, parents ...string) *Policy {
+	p.mu.Lock()
+	p.parents[role] = append(p.parents[role], parents...)
+	p.mu.Unlock()
+
+	return p
+}
+
+// Grant grants the "permission" to the users that pass the "rule",
+// whatever their roles are, e.g. the owner of a resource.
+func (p *Policy) Grant(permission string, rule RuleFunc) *Policy {
+	p.mu.Lock()
+	p.grants[permission] = append(p.grants[permission], rule)
+	p.mu.Unlock()
+
+	return p
+}
+
+// Restrict adds a condition to the "permission":
+// a user which is granted the permission must pass the "rule" as well,
+// e.g. to limit writes to business hours.
+func (p *Policy) Restrict(permission string, rule RuleFunc) *Policy {
+	p.mu.Lock()
+	p.restricts[permission] = append(p.restricts[permission], rule)
+	p.mu.Unlock()
+
+	return p
+}
+
+// SetRolesFunc sets the function which returns the roles of a user.
+// Defaults to the User.GetRoles method of the request's user,
+// e.g. the `Roles` field of a basicauth user or a `GetRoles() []string`
+// method of an auth's T user.
+func (p *Policy) SetRolesFunc(fn RolesFunc) *Policy {
+	p.mu.Lock()
+	p.rolesFunc = fn
+	p.mu.Unlock()
+
+	return p
+}
+
+// SetAudit sets a function which receives every decision,
+// the allowed and the denied ones, e.g. to write them to an audit log.
+func (p *Policy) SetAudit(fn AuditFunc) *Policy {
+	p.mu.Lock()
+	p.audit = fn
+	p.mu.Unlock()
+
+	return p
+}
+
+// SetDenyHandler sets the handler which responds to the denied requests
+// of the `Require` and `RequireRole` middlewares.
+// Defaults to a handler which responds with 401 Unauthorized
+// to not authenticated requests and 403 Forbidden to the rest.
+func (p *Policy) SetDenyHandler(handler DenyHandler) *Policy {
+	p.mu.Lock()
+	p.denyHandler = handler
+	p.mu.Unlock()
+
+	return p
+}
+
+// Authorize reports whether the user of the request is granted all the "permissions".
+// The decision is passed to the audit function, if any.
+func (p *Policy) Authorize(ctx *context.Context, permissions ...string) Decision {
+	d := Decision{
+		Time:        time.Now(),
+		Permissions: permissions,
+		User:        ctx.User(),
+	}
+
+	p.mu.RLock()
+	d.Allowed, d.Denied, d.Roles, d.Err = p.authorize(ctx, d.User, permissions)
+	audit := p.audit
+	p.mu.RUnlock()
+
+	if audit != nil {
+		audit(ctx, d)
+	}
+
+	return d
+}
+
+func (p *Policy) authorize(ctx *context.Context, user context.User, permissions []string) (bool, string, []string, error) {
+	if user == nil {
+		return false, "", nil, ErrUnauthenticated
+	}
+
+	roles, err := p.rolesFunc(ctx, user)
+	if err != nil {
+		return false, "", nil, err
+	}
+
+	if len(permissions) == 0 {
+		return false, "", roles, ErrForbidden
+	}
+
+	granted := p.permissions(roles)
+
+	for _, permission := range permissions {
+		if !p.granted(ctx, user, granted, permission) {
+			return false, permission, roles, ErrForbidden
+		}
+	}
+
+	return true, "", roles, nil
+}
+
+// permissions returns the permissions of the roles and their parents.
+func (p *Policy) permissions(roles []string) map[string]struct{} {
+	granted := make(map[string]struct{})
+	visited := make(map[string]struct{})
+
+	var walk func(role string)
+	walk = func(role string) {
+		if _, ok := visited[role]; ok {
+			return
+		}
+		visited[role] = struct{}{}
+
+		for permission := range p.roles[role] {
+			granted[permission] = struct{}{}
+		}
+
+		for _, parent := range p.parents[role] {
+			walk(parent)
+		}
+	}
+
+	for _, role := range roles {
+		walk(role)
+	}
+
+	return granted
+}
+
+func (p *Policy) granted(ctx *context.Context, user context.User, granted map[string]struct{}, permission string) bool {
+	ok := matchPermission(granted, permission)
+	if !ok {
+		for _, rule := range p.grants[permission] {
+			if rule(ctx, user) {
+				ok = true
+				break
+			}
+		}
+	}
+
+	if !ok {
+		return false
+	}
+
+	for _, rule := range p.restricts[permission] {
+		if !rule(ctx, user) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchPermission(granted map[string]struct{}, permission string) bool {
+	if _, ok := granted[permission]; ok {
+		return true
+	}
+
+	if _, ok := granted[Wildcard]; ok {
+		return true
+	}
+
+	// "orders:write" is granted by "orders:*".
+	for i := strings.LastIndexByte(permission, ':'); i > 0; i = strings.LastIndexByte(permission[:i], ':') {
+		if _, ok := granted[permission[:i+1]+Wildcard]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Can reports whether the user of the request is granted all the "permissions".
+func (p *Policy) Can(ctx *context.Context, permissions ...string) bool {
+	return p.Authorize(ctx, permissions...).Allowed
+}
+
+// HasRole reports whether the user of the request has one of the "roles",
+// directly or through inheritance.
+func (p *Policy) HasRole(ctx *context.Context, roles ...string) bool {
+	return p.authorizeRole(ctx, roles).Allowed
+}
+
+func (p *Policy) authorizeRole(ctx *context.Context, roles []string) Decision {
+	d := Decision{
+		Time:  time.Now(),
+		Roles: roles,
+		User:  ctx.User(),
+	}
+
+	p.mu.RLock()
+	d.Allowed, d.Err = p.hasRole(ctx, d.User, roles)
+	if !d.Allowed && d.Err == ErrForbidden {
+		d.Denied = strings.Join(roles, ",")
+	}
+	audit := p.audit
+	p.mu.RUnlock()
+
+	if audit != nil {
+		audit(ctx, d)
+	}
+
+	return d
+}
+
+func (p *Policy) hasRole(ctx *context.Context, user context.User, roles []string) (bool, error) {
+	if user == nil {
+		return false, ErrUnauthenticated
+	}
+
+	userRoles, err := p.rolesFunc(ctx, user)
+	if err != nil {
+		return false, err
+	}
+
+	visited := make(map[string]struct{})
+	var walk func(role string)
+	walk = func(role string) {
+		if _, ok := visited[role]; ok {
+			return
+		}
+		visited[role] = struct{}{}
+
+		for _, parent := range p.parents[role] {
+			walk(parent)
+		}
+	}
+
+	for _, role := range userRoles {
+		walk(role)
+	}
+
+	for _, role := range roles {
+		if _, ok := visited[role]; ok {
+			return true, nil
+		}
+	}
+
+	return false, ErrForbidden
+}
+
+// Require returns a middleware which allows the requests
+// of users that are granted all the "permissions"
+// and fires the deny handler for the rest.
+//
+// It can be registered on routes, parties and MVC controller methods, e.g.
+//
+//	app.Post("/orders", policy.Require("orders:write"), handler)
+//	b.Handle("POST", "/", "Create", policy.Require("orders:write"))
+func (p *Policy) Require(permissions ...string) context.Handler {
+	return func(ctx *context.Context) {
+		if d := p.Authorize(ctx, permissions...); !d.Allowed {
+			p.deny(ctx, d)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireRole returns a middleware which allows the requests
+// of users that have one of the "roles" and fires the deny handler for the rest.
+func (p *Policy) RequireRole(roles ...string) context.Handler {
+	return func(ctx *context.Context) {
+		if d := p.authorizeRole(ctx, roles); !d.Allowed {
+			p.deny(ctx, d)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func (p *Policy) deny(ctx *context.Context, d Decision) {
+	p.mu.RLock()
+	denyHandler := p.denyHandler
+	p.mu.RUnlock()
+
+	denyHandler(ctx, d)
+}
+
+// Guard is the authorization of a request, it's the dependency
+// of hero functions and MVC controllers which check permissions inside their body.
+// See the `Policy.Guard` method.
+type Guard struct {
+	ctx    *context.Context
+	policy *Policy
+}
+
+// Guard returns the Guard of the request.
+// Register it as a dependency to accept a *Guard input argument
+// on hero functions and MVC controllers, e.g.
+//
+//	app.RegisterDependency(policy.Guard)
+//	app.ConfigureContainer().Post("/orders", func(g *authz.Guard, o Order) error {
+//	  if err := g.Authorize("orders:write"); err != nil {
+//	    return err
+//	  }
+//	  [...]
+//	})
+func (p *Policy) Guard(ctx *context.Context) *Guard {
+	return &Guard{ctx: ctx, policy: p}
+}
+
+// Can reports whether the user of the request is granted all the "permissions".
+func (g *Guard) Can(permissions ...string) bool {
+	return g.policy.Can(g.ctx, permissions...)
+}
+
+// HasRole reports whether the user of the request has one of the "roles".
+func (g *Guard) HasRole(roles ...string) bool {
+	return g.policy.HasRole(g.ctx, roles...)
+}
+
+// Authorize returns a nil error if the user of the request is granted all the "permissions".
+// Otherwise it sets the 401 or 403 status code and returns ErrUnauthenticated or ErrForbidden,
+// so a hero function or an MVC controller's method can return it as it's.
+func (g *Guard) Authorize(permissions ...string) error {
+	d := g.policy.Authorize(g.ctx, permissions...)
+	if d.Allowed {
+		return nil
+	}
+
+	if d.Err == ErrUnauthenticated {
+		g.ctx.StatusCode(http.StatusUnauthorized)
+	} else {
+		g.ctx.StatusCode(http.StatusForbidden)
+	}
+
+	return d.Err
+}
+
+// UserRule returns a RuleFunc which passes the user of the request as T,
+// e.g. the user of an auth.Auth[T] or a custom basicauth user.
+// The rule fails when the user is not a T.
+func UserRule[T any](rule func(ctx *context.Context, user T) bool) RuleFunc {
+	return func(ctx *context.Context, user context.User) bool {
+		t, ok := userAs[T](user)
+		if !ok {
+			return false
+		}
+
+		return rule(ctx, t)
+	}
+}
+
+// UserRoles returns a RolesFunc which passes the user of the request as T
+// to the "roles" function. See the `Policy.SetRolesFunc` method.
+func UserRoles[T any](roles func(user T) []string) RolesFunc {
+	return func(ctx *context.Context, user context.User) ([]string, error) {
+		t, ok := userAs[T](user)
+		if !ok {
+			return nil, nil
+		}
+
+		return roles(t), nil
+	}
+}
+
+// userAs returns the user as T, the user itself or its raw value
+// which is set through Context.SetUser.
+func userAs[T any](user context.User) (T, bool) {
+	if t, ok := user.(T); ok {
+		return t, true
+	}
+
+	var t T
+	raw, err := user.GetRaw()
+	if err != nil {
+		return t, false
+	}
+
+	t, ok := raw.(T)
+	return t, ok
+}
@@ -0,0 +1,126 @@
+package authz_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/middleware/authz"
+	"github.com/kataras/iris/v12/middleware/basicauth"
+)
+
+type testUser struct {
+	ID    string
+	Roles []string
+}
+
+func (u testUser) GetID() string {
+	return u.ID
+}
+
+func (u testUser) GetRoles() []string {
+	return u.Roles
+}
+
+func TestPolicy(t *testing.T) {
+	var denied []authz.Decision
+	policy := authz.New().
+		Role("admin", authz.Wildcard).
+		Role("clerk", "orders:read", "orders:write").
+		Role("auditor", "reports:*").
+		Inherit("manager", "clerk").
+		Grant("profiles:write", func(ctx iris.Context, user iris.User) bool {
+			id, _ := user.GetID()
+			return id != "" && ctx.Params().Get("id") == id
+		}).
+		Restrict("orders:write", func(ctx iris.Context, user iris.User) bool {
+			return ctx.GetHeader("X-Readonly") == ""
+		}).
+		SetAudit(func(ctx iris.Context, d authz.Decision) {
+			if !d.Allowed {
+				denied = append(denied, d)
+			}
+		})
+
+	app := iris.New()
+
+	users := []*iris.SimpleUser{
+		{Username: "admin", Password: "admin", Roles: []string{"admin"}},
+		{Username: "clerk", Password: "clerk", Roles: []string{"clerk"}},
+		{Username: "manager", Password: "manager", Roles: []string{"manager"}},
+		{Username: "guest", Password: "guest"},
+	}
+
+	basic := app.Party("/basic", basicauth.Default(users))
+	basic.Post("/orders", policy.Require("orders:write"), writeOK)
+	basic.Get("/reports", policy.Require("reports:monthly"), writeOK)
+	basic.Get("/managers", policy.RequireRole("manager"), writeOK)
+
+	app.Post("/anonymous", policy.Require("orders:write"), writeOK)
+
+	// A typed user, e.g. an auth.Auth[T] user, and a hero dependency.
+	typed := app.Party("/typed", func(ctx iris.Context) {
+		ctx.SetUser(testUser{ID: ctx.GetHeader("X-User"), Roles: []string{ctx.GetHeader("X-Role")}})
+		ctx.Next()
+	})
+	typed.RegisterDependency(policy.Guard)
+	typed.ConfigureContainer().Put("/profiles/{id}", func(g *authz.Guard) (string, error) {
+		if err := g.Authorize("profiles:write"); err != nil {
+			return "", err
+		}
+
+		return "OK", nil
+	})
+
+	e := httptest.New(t, app)
+
+	e.POST("/anonymous").Expect().Status(iris.StatusUnauthorized)
+
+	e.POST("/basic/orders").WithBasicAuth("admin", "admin").Expect().Status(iris.StatusOK).Body().IsEqual("OK")
+	e.POST("/basic/orders").WithBasicAuth("clerk", "clerk").Expect().Status(iris.StatusOK)
+	e.POST("/basic/orders").WithBasicAuth("manager", "manager").Expect().Status(iris.StatusOK)
+	e.POST("/basic/orders").WithBasicAuth("guest", "guest").Expect().Status(iris.StatusForbidden)
+	e.POST("/basic/orders").WithBasicAuth("clerk", "clerk").WithHeader("X-Readonly", "1").
+		Expect().Status(iris.StatusForbidden)
+
+	e.GET("/basic/reports").WithBasicAuth("admin", "admin").Expect().Status(iris.StatusOK)
+	e.GET("/basic/reports").WithBasicAuth("clerk", "clerk").Expect().Status(iris.StatusForbidden)
+
+	e.GET("/basic/managers").WithBasicAuth("manager", "manager").Expect().Status(iris.StatusOK)
+	e.GET("/basic/managers").WithBasicAuth("clerk", "clerk").Expect().Status(iris.StatusForbidden)
+
+	e.PUT("/typed/profiles/42").WithHeader("X-User", "42").Expect().Status(iris.StatusOK).Body().IsEqual("OK")
+	e.PUT("/typed/profiles/42").WithHeader("X-User", "43").Expect().Status(iris.StatusForbidden)
+	e.PUT("/typed/profiles/42").WithHeader("X-User", "43").WithHeader("X-Role", "admin").Expect().Status(iris.StatusOK)
+
+	if expected, got := 6, len(denied); expected != got {
+		t.Fatalf("expected %d denied decisions but got %d", expected, got)
+	}
+
+	if expected, got := "orders:write", denied[1].Denied; expected != got {
+		t.Fatalf("expected denied permission: %q but got: %q", expected, got)
+	}
+}
+
+func TestUserRule(t *testing.T) {
+	policy := authz.New().
+		SetRolesFunc(authz.UserRoles(func(u testUser) []string { return u.Roles })).
+		Role("clerk", "orders:write").
+		Restrict("orders:write", authz.UserRule(func(ctx iris.Context, u testUser) bool {
+			return u.ID != "blocked"
+		}))
+
+	app := iris.New()
+	app.Post("/orders/{user}", func(ctx iris.Context) {
+		ctx.SetUser(testUser{ID: ctx.Params().Get("user"), Roles: []string{"clerk"}})
+		ctx.Next()
+	}, policy.Require("orders:write"), writeOK)
+
+	e := httptest.New(t, app)
+	e.POST("/orders/kataras").Expect().Status(iris.StatusOK)
+	e.POST("/orders/blocked").Expect().Status(iris.StatusForbidden)
+}
+
+func writeOK(ctx iris.Context) {
+	ctx.WriteString("OK")
+}
@@ -0,0 +1,146 @@
+package concurrency
+
+import (
+	"math"
+	"time"
+)
+
+// Adaptive adjusts the limit of a Limiter based on the observed latency.
+// Its Update method is called, under a lock, each time a request is completed.
+type Adaptive interface {
+	// Update returns the new limit based on the current "limit", the number of
+	// "inFlight" requests (the completed one included) and the "latency"
+	// of the completed request which "failed" with a server error or not.
+	Update(limit float64, inFlight int, latency time.Duration, failed bool) float64
+}
+
+// AIMDOptions holds the configuration of the AIMD adaptive limit.
+type AIMDOptions struct {
+	// MinLimit and MaxLimit are the bounds of the limit.
+	// Default to 1 and 1000.
+	MinLimit int
+	MaxLimit int
+	// Latency is the threshold which a completed request is considered slow.
+	// Defaults to 1 second.
+	Latency time.Duration
+	// BackoffRatio multiplies the limit on a slow or failed request.
+	// Defaults to 0.9.
+	BackoffRatio float64
+}
+
+type aimd struct {
+	opts AIMDOptions
+}
+
+// AIMD returns an additive-increase/multiplicative-decrease adaptive limit.
+// The limit is increased by one for each window of successful requests
+// which are faster than the configured latency and it's multiplied by
+// the backoff ratio on a slow or failed request.
+func AIMD(opts AIMDOptions) Adaptive {
+	if opts.MinLimit <= 0 {
+		opts.MinLimit = 1
+	}
+
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = 1000
+	}
+
+	if opts.Latency <= 0 {
+		opts.Latency = time.Second
+	}
+
+	if opts.BackoffRatio <= 0 || opts.BackoffRatio >= 1 {
+		opts.BackoffRatio = 0.9
+	}
+
+	return &aimd{opts: opts}
+}
+
+func (a *aimd) Update(limit float64, inFlight int, latency time.Duration, failed bool) float64 {
+	if failed || latency > a.opts.Latency {
+		limit *= a.opts.BackoffRatio
+	} else if float64(inFlight) >= limit/2 {
+		// increase only when the limit is actually used.
+		limit += 1 / limit
+	}
+
+	return clamp(limit, a.opts.MinLimit, a.opts.MaxLimit)
+}
+
+// GradientOptions holds the configuration of the gradient adaptive limit.
+type GradientOptions struct {
+	// MinLimit and MaxLimit are the bounds of the limit.
+	// Default to 1 and 1000.
+	MinLimit int
+	MaxLimit int
+	// Smoothing is the weight, between 0 and 1, of a new limit over the current one.
+	// Defaults to 0.2.
+	Smoothing float64
+	// Tolerance is the ratio of the latency over the minimum latency
+	// which is not considered as a queueing delay. Defaults to 1.5.
+	Tolerance float64
+	// MinLatencyWindow is the period which the minimum observed latency is reset,
+	// so the limit can follow changes of the system. Defaults to 1 minute.
+	MinLatencyWindow time.Duration
+}
+
+type gradient struct {
+	opts GradientOptions
+
+	minLatency      time.Duration
+	minLatencyReset time.Time
+}
+
+// Gradient returns an adaptive limit which compares the latency of each request
+// with the minimum observed latency: the limit shrinks as the latency grows
+// because of queueing and it grows, by the square root of the limit, while the latency is low.
+func Gradient(opts GradientOptions) Adaptive {
+	if opts.MinLimit <= 0 {
+		opts.MinLimit = 1
+	}
+
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = 1000
+	}
+
+	if opts.Smoothing <= 0 || opts.Smoothing > 1 {
+		opts.Smoothing = 0.2
+	}
+
+	if opts.Tolerance < 1 {
+		opts.Tolerance = 1.5
+	}
+
+	if opts.MinLatencyWindow <= 0 {
+		opts.MinLatencyWindow = time.Minute
+	}
+
+	return &gradient{opts: opts}
+}
+
+func (g *gradient) Update(limit float64, inFlight int, latency time.Duration, failed bool) float64 {
+	now := time.Now()
+	if g.minLatency == 0 || latency < g.minLatency || now.After(g.minLatencyReset) {
+		g.minLatency = latency
+		g.minLatencyReset = now.Add(g.opts.MinLatencyWindow)
+	}
+
+	if latency <= 0 {
+		return limit
+	}
+
+	ratio := g.opts.Tolerance * float64(g.minLatency) / float64(latency)
+	if failed {
+		ratio = 0.5
+	}
+	ratio = math.Max(0.5, math.Min(1, ratio))
+
+	newLimit := limit*ratio + math.Sqrt(limit)
+	limit = limit*(1-g.opts.Smoothing) + newLimit*g.opts.Smoothing
+
+	return clamp(limit, g.opts.MinLimit, g.opts.MaxLimit)
+}
+
+func clamp(limit float64, min, max int) float64 {
+	return math.Max(float64(min), math.Min(float64(max), limit))
+}
@@ -0,0 +1,254 @@
+// Package concurrency implements a concurrency limiter and load-shedding middleware.
+// It caps the in-flight requests of a route or a party, excess requests
+// wait in a bounded queue and are rejected when the queue is full or their wait times out.
+// See the `middleware/rate` package for request-rate limiting.
+package concurrency
+
+import (
+	"container/list"
+	stdContext "context"
+	"errors"
+	"expvar"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+func init() {
+	context.SetHandlerName("iris/middleware/concurrency.*", "iris.concurrency")
+}
+
+// Options holds the configuration of a concurrency Limiter.
+type Options struct {
+	// Limit is the maximum number of in-flight requests.
+	// When Adaptive is set it is the initial limit.
+	// Defaults to 100.
+	Limit int
+	// QueueSize is the maximum number of requests that wait for an in-flight request to complete.
+	// Zero means no queue, excess requests are rejected immediately.
+	QueueSize int
+	// QueueTimeout is the maximum time that a request waits in the queue.
+	// Zero means no timeout, the request waits until a slot is available
+	// or the client cancels the request.
+	QueueTimeout time.Duration
+	// RejectHandler is fired when a request is rejected.
+	// Defaults to a handler which responds with 503 Service Unavailable.
+	RejectHandler context.Handler
+	// Adaptive, if not nil, adjusts the limit based on the observed latency.
+	// See `AIMD` and `Gradient` package-level functions.
+	Adaptive Adaptive
+}
+
+// Stats holds the statistics of a Limiter.
+// Canceled counts the queued requests which their client has gone,
+// they are not counted as rejected.
+type Stats struct {
+	Limit    int    `json:"limit" yaml:"Limit"`
+	InFlight int    `json:"in_flight" yaml:"InFlight"`
+	Queued   int    `json:"queued" yaml:"Queued"`
+	Rejected uint64 `json:"rejected" yaml:"Rejected"`
+	TimedOut uint64 `json:"timed_out" yaml:"TimedOut"`
+	Canceled uint64 `json:"canceled" yaml:"Canceled"`
+}
+
+// Limiter caps the in-flight requests.
+// Initialize with the `New` package-level function
+// and register its `Handler` method as a middleware.
+type Limiter struct {
+	opts Options
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	queue    *list.List // of chan struct{}.
+
+	rejected uint64
+	timedOut uint64
+	canceled uint64
+}
+
+// The process-wide metrics of all limiters, see `Totals`.
+var (
+	metricInFlight = expvar.NewInt("concurrency_in_flight")
+	metricQueued   = expvar.NewInt("concurrency_queued")
+	metricRejected = expvar.NewInt("concurrency_rejected")
+)
+
+// Totals returns the sum of the statistics of all limiters of the current process.
+// The "Limit" field is always zero.
+// Each value is also published as an expvar metric:
+// * concurrency_in_flight
+// * concurrency_queued
+// * concurrency_rejected
+func Totals() Stats {
+	return Stats{
+		InFlight: int(metricInFlight.Value()),
+		Queued:   int(metricQueued.Value()),
+		Rejected: uint64(metricRejected.Value()),
+	}
+}
+
+// DefaultRejectHandler is the default `Options.RejectHandler`.
+// It responds with 503 Service Unavailable and a "Retry-After: 1" header.
+func DefaultRejectHandler(ctx *context.Context) {
+	ctx.Header("Retry-After", "1")
+	ctx.StopWithStatus(http.StatusServiceUnavailable)
+}
+
+// New returns a new concurrency Limiter.
+//
+// Usage:
+// limiter := concurrency.New(concurrency.Options{Limit: 50, QueueSize: 100, QueueTimeout: 2 * time.Second})
+// api.Use(limiter.Handler)
+func New(opts Options) *Limiter {
+	if opts.Limit <= 0 {
+		opts.Limit = 100
+	}
+
+	if opts.QueueSize < 0 {
+		opts.QueueSize = 0
+	}
+
+	if opts.RejectHandler == nil {
+		opts.RejectHandler = DefaultRejectHandler
+	}
+
+	return &Limiter{
+		opts:  opts,
+		limit: float64(opts.Limit),
+		queue: list.New(),
+	}
+}
+
+// Limit is a shortcut of New(Options{Limit: limit, QueueSize: queueSize, QueueTimeout: queueTimeout}).Handler.
+func Limit(limit, queueSize int, queueTimeout time.Duration) context.Handler {
+	return New(Options{Limit: limit, QueueSize: queueSize, QueueTimeout: queueTimeout}).Handler
+}
+
+// Handler is the middleware which limits the in-flight requests.
+func (l *Limiter) Handler(ctx *context.Context) {
+	if ok, canceled := l.acquire(ctx); !ok {
+		if !canceled { // there is no one to respond to otherwise.
+			l.opts.RejectHandler(ctx)
+		}
+		return
+	}
+
+	start := time.Now()
+	defer func() {
+		l.release(time.Since(start), ctx.GetStatusCode() >= http.StatusInternalServerError)
+	}()
+
+	ctx.Next()
+}
+
+// Stats returns the current statistics of the limiter.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	stats := Stats{
+		Limit:    int(l.limit),
+		InFlight: l.inFlight,
+		Queued:   l.queue.Len(),
+	}
+	l.mu.Unlock()
+
+	stats.Rejected = atomic.LoadUint64(&l.rejected)
+	stats.TimedOut = atomic.LoadUint64(&l.timedOut)
+	stats.Canceled = atomic.LoadUint64(&l.canceled)
+	return stats
+}
+
+// acquire reports whether the request can be executed,
+// it waits in the queue if the limit is reached.
+// It reports whether the client canceled the request while it was waiting too.
+func (l *Limiter) acquire(ctx *context.Context) (ok bool, canceled bool) {
+	l.mu.Lock()
+	if l.inFlight < int(l.limit) && l.queue.Len() == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		metricInFlight.Add(1)
+		return true, false
+	}
+
+	if l.queue.Len() >= l.opts.QueueSize {
+		l.mu.Unlock()
+		l.reject()
+		return false, false
+	}
+
+	ready := make(chan struct{})
+	el := l.queue.PushBack(ready)
+	l.mu.Unlock()
+	metricQueued.Add(1)
+
+	var timeout <-chan time.Time
+	if l.opts.QueueTimeout > 0 {
+		timer := time.NewTimer(l.opts.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	reqCtx := ctx.Request().Context()
+	select {
+	case <-ready:
+		// release passed its slot to us.
+		return true, false
+	case <-timeout:
+	case <-reqCtx.Done():
+		canceled = errors.Is(reqCtx.Err(), stdContext.Canceled)
+	}
+
+	l.mu.Lock()
+	select {
+	case <-ready:
+		// a slot was passed to us at the same time.
+		l.mu.Unlock()
+		return true, false
+	default:
+		l.queue.Remove(el)
+	}
+	l.mu.Unlock()
+
+	metricQueued.Add(-1)
+	if canceled {
+		atomic.AddUint64(&l.canceled, 1)
+		return false, true
+	}
+
+	atomic.AddUint64(&l.timedOut, 1)
+	l.reject()
+	return false, false
+}
+
+func (l *Limiter) reject() {
+	atomic.AddUint64(&l.rejected, 1)
+	metricRejected.Add(1)
+}
+
+// release frees the slot of a completed request, adjusts the limit
+// and passes the free slots to the queued requests.
+func (l *Limiter) release(latency time.Duration, failed bool) {
+	l.mu.Lock()
+	if l.opts.Adaptive != nil {
+		l.limit = l.opts.Adaptive.Update(l.limit, l.inFlight, latency, failed)
+		if l.limit < 1 {
+			l.limit = 1
+		}
+	}
+
+	l.inFlight--
+	passed := 0
+	for l.inFlight < int(l.limit) && l.queue.Len() > 0 {
+		ready := l.queue.Remove(l.queue.Front()).(chan struct{})
+		close(ready)
+		l.inFlight++
+		passed++
+	}
+	l.mu.Unlock()
+
+	metricInFlight.Add(int64(passed - 1))
+	metricQueued.Add(int64(-passed))
+}
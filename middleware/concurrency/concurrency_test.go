@@ -0,0 +1,152 @@
+package concurrency_test
+
+import (
+	stdContext "context"
+	"net/http"
+	stdhttptest "net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/middleware/concurrency"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := concurrency.New(concurrency.Options{Limit: 1, QueueSize: 1, QueueTimeout: 3 * time.Second})
+
+	started := make(chan struct{}, 2)
+	unblock := make(chan struct{})
+
+	app := iris.New()
+	app.Get("/", limiter.Handler, func(ctx iris.Context) {
+		started <- struct{}{}
+		<-unblock
+		ctx.WriteString("OK")
+	})
+
+	e := httptest.New(t, app)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual("OK")
+		}()
+	}
+
+	<-started // the first one is in-flight.
+	waitFor(t, func() bool { return limiter.Stats().Queued == 1 })
+
+	// the queue is full.
+	e.GET("/").Expect().Status(http.StatusServiceUnavailable).Header("Retry-After").IsEqual("1")
+
+	close(unblock)
+	wg.Wait()
+
+	stats := limiter.Stats()
+	if stats.InFlight != 0 || stats.Queued != 0 || stats.Rejected != 1 || stats.TimedOut != 0 {
+		t.Fatalf("unexpected stats: %#+v", stats)
+	}
+}
+
+func TestLimiterQueueTimeout(t *testing.T) {
+	limiter := concurrency.New(concurrency.Options{Limit: 1, QueueSize: 1, QueueTimeout: 50 * time.Millisecond})
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+
+	app := iris.New()
+	app.Get("/", limiter.Handler, func(ctx iris.Context) {
+		close(started)
+		<-unblock
+	})
+
+	e := httptest.New(t, app)
+
+	done := make(chan struct{})
+	go func() {
+		e.GET("/").Expect().Status(http.StatusOK)
+		close(done)
+	}()
+
+	<-started
+	e.GET("/").Expect().Status(http.StatusServiceUnavailable)
+	close(unblock)
+	<-done
+
+	if stats := limiter.Stats(); stats.TimedOut != 1 || stats.Rejected != 1 {
+		t.Fatalf("unexpected stats: %#+v", stats)
+	}
+}
+
+func TestLimiterQueueCanceled(t *testing.T) {
+	limiter := concurrency.New(concurrency.Options{Limit: 1, QueueSize: 1})
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+
+	app := iris.New()
+	app.Get("/", limiter.Handler, func(ctx iris.Context) {
+		close(started)
+		<-unblock
+	})
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		app.ServeHTTP(stdhttptest.NewRecorder(), stdhttptest.NewRequest(http.MethodGet, "/", nil))
+		close(done)
+	}()
+
+	<-started
+	ctx, cancel := stdContext.WithCancel(stdContext.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	// the client has gone while it was waiting in the queue.
+	rec := stdhttptest.NewRecorder()
+	app.ServeHTTP(rec, stdhttptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	close(unblock)
+	<-done
+
+	if rec.Code == http.StatusServiceUnavailable {
+		t.Fatalf("expected the canceled request not to be rejected")
+	}
+
+	if stats := limiter.Stats(); stats.Canceled != 1 || stats.TimedOut != 0 || stats.Rejected != 0 {
+		t.Fatalf("unexpected stats: %#+v", stats)
+	}
+}
+
+func TestAIMD(t *testing.T) {
+	a := concurrency.AIMD(concurrency.AIMDOptions{MinLimit: 2, MaxLimit: 10, Latency: time.Second})
+
+	if limit := a.Update(10, 10, 2*time.Second, false); limit != 9 {
+		t.Fatalf("expected limit to decrease to 9 but got %f", limit)
+	}
+
+	if limit := a.Update(4, 4, time.Millisecond, false); limit != 4.25 {
+		t.Fatalf("expected limit to increase to 4.25 but got %f", limit)
+	}
+
+	if limit := a.Update(2, 2, 0, true); limit != 2 {
+		t.Fatalf("expected limit to stay at the minimum 2 but got %f", limit)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("condition was not met")
+}
@@ -0,0 +1,390 @@
+package jwt
+
+import (
+	stdContext "context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/jwt"
+)
+
+type (
+	// JWK is a JSON Web Key of a public key, as specified in RFC 7517.
+	// RSA, EC (P-256, P-384, P-521) and OKP (Ed25519) keys are supported.
+	JWK struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid,omitempty"`
+		Use string `json:"use,omitempty"`
+		Alg string `json:"alg,omitempty"`
+		// RSA public key fields.
+		N string `json:"n,omitempty"`
+		E string `json:"e,omitempty"`
+		// EC and OKP public key fields.
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+		Y   string `json:"y,omitempty"`
+	}
+
+	// JWKS is a JSON Web Key Set.
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+// AlgByName returns the signature algorithm of the given "name", e.g. "RS256".
+func AlgByName(name string) (Alg, bool) {
+	for _, alg := range []Alg{EdDSA, HS256, HS384, HS512, RS256, RS384, RS512, ES256, ES384, ES512, PS256, PS384, PS512} {
+		if alg.Name() == name {
+			return alg, true
+		}
+	}
+
+	return nil, false
+}
+
+// Algorithm returns the signature algorithm of the key.
+// If the "alg" field is empty then it is resolved by the key type and curve.
+// An algorithm which does not match the key type, e.g. "HS256" on an "RSA" key
+// or "ES384" on a "P-256" curve, is rejected, so a key set can only hold
+// public keys of asymmetric algorithms.
+func (k JWK) Algorithm() (Alg, error) {
+	name := k.Alg
+	if name == "" {
+		switch k.Kty {
+		case "RSA":
+			name = "RS256"
+		case "EC":
+			switch k.Crv {
+			case "P-256":
+				name = "ES256"
+			case "P-384":
+				name = "ES384"
+			case "P-521":
+				name = "ES512"
+			}
+		case "OKP":
+			name = "EdDSA"
+		}
+	}
+
+	alg, ok := AlgByName(name)
+	if !ok {
+		return nil, fmt.Errorf("jwk: %q: unsupported algorithm: %q", k.Kid, name)
+	}
+
+	if !k.matches(name) {
+		return nil, fmt.Errorf("jwk: %q: algorithm %q does not match the key type %q", k.Kid, name, k.Kty)
+	}
+
+	return alg, nil
+}
+
+// matches reports whether the algorithm "name" can be used with the key type and curve.
+func (k JWK) matches(name string) bool {
+	switch k.Kty {
+	case "RSA":
+		return strings.HasPrefix(name, "RS") || strings.HasPrefix(name, "PS")
+	case "EC":
+		switch k.Crv {
+		case "P-256":
+			return name == "ES256"
+		case "P-384":
+			return name == "ES384"
+		case "P-521":
+			return name == "ES512"
+		}
+	case "OKP":
+		return name == "EdDSA"
+	}
+
+	return false
+}
+
+// PublicKey decodes the key to a Go crypto public key:
+// *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey.
+func (k JWK) PublicKey() (jwt.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: %q: n: %w", k.Kid, err)
+		}
+
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: %q: e: %w", k.Kid, err)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk: %q: unsupported curve: %q", k.Kid, k.Crv)
+		}
+
+		x, err := decodeBase64URLInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: %q: x: %w", k.Kid, err)
+		}
+
+		y, err := decodeBase64URLInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: %q: y: %w", k.Kid, err)
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("jwk: %q: point is not on curve %s", k.Kid, k.Crv)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwk: %q: unsupported curve: %q", k.Kid, k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: %q: x: %w", k.Kid, err)
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk: %q: invalid Ed25519 public key size", k.Kid)
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("jwk: %q: unsupported key type: %q", k.Kid, k.Kty)
+	}
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// defaultJWKSClient fetches the keys of a RemoteJWKS without a Client.
+var defaultJWKSClient = &http.Client{Timeout: 15 * time.Second}
+
+// Load decodes the signature keys of the set, keys which are not
+// meant for signatures ("use" is not empty or "sig") are skipped.
+// The result can be used to verify tokens through its `ValidateHeader` method.
+func (set JWKS) Load() (jwt.Keys, error) {
+	keys := make(jwt.Keys, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		alg, err := k.Algorithm()
+		if err != nil {
+			return nil, err
+		}
+
+		pub, err := k.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+
+		keys.Register(alg, k.Kid, pub, nil)
+	}
+
+	return keys, nil
+}
+
+// RemoteJWKS fetches and caches the keys of a remote JSON Web Key Set,
+// e.g. the "jwks_uri" of an OpenID Connect issuer.
+// The keys are fetched again when the cache expires
+// or when a token is signed by an unknown key id, at most once per `MinRefreshInterval`.
+// Concurrent requests of unknown key ids wait for a single fetch.
+//
+// Initialize it through the `NewRemoteJWKS` package-level function.
+// Its `ValidateHeader` method can be passed to the `jwt.VerifyWithHeaderValidator` function.
+type RemoteJWKS struct {
+	// URL of the JSON Web Key Set.
+	URL string
+	// Client is the HTTP Client which fetches the keys.
+	// Defaults to a client with a 15 seconds timeout.
+	Client *http.Client
+	// RefreshInterval is the maximum age of the cached keys.
+	// Defaults to 1 hour.
+	RefreshInterval time.Duration
+	// MinRefreshInterval is the minimum time between two fetches
+	// caused by an unknown key id.
+	// Defaults to 1 minute.
+	MinRefreshInterval time.Duration
+
+	fetchMu sync.Mutex // one fetch at a time.
+
+	mu        sync.RWMutex
+	keys      jwt.Keys
+	fetchedAt time.Time
+}
+
+// NewRemoteJWKS returns a new RemoteJWKS for the given JSON Web Key Set URL.
+// The keys are fetched on first use, call its `Fetch` method to fetch them on initialization.
+func NewRemoteJWKS(url string) *RemoteJWKS {
+	return &RemoteJWKS{
+		URL:                url,
+		RefreshInterval:    time.Hour,
+		MinRefreshInterval: time.Minute,
+	}
+}
+
+// Fetch fetches and caches the keys.
+func (r *RemoteJWKS) Fetch(ctx stdContext.Context) error {
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+
+	return r.fetch(ctx)
+}
+
+func (r *RemoteJWKS) fetch(ctx stdContext.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := r.Client
+	if client == nil {
+		client = defaultJWKSClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks: %s: unexpected status code: %d", r.URL, resp.StatusCode)
+	}
+
+	var set JWKS
+	if err = json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+
+	keys, err := set.Load()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.fetchedAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// Key returns the cached key of the given "kid".
+// The keys are fetched when the cache is expired or when the "kid" is unknown.
+func (r *RemoteJWKS) Key(ctx stdContext.Context, kid string) (*jwt.Key, error) {
+	r.mu.RLock()
+	key, ok := r.keys[kid]
+	fetchedAt := r.fetchedAt
+	r.mu.RUnlock()
+
+	age := time.Since(fetchedAt)
+	fetched := !fetchedAt.IsZero()
+
+	refreshInterval := r.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = time.Hour
+	}
+
+	if ok && age < refreshInterval {
+		return key, nil
+	}
+
+	if !ok && fetched && age < r.MinRefreshInterval {
+		// do not flood the server with requests of unknown keys.
+		return nil, jwt.ErrUnknownKid
+	}
+
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+
+	// the keys may have been fetched by another request while this one was waiting.
+	r.mu.RLock()
+	refetched := r.fetchedAt.After(fetchedAt)
+	r.mu.RUnlock()
+
+	if !refetched {
+		if err := r.fetch(ctx); err != nil {
+			if ok {
+				// keep using the cached key.
+				return key, nil
+			}
+
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	key, ok = r.keys[kid]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, jwt.ErrUnknownKid
+	}
+
+	return key, nil
+}
+
+// ValidateHeader completes the jwt.HeaderValidator function type.
+// It resolves the public key of the token by its "kid" header field.
+func (r *RemoteJWKS) ValidateHeader(alg string, headerDecoded []byte) (Alg, jwt.PublicKey, jwt.InjectFunc, error) {
+	var h jwt.HeaderWithKid
+	if err := json.Unmarshal(headerDecoded, &h); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if h.Kid == "" {
+		return nil, nil, nil, jwt.ErrEmptyKid
+	}
+
+	key, err := r.Key(stdContext.Background(), h.Kid)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if h.Alg != key.Alg.Name() || (alg != "" && alg != h.Alg) {
+		return nil, nil, nil, ErrTokenAlg
+	}
+
+	return key.Alg, key.Public, nil, nil
+}
+
+// Verifier returns a new Verifier which verifies the tokens through the remote keys.
+// See the `NewRemoteVerifier` package-level function too.
+func (r *RemoteJWKS) Verifier(validators ...TokenValidator) *Verifier {
+	verifier := NewVerifier(nil, nil, validators...)
+	verifier.HeaderValidator = r.ValidateHeader
+	return verifier
+}
@@ -1,13 +1,22 @@
 package jwt_test
 
 import (
+	stdContext "context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	stdhttptest "net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/httptest"
 	"github.com/kataras/iris/v12/middleware/jwt"
+
+	kjwt "github.com/kataras/jwt"
 )
 
 var testAlg, testSecret = jwt.HS256, []byte("sercrethatmaycontainch@r$")
@@ -63,3 +72,158 @@ func TestJWT(t *testing.T) {
 	e.GET("/protected").WithHeader("Authorization", headerValue).Expect().
 		Status(iris.StatusUnauthorized).Body().IsEqual("jwt: token expired")
 }
+
+func TestVerifierRejectedTypes(t *testing.T) {
+	keys := make(kjwt.Keys)
+	keys.Register(testAlg, "test", testSecret, testSecret)
+
+	header := map[string]string{"kid": "test", "alg": testAlg.Name(), "typ": jwt.MFAPendingType}
+	token, err := kjwt.SignWithHeader(testAlg, testSecret, fooClaims{Foo: "bar"}, header, kjwt.MaxAge(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := jwt.NewVerifier(nil, nil)
+	verifier.HeaderValidator = keys.ValidateHeader
+	if _, err = verifier.VerifyToken(token); !errors.Is(err, jwt.ErrTokenType) {
+		t.Fatalf("expected error: %v but got: %v", jwt.ErrTokenType, err)
+	}
+
+	verifier.RejectedTypes = nil
+	if _, err = verifier.VerifyToken(token); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	set, err := jwt.NewKeySet(jwt.EdDSA, 0, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer set.Close()
+
+	noGraceSet, err := jwt.NewKeySet(jwt.ES256, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer noGraceSet.Close()
+
+	app := iris.New()
+	app.Get(jwt.JWKSPath, set.Handler)
+	app.Get("/protected", set.Verifier().Verify(func() interface{} { return new(fooClaims) }), func(ctx iris.Context) {
+		ctx.WriteString(jwt.Get(ctx).(*fooClaims).Foo)
+	})
+	app.Get("/protected/nograce", noGraceSet.Verifier().Verify(nil))
+
+	srv := stdhttptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(set.JWKS())
+	}))
+	defer srv.Close()
+
+	remote := jwt.NewRemoteJWKS(srv.URL)
+	remote.MinRefreshInterval = 0
+	app.Get("/remote", remote.Verifier().Verify(nil))
+
+	e := httptest.New(t, app)
+
+	token, err := set.Signer(time.Minute).Sign(fooClaims{Foo: "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noGraceToken, err := noGraceSet.Signer(time.Minute).Sign(fooClaims{Foo: "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.GET("/protected").WithQuery("token", string(token)).Expect().Status(iris.StatusOK).Body().IsEqual("bar")
+	e.GET("/protected/nograce").WithQuery("token", string(noGraceToken)).Expect().Status(iris.StatusOK)
+	e.GET("/remote").WithQuery("token", string(token)).Expect().Status(iris.StatusOK)
+	e.GET(jwt.JWKSPath).Expect().Status(iris.StatusOK).
+		JSON().Path("$.keys").Array().Length().IsEqual(1)
+
+	if err = set.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err = noGraceSet.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// retired keys are valid in their grace period.
+	e.GET("/protected").WithQuery("token", string(token)).Expect().Status(iris.StatusOK)
+	e.GET("/protected/nograce").WithQuery("token", string(noGraceToken)).Expect().Status(iris.StatusUnauthorized)
+	e.GET(jwt.JWKSPath).Expect().Status(iris.StatusOK).
+		JSON().Path("$.keys").Array().Length().IsEqual(2)
+
+	// the remote verifier fetches the keys again on unknown kid.
+	newToken, err := set.Signer(time.Minute).Sign(fooClaims{Foo: "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/remote").WithQuery("token", string(newToken)).Expect().Status(iris.StatusOK)
+}
+
+func TestRemoteJWKSSingleFetch(t *testing.T) {
+	set, err := jwt.NewKeySet(jwt.EdDSA, 0, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer set.Close()
+
+	var fetches int32
+	srv := stdhttptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		time.Sleep(50 * time.Millisecond)
+		json.NewEncoder(w).Encode(set.JWKS())
+	}))
+	defer srv.Close()
+
+	remote := jwt.NewRemoteJWKS(srv.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := remote.Key(stdContext.Background(), fmt.Sprintf("unknown-%d", i)); err != kjwt.ErrUnknownKid {
+				t.Errorf("expected ErrUnknownKid but got: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Fatalf("expected a single fetch but got %d", n)
+	}
+
+	if _, err = jwt.NewKeySet(jwt.HS256, 0, 0); err == nil {
+		t.Fatalf("expected an error for a symmetric algorithm")
+	}
+}
+
+func TestJWKSLoad(t *testing.T) {
+	set, err := jwt.NewKeySet(jwt.ES256, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer set.Close()
+
+	jwks := set.JWKS()
+	if _, err = jwks.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the algorithm should match the key type and curve
+	// and the symmetric ones are never accepted.
+	for _, alg := range []string{"HS256", "RS256", "ES384", "EdDSA"} {
+		k := jwks.Keys[0]
+		k.Alg = alg
+		if _, err = (jwt.JWKS{Keys: []jwt.JWK{k}}).Load(); err == nil {
+			t.Fatalf("expected an error for the %s algorithm of an EC P-256 key", alg)
+		}
+	}
+
+	k := jwt.JWK{Kty: "oct", Alg: "HS256"}
+	if _, err = (jwt.JWKS{Keys: []jwt.JWK{k}}).Load(); err == nil {
+		t.Fatalf("expected an error for a symmetric key")
+	}
+}
@@ -0,0 +1,304 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+
+	"github.com/google/uuid"
+	"github.com/kataras/jwt"
+)
+
+// JWKSPath is the well-known path of a JSON Web Key Set, see `KeySet.Handler`.
+const JWKSPath = "/.well-known/jwks.json"
+
+type keySetEntry struct {
+	key       *jwt.Key
+	retiredAt time.Time // zero for the current key.
+}
+
+// KeySet holds a rotating set of asymmetric signing keys.
+// Tokens are signed by the current key and their header carries its "kid",
+// retired keys stay valid for verification until their grace period ends.
+// The public keys are published through its `Handler` method as a JSON Web Key Set,
+// so other services can verify the tokens through a `RemoteJWKS`.
+//
+// The keys are generated in memory, they are not shared between servers
+// and they do not survive restarts.
+//
+// Initialize it through the `NewKeySet` package-level function.
+type KeySet struct {
+	alg              Alg
+	rotationInterval time.Duration
+	gracePeriod      time.Duration
+
+	mu      sync.RWMutex
+	entries []*keySetEntry // the current key is the last one.
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewKeySet returns a new KeySet which generates keys for the "alg" signature algorithm,
+// which must be an asymmetric one (RS*, PS*, ES* or EdDSA).
+// If "rotationInterval" is positive then a new key is generated on that interval,
+// call its Close method to stop the rotation.
+// The retired keys verify tokens for the "gracePeriod" duration,
+// it should be at least the max age of the signed tokens.
+//
+// Usage:
+//
+//	set, err := jwt.NewKeySet(jwt.EdDSA, 24*time.Hour, 2*time.Hour)
+//	app.Get(jwt.JWKSPath, set.Handler)
+//	signer := set.Signer(15 * time.Minute)
+//	verifier := set.Verifier()
+func NewKeySet(alg Alg, rotationInterval, gracePeriod time.Duration) (*KeySet, error) {
+	s := &KeySet{
+		alg:              alg,
+		rotationInterval: rotationInterval,
+		gracePeriod:      gracePeriod,
+		stop:             make(chan struct{}),
+	}
+
+	// generates the first key, it fails on an unsupported algorithm.
+	if err := s.Rotate(); err != nil {
+		return nil, err
+	}
+
+	if rotationInterval > 0 {
+		go s.rotateEvery(rotationInterval)
+	}
+
+	return s, nil
+}
+
+func (s *KeySet) rotateEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Rotate(); err != nil {
+				context.DefaultLogger("jwt").Errorf("key set: rotate: %v", err)
+			}
+		}
+	}
+}
+
+// Close stops the scheduled rotation.
+func (s *KeySet) Close() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+// Rotate generates a new current key and retires the previous one.
+// Retired keys which their grace period has ended are removed.
+func (s *KeySet) Rotate() error {
+	private, public, err := generateKey(s.alg)
+	if err != nil {
+		return err
+	}
+
+	key := &jwt.Key{
+		ID:      uuid.NewString(),
+		Alg:     s.alg,
+		Public:  public,
+		Private: private,
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	if n := len(s.entries); n > 0 {
+		s.entries[n-1].retiredAt = now
+	}
+
+	entries := s.entries[:0]
+	for _, e := range s.entries {
+		if now.Sub(e.retiredAt) < s.gracePeriod {
+			entries = append(entries, e)
+		}
+	}
+	s.entries = append(entries, &keySetEntry{key: key})
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Current returns the key which signs the new tokens.
+func (s *KeySet) Current() *jwt.Key {
+	s.mu.RLock()
+	key := s.entries[len(s.entries)-1].key
+	s.mu.RUnlock()
+	return key
+}
+
+// Key returns the key of the given "kid" if it's the current key
+// or a retired key in its grace period.
+func (s *KeySet) Key(kid string) (*jwt.Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.entries {
+		if e.key.ID != kid {
+			continue
+		}
+
+		if !e.retiredAt.IsZero() && time.Since(e.retiredAt) >= s.gracePeriod {
+			return nil, false
+		}
+
+		return e.key, true
+	}
+
+	return nil, false
+}
+
+// SignToken signs the "claims" with the current key and sets its "kid" to the token's header.
+func (s *KeySet) SignToken(claims interface{}, opts ...SignOption) ([]byte, error) {
+	key := s.Current()
+	return jwt.SignEncryptedWithHeader(key.Alg, key.Private, nil, claims, jwt.HeaderWithKid{
+		Kid: key.ID,
+		Alg: key.Alg.Name(),
+	}, opts...)
+}
+
+// ValidateHeader completes the jwt.HeaderValidator function type.
+// It resolves the public key of the token by its "kid" header field.
+func (s *KeySet) ValidateHeader(alg string, headerDecoded []byte) (Alg, jwt.PublicKey, jwt.InjectFunc, error) {
+	var h jwt.HeaderWithKid
+	if err := json.Unmarshal(headerDecoded, &h); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if h.Kid == "" {
+		return nil, nil, nil, jwt.ErrEmptyKid
+	}
+
+	key, ok := s.Key(h.Kid)
+	if !ok {
+		return nil, nil, nil, jwt.ErrUnknownKid
+	}
+
+	if h.Alg != key.Alg.Name() || (alg != "" && alg != h.Alg) {
+		return nil, nil, nil, ErrTokenAlg
+	}
+
+	return key.Alg, key.Public, nil, nil
+}
+
+// JWKS returns the public keys of the current and the retired keys in their grace period.
+func (s *KeySet) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := JWKS{Keys: make([]JWK, 0, len(s.entries))}
+	for i := len(s.entries) - 1; i >= 0; i-- { // current key first.
+		e := s.entries[i]
+		if !e.retiredAt.IsZero() && time.Since(e.retiredAt) >= s.gracePeriod {
+			continue
+		}
+
+		if jwk, err := NewJWK(e.key.ID, e.key.Alg, e.key.Public); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// Handler serves the public keys as a JSON Web Key Set.
+// Register it on the `JWKSPath`, e.g. app.Get(jwt.JWKSPath, set.Handler).
+func (s *KeySet) Handler(ctx *context.Context) {
+	maxAge := s.rotationInterval / 4
+	if maxAge <= 0 || maxAge > time.Hour {
+		maxAge = time.Hour
+	}
+
+	ctx.Header(context.CacheControlHeaderKey, "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	ctx.JSON(s.JWKS())
+}
+
+// Signer returns a new Signer which signs the tokens through this key set.
+func (s *KeySet) Signer(maxAge time.Duration) *Signer {
+	signer := NewSigner(s.alg, nil, maxAge)
+	signer.KeySet = s
+	return signer
+}
+
+// Verifier returns a new Verifier which verifies the tokens through this key set.
+func (s *KeySet) Verifier(validators ...TokenValidator) *Verifier {
+	verifier := NewVerifier(s.alg, nil, validators...)
+	verifier.HeaderValidator = s.ValidateHeader
+	return verifier
+}
+
+// NewJWK returns the JSON Web Key of the "publicKey",
+// which can be an *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey.
+func NewJWK(kid string, alg Alg, publicKey jwt.PublicKey) (JWK, error) {
+	jwk := JWK{Kid: kid, Use: "sig", Alg: alg.Name()}
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = key.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(key)
+	default:
+		return JWK{}, fmt.Errorf("jwk: %q: unsupported public key type: %T", kid, publicKey)
+	}
+
+	return jwk, nil
+}
+
+// generateKey generates a new key pair for the given asymmetric signature algorithm.
+func generateKey(alg Alg) (jwt.PrivateKey, jwt.PublicKey, error) {
+	switch alg {
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return key, &key.PublicKey, nil
+	case ES256, ES384, ES512:
+		curve := map[Alg]elliptic.Curve{ES256: elliptic.P256(), ES384: elliptic.P384(), ES512: elliptic.P521()}[alg]
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return key, &key.PublicKey, nil
+	case EdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return private, public, nil
+	default:
+		return nil, nil, fmt.Errorf("jwt: key set: unsupported algorithm: %v", alg)
+	}
+}
@@ -11,7 +11,7 @@ import (
 // Its Sign method can be used to generate a token which can be sent to the client.
 // Its NewTokenPair can be used to construct a token pair (access_token, refresh_token).
 //
-// It does not support JWE, JWK.
+// It does not support JWE.
 type Signer struct {
 	Alg Alg
 	Key interface{}
@@ -23,6 +23,11 @@ type Signer struct {
 	Options []SignOption
 
 	Encrypt func([]byte) ([]byte, error)
+
+	// KeySet, if not nil, signs the tokens with its current key
+	// and sets the key's id to the "kid" header, the Alg and Key fields are ignored.
+	// See the `KeySet.Signer` method.
+	KeySet *KeySet
 }
 
 // NewSigner accepts the signature algorithm among with its (private or shared) key
@@ -73,6 +78,14 @@ func (s *Signer) Sign(claims interface{}, opts ...SignOption) ([]byte, error) {
 		opts = s.Options
 	}
 
+	return s.sign(claims, opts...)
+}
+
+func (s *Signer) sign(claims interface{}, opts ...SignOption) ([]byte, error) {
+	if s.KeySet != nil {
+		return s.KeySet.SignToken(claims, opts...)
+	}
+
 	return SignEncrypted(s.Alg, s.Key, s.Encrypt, claims, opts...)
 }
 
@@ -89,7 +102,12 @@ func (s *Signer) NewTokenPair(accessClaims interface{}, refreshClaims interface{
 		return TokenPair{}, err
 	}
 
-	refreshToken, err := Sign(s.Alg, s.Key, refreshClaims, MaxAge(refreshMaxAge))
+	var refreshToken []byte
+	if s.KeySet != nil {
+		refreshToken, err = s.KeySet.SignToken(refreshClaims, MaxAge(refreshMaxAge))
+	} else {
+		refreshToken, err = Sign(s.Alg, s.Key, refreshClaims, MaxAge(refreshMaxAge))
+	}
 	if err != nil {
 		return TokenPair{}, err
 	}
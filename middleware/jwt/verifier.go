@@ -1,7 +1,9 @@
 package jwt
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/kataras/iris/v12/context"
@@ -14,6 +16,14 @@ const (
 	verifiedTokenContextKey = "iris.jwt.token"
 )
 
+// MFAPendingType is the "typ" header of the short-lived "mfa-pending" tokens
+// of the auth package, which are sent before the second factor is completed.
+// They are rejected by the Verifier by default, see its RejectedTypes field.
+const MFAPendingType = "mfa-pending+jwt"
+
+// ErrTokenType is fired when the "typ" header of a token is rejected by the Verifier.
+var ErrTokenType = errors.New("jwt: token type is rejected")
+
 // Get returns the claims decoded by a verifier.
 func Get(ctx *context.Context) interface{} {
 	if v := ctx.Values().Get(claimsContextKey); v != nil {
@@ -39,12 +49,20 @@ func GetVerifiedToken(ctx *context.Context) *VerifiedToken {
 // Verifier holds common options to verify an incoming token.
 // Its Verify method can be used as a middleware to allow authorized clients to access an API.
 //
-// It does not support JWE, JWK.
+// It does not support JWE.
 type Verifier struct {
 	Alg Alg
 	Key interface{}
 
 	Decrypt func([]byte) ([]byte, error)
+	// HeaderValidator, if not nil, resolves the signature algorithm and the public key
+	// of each token by its header, e.g. by its "kid", the Alg and Key fields are ignored.
+	// See the `KeySet.Verifier` method and the `NewRemoteVerifier` package-level function.
+	HeaderValidator jwt.HeaderValidator
+	// RejectedTypes holds the "typ" header values of the tokens which are not accepted,
+	// even if their signature is valid.
+	// Defaults to MFAPendingType.
+	RejectedTypes []string
 
 	Extractors []TokenExtractor
 	Blocklist  Blocklist
@@ -101,7 +119,8 @@ func NewVerifier(signatureAlg Alg, signatureKey interface{}, validators ...Token
 		ErrorHandler: func(ctx *context.Context, err error) {
 			ctx.StopWithError(401, context.PrivateError(err))
 		},
-		Validators: validators,
+		Validators:    validators,
+		RejectedTypes: []string{MFAPendingType},
 	}
 }
 
@@ -163,7 +182,71 @@ type (
 // VerifyToken simply verifies the given "token" and validates its standard claims (such as expiration).
 // Returns a structure which holds the token's information. See the Verify method instead.
 func (v *Verifier) VerifyToken(token []byte, validators ...TokenValidator) (*VerifiedToken, error) {
-	return jwt.VerifyEncrypted(v.Alg, v.Key, v.Decrypt, token, validators...)
+	var (
+		verifiedToken *VerifiedToken
+		err           error
+	)
+
+	if v.HeaderValidator != nil {
+		verifiedToken, err = jwt.VerifyWithHeaderValidator(nil, nil, token, v.validateHeader, validators...)
+	} else {
+		verifiedToken, err = jwt.VerifyEncrypted(v.Alg, v.Key, v.Decrypt, token, validators...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = v.validateType(verifiedToken.Header); err != nil {
+		return nil, err
+	}
+
+	return verifiedToken, nil
+}
+
+// validateType rejects the tokens with a "typ" header of the RejectedTypes.
+func (v *Verifier) validateType(headerDecoded []byte) error {
+	if len(v.RejectedTypes) == 0 {
+		return nil
+	}
+
+	var header struct {
+		Typ string `json:"typ"`
+	}
+	if err := jwt.Unmarshal(headerDecoded, &header); err != nil {
+		return err
+	}
+
+	for _, typ := range v.RejectedTypes {
+		// Media types are case-insensitive.
+		if strings.EqualFold(header.Typ, typ) {
+			return ErrTokenType
+		}
+	}
+
+	return nil
+}
+
+func (v *Verifier) validateHeader(alg string, headerDecoded []byte) (Alg, jwt.PublicKey, jwt.InjectFunc, error) {
+	keyAlg, key, decrypt, err := v.HeaderValidator(alg, headerDecoded)
+	if err == nil && decrypt == nil {
+		decrypt = v.Decrypt
+	}
+
+	return keyAlg, key, decrypt, err
+}
+
+// NewRemoteVerifier returns a new Verifier which verifies the tokens through the keys
+// of a remote JSON Web Key Set, e.g. "https://issuer.example.com/.well-known/jwks.json".
+// The keys are cached and they are fetched again when a token
+// is signed by an unknown key id, see `RemoteJWKS` for more.
+//
+// Usage:
+//
+//	verifier := jwt.NewRemoteVerifier("https://issuer.example.com/.well-known/jwks.json", jwt.Expected{Issuer: "issuer"})
+//	app.Use(verifier.Verify(func() interface{} { return new(userClaims) }))
+func NewRemoteVerifier(jwksURL string, validators ...TokenValidator) *Verifier {
+	return NewRemoteJWKS(jwksURL).Verifier(validators...)
 }
 
 // Verify is the most important piece of code inside the Verifier.
@@ -5,6 +5,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/kataras/iris/v12/middleware/concurrency"
+
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
@@ -24,6 +26,9 @@ import (
 // * os_total_ram
 // * os_load_avg
 // * os_conns
+// * concurrency_in_flight
+// * concurrency_queued
+// * concurrency_rejected
 type Stats struct {
 	PIDCPU   float64 `json:"pid_cpu" yaml:"PIDCPU"`
 	PIDRAM   uint64  `json:"pid_ram" yaml:"PIDRAM"`
@@ -34,6 +39,11 @@ type Stats struct {
 	OSTotalRAM uint64  `json:"os_total_ram" yaml:"OSTotalRAM"`
 	OSLoadAvg  float64 `json:"os_load_avg" yaml:"OSLoadAvg"`
 	OSConns    int64   `json:"os_conns" yaml:"OSConns"`
+
+	// The requests of the concurrency limiters, see the middleware/concurrency package.
+	InFlight int64  `json:"in_flight" yaml:"InFlight"`
+	Queued   int64  `json:"queued" yaml:"Queued"`
+	Rejected uint64 `json:"rejected" yaml:"Rejected"`
 }
 
 // StatsHolder holds and refreshes the statistics.
@@ -201,5 +211,10 @@ func (sh *StatsHolder) GetStats() Stats {
 	}
 	sh.mu.Unlock()
 
+	concurrencyStats := concurrency.Totals()
+	statsCopy.InFlight = int64(concurrencyStats.InFlight)
+	statsCopy.Queued = int64(concurrencyStats.Queued)
+	statsCopy.Rejected = concurrencyStats.Rejected
+
 	return statsCopy
 }
@@ -0,0 +1,178 @@
+package rate
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+
+	"golang.org/x/time/rate"
+)
+
+// Algorithm is the rate limiting algorithm of a Policy.
+type Algorithm uint8
+
+const (
+	// AlgorithmTokenBucket allows bursts of "Limit" requests
+	// and refills the bucket at a rate of "Limit" tokens per "Window".
+	// Read more at: https://en.wikipedia.org/wiki/Token_bucket
+	AlgorithmTokenBucket Algorithm = iota
+	// AlgorithmSlidingWindowLog keeps the time of each request and allows
+	// at most "Limit" requests in any "Window" period.
+	AlgorithmSlidingWindowLog
+	// AlgorithmFixedWindow counts the requests and allows at most "Limit" requests
+	// per "Window" period, which starts on the first request of a client.
+	AlgorithmFixedWindow
+)
+
+// String returns the name of the algorithm.
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmTokenBucket:
+		return "token-bucket"
+	case AlgorithmSlidingWindowLog:
+		return "sliding-window-log"
+	case AlgorithmFixedWindow:
+		return "fixed-window"
+	default:
+		return "unknown"
+	}
+}
+
+// Policy describes a named rate limit, e.g. 10 requests per second.
+// Use the `TokenBucket`, `SlidingWindow` and `FixedWindow`
+// package-level functions to create one.
+type Policy struct {
+	// Name of the policy, it should be unique per limiter.
+	Name      string
+	Algorithm Algorithm
+	// Limit is the maximum number of requests per Window.
+	Limit int
+	// Window is the period of the limit.
+	// A zero Window on a token bucket means an infinite rate.
+	Window time.Duration
+
+	// tokenRate, if not zero, overrides the refill rate of the token bucket
+	// to keep the exact rate of the `Limit` package-level function.
+	tokenRate rate.Limit
+	// zeroRate reports whether the token bucket is never refilled,
+	// e.g. Limit(0, burst).
+	zeroRate bool
+}
+
+// TokenBucket returns a token bucket Policy which allows bursts of "limit" requests
+// and refills the bucket at a rate of "limit" requests per "window".
+//
+// E.g. TokenBucket("per-second", 10, time.Second).
+func TokenBucket(name string, limit int, window time.Duration) Policy {
+	return Policy{Name: name, Algorithm: AlgorithmTokenBucket, Limit: limit, Window: window}
+}
+
+// SlidingWindow returns a sliding window log Policy
+// which allows at most "limit" requests in any "window" period.
+//
+// E.g. SlidingWindow("per-minute", 100, time.Minute).
+func SlidingWindow(name string, limit int, window time.Duration) Policy {
+	return Policy{Name: name, Algorithm: AlgorithmSlidingWindowLog, Limit: limit, Window: window}
+}
+
+// FixedWindow returns a fixed window counter Policy
+// which allows at most "limit" requests per "window" period.
+//
+// E.g. FixedWindow("daily", 1000, 24*time.Hour).
+func FixedWindow(name string, limit int, window time.Duration) Policy {
+	return Policy{Name: name, Algorithm: AlgorithmFixedWindow, Limit: limit, Window: window}
+}
+
+// tokenBucketPolicy converts the "limit" tokens per second and "burst"
+// arguments of the `Limit` package-level function to a Policy.
+func tokenBucketPolicy(limit float64, burst int) Policy {
+	p := TokenBucket("", burst, 0)
+	p.tokenRate = rate.Limit(limit)
+
+	if limit > 0 && limit < Inf {
+		p.Window = time.Duration(float64(burst) / limit * float64(time.Second))
+	} else if limit <= 0 {
+		p.Window = math.MaxInt64
+		p.zeroRate = true
+	}
+
+	return p
+}
+
+// TokenRate returns the refill rate, tokens per second, of a token bucket policy.
+// It is zero for a bucket which is never refilled, e.g. Limit(0, burst).
+func (p Policy) TokenRate() rate.Limit {
+	if p.zeroRate {
+		return 0
+	}
+
+	if p.tokenRate != 0 {
+		return p.tokenRate
+	}
+
+	if p.Window <= 0 {
+		return rate.Inf
+	}
+
+	return rate.Limit(float64(p.Limit) / p.Window.Seconds())
+}
+
+// The IETF RateLimit response header names.
+// Read more at: https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers.
+const (
+	RateLimitLimitHeaderKey     = "RateLimit-Limit"
+	RateLimitRemainingHeaderKey = "RateLimit-Remaining"
+	RateLimitResetHeaderKey     = "RateLimit-Reset"
+	RateLimitPolicyHeaderKey    = "RateLimit-Policy"
+	RetryAfterHeaderKey         = "Retry-After"
+)
+
+// Headers is an `Option` that can be passed at the `Limit` package-level function.
+// It enables or disables the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset,
+// RateLimit-Policy and Retry-After response headers.
+// When many policies are registered the headers describe
+// the policy which is the closest to its limit.
+//
+// Defaults to true.
+func Headers(enable bool) Option {
+	return func(l *Limiter) {
+		l.headers = enable
+	}
+}
+
+// writeHeaders sets the RateLimit response headers based on the given result.
+func writeHeaders(ctx *context.Context, policies []Policy, result Result) {
+	ctx.Header(RateLimitLimitHeaderKey, strconv.Itoa(result.Policy.Limit))
+	ctx.Header(RateLimitRemainingHeaderKey, strconv.Itoa(result.Remaining))
+	ctx.Header(RateLimitResetHeaderKey, formatSeconds(result.Reset))
+
+	var b strings.Builder
+	for i, p := range policies {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(strconv.Itoa(p.Limit))
+		if p.Window > 0 && p.Window < math.MaxInt64 {
+			b.WriteString(";w=")
+			b.WriteString(formatSeconds(p.Window))
+		}
+	}
+	ctx.Header(RateLimitPolicyHeaderKey, b.String())
+
+	if !result.Allowed && result.RetryAfter > 0 {
+		ctx.Header(RetryAfterHeaderKey, formatSeconds(result.RetryAfter))
+	}
+}
+
+// formatSeconds returns the "d" rounded up to seconds.
+func formatSeconds(d time.Duration) string {
+	if d <= 0 {
+		return "0"
+	}
+
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
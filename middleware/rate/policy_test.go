@@ -0,0 +1,28 @@
+package rate
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestPolicyTokenRate(t *testing.T) {
+	tests := []struct {
+		policy   Policy
+		expected rate.Limit
+	}{
+		{tokenBucketPolicy(0, 2), 0},
+		{tokenBucketPolicy(-1, 2), 0},
+		{tokenBucketPolicy(2, 4), 2},
+		{tokenBucketPolicy(Inf, 4), rate.Limit(Inf)},
+		{TokenBucket("per-second", 10, time.Second), 10},
+		{TokenBucket("infinite", 10, 0), rate.Inf},
+	}
+
+	for i, tt := range tests {
+		if got := tt.policy.TokenRate(); got != tt.expected {
+			t.Fatalf("[%d] expected token rate: %v but got: %v", i, tt.expected, got)
+		}
+	}
+}
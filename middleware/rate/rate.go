@@ -21,6 +21,8 @@ func init() {
 // * ExceedHandler
 // * ClientData
 // * PurgeEvery
+// * SetStore
+// * Headers
 type Option func(*Limiter)
 
 // ExceedHandler is an `Option` that can be passed at the `Limit` package-level function.
@@ -82,8 +84,9 @@ type (
 		clientDataFunc func(ctx *context.Context) interface{} // fill the Client's Data field.
 		exceedHandler  context.Handler                        // when too many requests.
 
-		limit     rate.Limit
-		burstSize int
+		policies []Policy
+		store    Store // defaults to memoryStore.
+		headers  bool  // send the RateLimit response headers.
 
 		clients map[string]*Client
 		mu      sync.RWMutex // mutex for clients.
@@ -98,7 +101,8 @@ type (
 		Limiter *rate.Limiter
 
 		lastSeen time.Time
-		mu       sync.RWMutex // mutex for lastSeen.
+		states   map[string]any // the in-memory state per policy, see memoryStore.
+		mu       sync.RWMutex   // mutex for lastSeen and states.
 	}
 )
 
@@ -110,12 +114,31 @@ const Inf = math.MaxFloat64
 //
 // E.g. Limit(1, 5) to allow 1 request per second, with a maximum burst size of 5.
 //
-// See `ExceedHandler`, `ClientData` and `PurgeEvery` for the available "options".
+// See `ExceedHandler`, `ClientData`, `PurgeEvery`, `SetStore` and `Headers` for the available "options".
 func Limit(limit float64, burst int, options ...Option) context.Handler {
+	return LimitPolicies([]Policy{tokenBucketPolicy(limit, burst)}, options...)
+}
+
+// LimitPolicies returns a new rate limiter handler which limits the requests
+// based on one or more named policies at the same time.
+// A request is allowed only if all policies allow it.
+//
+// E.g. LimitPolicies([]rate.Policy{
+// rate.TokenBucket("per-second", 10, time.Second),
+// rate.FixedWindow("daily", 1000, 24*time.Hour),
+// })
+//
+// See `Limit` for the available "options".
+func LimitPolicies(policies []Policy, options ...Option) context.Handler {
+	if len(policies) == 0 {
+		panic("iris: rate: at least one policy is required")
+	}
+
 	l := &Limiter{
-		clients:   make(map[string]*Client),
-		limit:     rate.Limit(limit),
-		burstSize: burst,
+		clients:  make(map[string]*Client),
+		policies: policies,
+		store:    memoryStore{},
+		headers:  true,
 		exceedHandler: func(ctx *context.Context) {
 			ctx.StopWithStatus(429) // Too Many Requests.
 		},
@@ -146,10 +169,7 @@ func (l *Limiter) serveHTTP(ctx *context.Context) {
 	l.mu.RUnlock()
 
 	if !ok {
-		client = &Client{
-			ID:      id,
-			Limiter: rate.NewLimiter(l.limit, l.burstSize),
-		}
+		client = l.newClient(id)
 
 		if l.clientDataFunc != nil {
 			client.Data = l.clientDataFunc(ctx)
@@ -168,7 +188,45 @@ func (l *Limiter) serveHTTP(ctx *context.Context) {
 
 	ctx.Values().Set(clientContextKey, client)
 
-	if client.Limiter.Allow() {
+	var (
+		result  Result
+		found   bool
+		allowed []Result
+	)
+	for _, p := range l.policies {
+		r, err := l.store.Allow(client, p)
+		if err != nil {
+			// don't block the clients when the store is unavailable.
+			ctx.Application().Logger().Errorf("ratelimit: %s: %s: %v", id, p.Name, err)
+			continue
+		}
+
+		if r.Allowed {
+			allowed = append(allowed, r)
+		}
+
+		if !found || r.moreRestrictive(result) {
+			result = r
+			found = true
+		}
+	}
+
+	if !found {
+		ctx.Next()
+		return
+	}
+
+	if !result.Allowed {
+		// the request is denied, give it back to the policies which allowed it.
+		l.refund(ctx, client, allowed)
+	}
+
+	ctx.Values().Set(resultContextKey, result)
+	if l.headers {
+		writeHeaders(ctx, l.policies, result)
+	}
+
+	if result.Allowed {
 		ctx.Next()
 		return
 	}
@@ -178,6 +236,54 @@ func (l *Limiter) serveHTTP(ctx *context.Context) {
 	}
 }
 
+// refund gives back the request consumed by the "allowed" results.
+func (l *Limiter) refund(ctx *context.Context, client *Client, allowed []Result) {
+	refunder, _ := l.store.(Refunder)
+	for _, r := range allowed {
+		if r.refund != nil {
+			r.refund()
+			continue
+		}
+
+		if refunder == nil {
+			continue
+		}
+
+		if err := refunder.Refund(client, r); err != nil {
+			ctx.Application().Logger().Errorf("ratelimit: %s: %s: %v", client.ID, r.Policy.Name, err)
+		}
+	}
+}
+
+// newClient returns a new Client, its Limiter is the token bucket
+// of the first policy, which is also used by the in-memory store.
+func (l *Limiter) newClient(id string) *Client {
+	p := l.policies[0]
+	client := &Client{
+		ID:      id,
+		Limiter: rate.NewLimiter(p.TokenRate(), p.Limit),
+	}
+
+	if p.Algorithm == AlgorithmTokenBucket {
+		client.states = map[string]any{p.Name: client.Limiter}
+	}
+
+	return client
+}
+
+// moreRestrictive reports whether "r" is closer to its limit than "other".
+func (r Result) moreRestrictive(other Result) bool {
+	if r.Allowed != other.Allowed {
+		return !r.Allowed
+	}
+
+	if !r.Allowed {
+		return r.RetryAfter > other.RetryAfter
+	}
+
+	return r.Remaining < other.Remaining
+}
+
 const identifierContextKey = "iris.ratelimit.identifier"
 
 // SetIdentifier can be called manually from a handler or a middleare
@@ -194,7 +300,10 @@ func getIdentifier(ctx *context.Context) string {
 	return ctx.RemoteAddr()
 }
 
-const clientContextKey = "iris.ratelimit.client"
+const (
+	clientContextKey = "iris.ratelimit.client"
+	resultContextKey = "iris.ratelimit.result"
+)
 
 // Get returns the current rate limited `Client`.
 // Use it when you want to log or add response headers based on the current request limitation.
@@ -212,6 +321,14 @@ func Get(ctx *context.Context) *Client {
 	return nil
 }
 
+// GetResult returns the rate limiting outcome of the current request,
+// based on the policy which is the closest to its limit.
+// It reports false if the request was not checked by a rate limiter.
+func GetResult(ctx *context.Context) (Result, bool) {
+	result, ok := ctx.Values().Get(resultContextKey).(Result)
+	return result, ok
+}
+
 // LastSeen reports the last Client's visit.
 func (c *Client) LastSeen() time.Time {
 	c.mu.RLock()
@@ -233,7 +350,15 @@ func (c *Client) TokensFromDuration(d time.Duration) float64 {
 // DurationFromTokens is a unit conversion function from the number of tokens to the duration
 // of time it takes to accumulate them at a rate of limit tokens per second.
 func (c *Client) DurationFromTokens(tokens float64) time.Duration {
+	return durationFromTokens(c.Limiter.Limit(), tokens)
+}
+
+func durationFromTokens(limit rate.Limit, tokens float64) time.Duration {
 	// rate.go#durationFromTokens
-	seconds := tokens / float64(c.Limiter.Limit())
+	if limit <= 0 || limit == rate.Inf {
+		return 0
+	}
+
+	seconds := tokens / float64(limit)
 	return time.Nanosecond * time.Duration(1e9*seconds)
 }
@@ -0,0 +1,115 @@
+package rate_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/middleware/rate"
+)
+
+type testStore struct {
+	calls int
+	err   error
+}
+
+func (s *testStore) Allow(c *rate.Client, p rate.Policy) (rate.Result, error) {
+	s.calls++
+	return rate.Result{Policy: p, Allowed: s.calls <= 2}, s.err
+}
+
+func TestLimit(t *testing.T) {
+	app := iris.New()
+	app.Get("/", rate.Limit(1, 2), func(ctx iris.Context) {
+		ctx.WriteString("OK")
+	})
+
+	store := new(testStore)
+	app.Get("/store", rate.Limit(1, 2, rate.SetStore(store)), func(ctx iris.Context) {
+		ctx.WriteString(rate.Get(ctx).ID)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual("OK")
+	e.GET("/").Expect().Status(http.StatusOK).Body().IsEqual("OK")
+	e.GET("/").Expect().Status(http.StatusTooManyRequests)
+
+	e.GET("/store").Expect().Status(http.StatusOK)
+	e.GET("/store").Expect().Status(http.StatusOK)
+	e.GET("/store").Expect().Status(http.StatusTooManyRequests)
+
+	// fail open.
+	store.err = errors.New("store is down")
+	e.GET("/store").Expect().Status(http.StatusOK)
+	if store.calls != 4 {
+		t.Fatalf("expected store to be called 4 times but called %d", store.calls)
+	}
+}
+
+func TestLimitPolicies(t *testing.T) {
+	app := iris.New()
+	app.Get("/", rate.LimitPolicies([]rate.Policy{
+		rate.TokenBucket("burst", 3, time.Second),
+		rate.SlidingWindow("minute", 2, time.Minute),
+		rate.FixedWindow("daily", 10, 24*time.Hour),
+	}), func(ctx iris.Context) {
+		ctx.WriteString("OK")
+	})
+
+	e := httptest.New(t, app)
+	r := e.GET("/").Expect().Status(http.StatusOK)
+	r.Header(rate.RateLimitLimitHeaderKey).IsEqual("2")
+	r.Header(rate.RateLimitRemainingHeaderKey).IsEqual("1")
+	r.Header(rate.RateLimitResetHeaderKey).IsEqual("60")
+	r.Header(rate.RateLimitPolicyHeaderKey).IsEqual("3;w=1, 2;w=60, 10;w=86400")
+	r.Header(rate.RetryAfterHeaderKey).IsEmpty()
+
+	r = e.GET("/").Expect().Status(http.StatusOK)
+	r.Header(rate.RateLimitRemainingHeaderKey).IsEqual("0")
+
+	r = e.GET("/").Expect().Status(http.StatusTooManyRequests)
+	r.Header(rate.RateLimitLimitHeaderKey).IsEqual("2")
+	r.Header(rate.RateLimitRemainingHeaderKey).IsEqual("0")
+	r.Header(rate.RetryAfterHeaderKey).IsEqual("60")
+}
+
+func TestLimitFixedWindow(t *testing.T) {
+	app := iris.New()
+	app.Get("/", rate.LimitPolicies([]rate.Policy{
+		rate.FixedWindow("window", 2, 2*time.Second),
+	}, rate.Headers(false)), func(ctx iris.Context) {
+		ctx.WriteString("OK")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(http.StatusOK).Header(rate.RateLimitLimitHeaderKey).IsEmpty()
+	e.GET("/").Expect().Status(http.StatusOK)
+	e.GET("/").Expect().Status(http.StatusTooManyRequests)
+	time.Sleep(2 * time.Second)
+	e.GET("/").Expect().Status(http.StatusOK)
+}
+
+func TestLimitPoliciesRefund(t *testing.T) {
+	app := iris.New()
+	app.Get("/", rate.LimitPolicies([]rate.Policy{
+		rate.FixedWindow("short", 2, time.Second),
+		rate.FixedWindow("long", 3, time.Hour),
+	}), func(ctx iris.Context) {
+		ctx.WriteString("OK")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(http.StatusOK)
+	e.GET("/").Expect().Status(http.StatusOK)
+	// denied by the short policy, the long one should keep its remaining quota.
+	e.GET("/").Expect().Status(http.StatusTooManyRequests)
+	e.GET("/").Expect().Status(http.StatusTooManyRequests)
+
+	time.Sleep(time.Second)
+	r := e.GET("/").Expect().Status(http.StatusOK)
+	r.Header(rate.RateLimitLimitHeaderKey).IsEqual("3")
+	r.Header(rate.RateLimitRemainingHeaderKey).IsEqual("0")
+}
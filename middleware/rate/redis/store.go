@@ -0,0 +1,301 @@
+// Package redis provides a redis-based Store for the rate limiter middleware,
+// so the limits are shared between all the servers behind a load balancer.
+package redis
+
+import (
+	stdContext "context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/iris/v12/middleware/rate"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultPrefix is the default prefix of the redis keys which hold the clients state.
+const DefaultPrefix = "iris.ratelimit."
+
+// defaultZeroRateExpiration is the default value of the Store.ZeroRateExpiration field.
+const defaultZeroRateExpiration = 24 * time.Hour
+
+// tokenBucketScript atomically refills and consumes the token bucket of a client.
+// The redis server's clock is used, so all servers share the same time.
+//
+// KEYS[1]: the client's key.
+// ARGV[1]: the limit, tokens per second.
+// ARGV[2]: the burst size.
+// ARGV[3]: the lifetime of a bucket with a zero rate in milliseconds.
+//
+// Returns: allowed (0 or 1), remaining tokens, retry after and reset in milliseconds.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", key, "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+if rate > 0 then
+	tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+elseif rate > 0 then
+	retry = math.ceil((1 - tokens) * 1000 / rate)
+else
+	retry = -1
+end
+
+local reset = 0
+if rate > 0 then
+	reset = math.ceil((burst - tokens) * 1000 / rate)
+end
+
+redis.call("HSET", key, "tokens", tostring(tokens), "ts", now)
+if rate <= 0 then
+	-- the bucket is never refilled, keep the consumed tokens.
+	redis.call("PEXPIRE", key, tonumber(ARGV[3]))
+elseif reset > 0 then
+	redis.call("PEXPIRE", key, reset)
+else
+	redis.call("DEL", key)
+end
+
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+// slidingWindowScript atomically removes the requests which are out of the window
+// and logs the current one if the limit is not reached.
+//
+// KEYS[1]: the client's key.
+// ARGV[1]: the limit.
+// ARGV[2]: the window in milliseconds.
+// ARGV[3]: a unique member for the current request.
+//
+// Returns: allowed (0 or 1), remaining requests, retry after and reset in milliseconds.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now - window)
+local count = redis.call("ZCARD", key)
+
+local allowed = 0
+local remaining = 0
+if count < limit then
+	redis.call("ZADD", key, now, ARGV[3])
+	redis.call("PEXPIRE", key, window)
+	count = count + 1
+	allowed = 1
+	remaining = limit - count
+end
+
+local reset = 0
+if count > 0 then
+	local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+	reset = math.max(0, tonumber(oldest[2]) + window - now)
+end
+
+local retry = 0
+if allowed == 0 then
+	retry = reset
+end
+
+return {allowed, remaining, retry, reset}
+`)
+
+// fixedWindowScript atomically counts the current request,
+// the window starts on the first request of the client.
+//
+// KEYS[1]: the client's key.
+// ARGV[1]: the limit.
+// ARGV[2]: the window in milliseconds.
+//
+// Returns: allowed (0 or 1), remaining requests, retry after and reset in milliseconds.
+var fixedWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local count = redis.call("INCR", key)
+if count == 1 then
+	redis.call("PEXPIRE", key, window)
+end
+
+local reset = redis.call("PTTL", key)
+if reset < 0 then
+	redis.call("PEXPIRE", key, window)
+	reset = window
+end
+
+if count <= limit then
+	return {1, limit - count, 0, reset}
+end
+
+return {0, 0, reset, reset}
+`)
+
+// refundScript atomically gives back the request consumed by the last allowed call
+// of one of the above scripts.
+//
+// KEYS[1]: the client's key.
+// ARGV[1]: the algorithm, see rate.Algorithm.
+// ARGV[2]: the burst size of a token bucket.
+// ARGV[3]: the member of the request on a sliding window log.
+var refundScript = redis.NewScript(`
+local key = KEYS[1]
+local algorithm = tonumber(ARGV[1])
+
+if algorithm == 1 then
+	-- sliding window log: remove the logged request.
+	redis.call("ZREM", key, ARGV[3])
+elseif algorithm == 2 then
+	-- fixed window: the count is restored only inside the current window.
+	local count = tonumber(redis.call("GET", key))
+	if count ~= nil and count > 0 then
+		redis.call("DECR", key)
+	end
+else
+	local tokens = tonumber(redis.call("HGET", key, "tokens"))
+	if tokens ~= nil then
+		redis.call("HSET", key, "tokens", tostring(math.min(tonumber(ARGV[2]), tokens + 1)))
+	end
+end
+
+return 1
+`)
+
+// Store is the redis-based rate limiter Store.
+// It supports all the rate limiting algorithms.
+type Store struct {
+	client redis.Scripter
+	prefix string
+	// Timeout of each redis call, defaults to 3 seconds.
+	Timeout time.Duration
+	// ZeroRateExpiration is the lifetime of the state of a token bucket
+	// with a zero rate, e.g. rate.Limit(0, burst), which is never refilled.
+	// The client's burst is restored when it expires, defaults to 24 hours.
+	ZeroRateExpiration time.Duration
+
+	seq uint64 // sequence of the sliding window log members.
+}
+
+var (
+	_ rate.Store    = (*Store)(nil)
+	_ rate.Refunder = (*Store)(nil)
+)
+
+// New returns a new redis rate limiter Store based on a go-redis client,
+// both redis.Client and redis.ClusterClient are accepted.
+// The "prefix" is prepended to the client's ID to form the redis key,
+// if empty then the `DefaultPrefix` is used instead.
+//
+// Usage:
+//
+//	import rateredis "github.com/kataras/iris/v12/middleware/rate/redis"
+//	[...]
+//	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379"})
+//	limiter := rate.Limit(1, 5, rate.SetStore(rateredis.New(client, "")))
+func New(client redis.Scripter, prefix string) *Store {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	return &Store{
+		client:             client,
+		prefix:             prefix,
+		Timeout:            3 * time.Second,
+		ZeroRateExpiration: defaultZeroRateExpiration,
+	}
+}
+
+func (s *Store) makeKey(c *rate.Client, p rate.Policy) string {
+	key := s.prefix + c.ID
+	if p.Name != "" {
+		key += ":" + p.Name
+	}
+
+	return key
+}
+
+// Allow implements the rate.Store interface.
+// The client's state is updated atomically on the redis server.
+func (s *Store) Allow(c *rate.Client, p rate.Policy) (rate.Result, error) {
+	ctx, cancel := stdContext.WithTimeout(stdContext.Background(), s.Timeout)
+	defer cancel()
+
+	keys := []string{s.makeKey(c, p)}
+	window := p.Window.Milliseconds()
+
+	var (
+		cmd *redis.Cmd
+		ref string // the member of the sliding window log.
+	)
+	switch p.Algorithm {
+	case rate.AlgorithmSlidingWindowLog:
+		member := strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + strconv.FormatUint(atomic.AddUint64(&s.seq, 1), 10)
+		cmd = slidingWindowScript.Run(ctx, s.client, keys, p.Limit, window, member)
+		ref = member
+	case rate.AlgorithmFixedWindow:
+		cmd = fixedWindowScript.Run(ctx, s.client, keys, p.Limit, window)
+	default:
+		limit := strconv.FormatFloat(float64(p.TokenRate()), 'f', -1, 64)
+		expiration := s.ZeroRateExpiration
+		if expiration <= 0 {
+			expiration = defaultZeroRateExpiration
+		}
+
+		cmd = tokenBucketScript.Run(ctx, s.client, keys, limit, p.Limit, expiration.Milliseconds())
+	}
+
+	values, err := cmd.Int64Slice()
+	if err != nil {
+		return rate.Result{}, err
+	}
+
+	result := rate.Result{
+		Policy:    p,
+		Allowed:   values[0] == 1,
+		Remaining: int(values[1]),
+		Reset:     time.Duration(values[3]) * time.Millisecond,
+	}
+
+	if values[2] > 0 {
+		result.RetryAfter = time.Duration(values[2]) * time.Millisecond
+	}
+
+	if result.Allowed {
+		result.Ref = ref
+	}
+
+	return result, nil
+}
+
+// Refund implements the rate.Refunder interface.
+// It gives back the request consumed by the allowed result "r" of an `Allow` call,
+// the request of a sliding window log is identified by its `Result.Ref`.
+func (s *Store) Refund(c *rate.Client, r rate.Result) error {
+	ctx, cancel := stdContext.WithTimeout(stdContext.Background(), s.Timeout)
+	defer cancel()
+
+	p := r.Policy
+	keys := []string{s.makeKey(c, p)}
+	return refundScript.Run(ctx, s.client, keys, int(p.Algorithm), p.Limit, r.Ref).Err()
+}
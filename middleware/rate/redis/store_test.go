@@ -0,0 +1,103 @@
+package redis_test
+
+import (
+	stdContext "context"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/middleware/rate"
+	rateredis "github.com/kataras/iris/v12/middleware/rate/redis"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func newClient(t *testing.T) *redis.Client {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(stdContext.Background()).Err(); err != nil {
+		client.Close()
+		t.Skipf("redis server is not available at %s: %v", addr, err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestStoreZeroRate(t *testing.T) {
+	client := newClient(t)
+	// a fresh state on each run.
+	prefix := "iris.ratelimit.test." + strconv.FormatInt(time.Now().UnixNano(), 10) + "."
+
+	store := rateredis.New(client, prefix)
+	store.ZeroRateExpiration = time.Minute
+
+	app := iris.New()
+	app.Use(func(ctx iris.Context) {
+		rate.SetIdentifier(ctx, "client")
+		ctx.Next()
+	})
+	app.Get("/memory", rate.Limit(0, 2), func(ctx iris.Context) {
+		ctx.WriteString("OK")
+	})
+	app.Get("/redis", rate.Limit(0, 2, rate.SetStore(store)), func(ctx iris.Context) {
+		ctx.WriteString("OK")
+	})
+
+	e := httptest.New(t, app)
+	// the bucket is never refilled: the burst is allowed and then all requests are denied.
+	for _, expected := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests} {
+		e.GET("/memory").Expect().Status(expected)
+		e.GET("/redis").Expect().Status(expected)
+	}
+
+	// the consumed tokens are kept for the ZeroRateExpiration.
+	ttl, err := client.PTTL(stdContext.Background(), prefix+"client").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ttl <= 50*time.Second || ttl > time.Minute {
+		t.Fatalf("expected the key to expire in about a minute but got: %s", ttl)
+	}
+}
+
+func TestStoreRefund(t *testing.T) {
+	client := newClient(t)
+	prefix := "iris.ratelimit.test." + strconv.FormatInt(time.Now().UnixNano(), 10) + "."
+
+	app := iris.New()
+	app.Use(func(ctx iris.Context) {
+		rate.SetIdentifier(ctx, "client")
+		ctx.Next()
+	})
+	app.Get("/", rate.LimitPolicies([]rate.Policy{
+		rate.FixedWindow("short", 1, time.Hour),
+		rate.SlidingWindow("long", 3, time.Hour),
+	}, rate.SetStore(rateredis.New(client, prefix))), func(ctx iris.Context) {
+		ctx.WriteString("OK")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(http.StatusOK)
+	e.GET("/").Expect().Status(http.StatusTooManyRequests)
+	e.GET("/").Expect().Status(http.StatusTooManyRequests)
+
+	// the denied requests are removed from the sliding window log.
+	n, err := client.ZCard(stdContext.Background(), prefix+"client:long").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 1 {
+		t.Fatalf("expected 1 logged request but got %d", n)
+	}
+}
@@ -0,0 +1,224 @@
+package rate
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type (
+	// Store is the interface which keeps the rate limiting state of the clients.
+	// The default Store keeps the state in memory, next to the Client itself.
+	// Use the `SetStore` option to share the state between many servers,
+	// e.g. through the rate/redis sub-package.
+	Store interface {
+		// Allow consumes a request of the given client against the policy "p"
+		// and reports the outcome. The client's state is identified by its ID and the policy's name.
+		Allow(c *Client, p Policy) (Result, error)
+	}
+
+	// Result holds the outcome of a `Store.Allow` call.
+	Result struct {
+		// Policy is the policy which this result is about.
+		Policy Policy
+		// Allowed reports whether the request is allowed.
+		Allowed bool
+		// Remaining is the number of requests which are allowed right after this one.
+		Remaining int
+		// RetryAfter is the time to wait until the next request is allowed,
+		// it is zero when the request is allowed.
+		RetryAfter time.Duration
+		// Reset is the time until the client's quota is restored.
+		Reset time.Duration
+		// Ref identifies the request which was consumed by an allowed result
+		// on the Store, if the Store requires it to refund it, see Refunder.
+		Ref string
+
+		// refund gives back the request consumed by an allowed result
+		// of the in-memory store.
+		refund func()
+	}
+
+	// Refunder can be optionally implemented by a Store.
+	// When a request is denied by one policy the limiter gives back the request
+	// which was consumed by the policies that allowed it,
+	// so a denied request does not count against the client's quota.
+	Refunder interface {
+		// Refund gives back the request consumed by the allowed result "r"
+		// of an `Allow` call of the given client.
+		Refund(c *Client, r Result) error
+	}
+)
+
+// SetStore is an `Option` that can be passed at the `Limit` package-level function.
+// It sets a custom Store which keeps the rate limiting state of the clients.
+// If the Store fails the request is allowed and the error is logged.
+func SetStore(store Store) Option {
+	return func(l *Limiter) {
+		if store != nil {
+			l.store = store
+		}
+	}
+}
+
+// memoryStore is the default Store, it keeps the state in memory.
+type memoryStore struct{}
+
+var _ Store = memoryStore{}
+
+type (
+	slidingWindowState struct {
+		log []time.Time
+	}
+
+	fixedWindowState struct {
+		start time.Time
+		count int
+	}
+)
+
+// Allow implements the Store interface for the in-memory state of the client.
+func (memoryStore) Allow(c *Client, p Policy) (Result, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.states == nil {
+		c.states = make(map[string]any)
+	}
+
+	var result Result
+	switch p.Algorithm {
+	case AlgorithmSlidingWindowLog:
+		state, ok := c.states[p.Name].(*slidingWindowState)
+		if !ok {
+			state = new(slidingWindowState)
+			c.states[p.Name] = state
+		}
+
+		result = state.allow(p, now)
+		if result.Allowed {
+			result.refund = func() {
+				c.mu.Lock()
+				state.remove(now)
+				c.mu.Unlock()
+			}
+		}
+	case AlgorithmFixedWindow:
+		state, ok := c.states[p.Name].(*fixedWindowState)
+		if !ok {
+			state = new(fixedWindowState)
+			c.states[p.Name] = state
+		}
+
+		result = state.allow(p, now)
+		if result.Allowed {
+			start := state.start
+			result.refund = func() {
+				c.mu.Lock()
+				if state.start.Equal(start) && state.count > 0 {
+					state.count--
+				}
+				c.mu.Unlock()
+			}
+		}
+	default:
+		limiter, ok := c.states[p.Name].(*rate.Limiter)
+		if !ok {
+			limiter = rate.NewLimiter(p.TokenRate(), p.Limit)
+			c.states[p.Name] = limiter
+		}
+
+		result = allowTokenBucket(limiter, now)
+	}
+
+	result.Policy = p
+	return result, nil
+}
+
+func allowTokenBucket(limiter *rate.Limiter, now time.Time) Result {
+	burst := limiter.Burst()
+	untilFull := func(tokens float64) time.Duration {
+		return durationFromTokens(limiter.Limit(), float64(burst)-tokens)
+	}
+
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		// burst size is zero, no request is ever allowed.
+		return Result{}
+	}
+
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return Result{RetryAfter: delay, Reset: untilFull(limiter.TokensAt(now))}
+	}
+
+	tokens := limiter.TokensAt(now)
+	return Result{
+		Allowed:   true,
+		Remaining: max(int(tokens), 0),
+		Reset:     untilFull(tokens),
+		refund: func() {
+			reservation.CancelAt(now)
+		},
+	}
+}
+
+func (s *slidingWindowState) allow(p Policy, now time.Time) Result {
+	// remove the requests which are out of the window.
+	since := now.Add(-p.Window)
+	n := 0
+	for _, t := range s.log {
+		if t.After(since) {
+			s.log[n] = t
+			n++
+		}
+	}
+	s.log = s.log[:n]
+
+	var result Result
+	if len(s.log) < p.Limit {
+		s.log = append(s.log, now)
+		result.Allowed = true
+		result.Remaining = p.Limit - len(s.log)
+	}
+
+	if len(s.log) > 0 {
+		// the oldest request leaves the window.
+		result.Reset = s.log[0].Add(p.Window).Sub(now)
+		if !result.Allowed {
+			result.RetryAfter = result.Reset
+		}
+	}
+
+	return result
+}
+
+// remove removes the logged request of "t".
+func (s *slidingWindowState) remove(t time.Time) {
+	for i := len(s.log) - 1; i >= 0; i-- {
+		if s.log[i].Equal(t) {
+			s.log = append(s.log[:i], s.log[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *fixedWindowState) allow(p Policy, now time.Time) Result {
+	if s.start.IsZero() || !now.Before(s.start.Add(p.Window)) {
+		s.start = now
+		s.count = 0
+	}
+
+	result := Result{Reset: s.start.Add(p.Window).Sub(now)}
+	if s.count < p.Limit {
+		s.count++
+		result.Allowed = true
+		result.Remaining = p.Limit - s.count
+	} else {
+		result.RetryAfter = result.Reset
+	}
+
+	return result
+}
@@ -0,0 +1,206 @@
+// Package timeout implements a middleware which sets a deadline to the requests of a route or a party.
+// The deadline is set on the request's context, so it reaches the `context.Context` input
+// of the dependency injection handlers and the outgoing calls (e.g. x/client) which receive it.
+// When it expires a timeout response is sent, even if the handler is still running.
+package timeout
+
+import (
+	"bytes"
+	stdContext "context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+func init() {
+	context.SetHandlerName("iris/middleware/timeout.*", "iris.timeout")
+}
+
+// Options holds the configuration of the timeout middleware.
+type Options struct {
+	// Timeout is the maximum duration of the next handlers.
+	// Defaults to 30 seconds.
+	Timeout time.Duration
+	// StatusCode is the status code of the timeout response,
+	// e.g. 504 Gateway Timeout for proxies.
+	// Defaults to 503 Service Unavailable.
+	StatusCode int
+	// Message is the text body of the timeout response.
+	// Defaults to the status code's text.
+	Message string
+	// OnTimeout, if not nil, writes the timeout response instead of the StatusCode and Message.
+	// It's fired on its own goroutine while the handler may still run,
+	// so it receives the response writer and the request instead of the Context.
+	// Set a Content-Length header, so the client can read the response before the handler returns.
+	OnTimeout func(w http.ResponseWriter, r *http.Request)
+}
+
+// New returns a new timeout middleware.
+//
+// The response of the next handlers is buffered and sent when they return.
+// If they don't return on time the buffered response is dropped,
+// their next writes fail with `http.ErrHandlerTimeout`
+// and the timeout response is sent instead.
+// Handlers should return as soon as the `Context.Done()` channel is closed.
+// Hijacking and streaming (e.g. Flush) are not supported by the next handlers.
+//
+// Usage:
+// api.Use(timeout.New(timeout.Options{Timeout: 5 * time.Second, StatusCode: iris.StatusGatewayTimeout}))
+// api.Get("/report", timeout.Handler(30*time.Second), report)
+func New(opts Options) context.Handler {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	if opts.StatusCode <= 0 {
+		opts.StatusCode = http.StatusServiceUnavailable
+	}
+
+	if opts.Message == "" {
+		opts.Message = http.StatusText(opts.StatusCode)
+	}
+
+	if opts.OnTimeout == nil {
+		opts.OnTimeout = func(w http.ResponseWriter, r *http.Request) {
+			// The Content-Length lets the client read the response
+			// before the handler returns.
+			w.Header().Set(context.ContentTypeHeaderKey, context.ContentTextHeaderValue+"; charset=utf-8")
+			w.Header().Set(context.ContentLengthHeaderKey, strconv.Itoa(len(opts.Message)))
+			w.WriteHeader(opts.StatusCode)
+			w.Write([]byte(opts.Message))
+		}
+	}
+
+	return func(ctx *context.Context) {
+		serve(ctx, opts)
+	}
+}
+
+// Handler is a shortcut of New(Options{Timeout: timeout}).
+func Handler(timeout time.Duration) context.Handler {
+	return New(Options{Timeout: timeout})
+}
+
+func serve(ctx *context.Context, opts Options) {
+	parent := ctx.Request().Context()
+	deadlineCtx, cancel := stdContext.WithTimeout(parent, opts.Timeout)
+	defer cancel()
+
+	r := ctx.Request().WithContext(deadlineCtx)
+	ctx.ResetRequest(r)
+
+	// The next handlers write to a detached buffer,
+	// so the timeout response can be written to the real one concurrently.
+	buf := &bufferedResponse{header: make(http.Header)}
+	tw := context.AcquireResponseWriter()
+	tw.BeginResponse(buf)
+	w := ctx.SwapResponseWriter(tw)
+
+	// writeTimeout must be called under the buffer's lock.
+	writeTimeout := func() {
+		buf.timedOut = true
+		opts.OnTimeout(w, r)
+		w.Flush()
+	}
+
+	stop := stdContext.AfterFunc(deadlineCtx, func() {
+		if deadlineCtx.Err() != stdContext.DeadlineExceeded {
+			return // the client has gone.
+		}
+
+		buf.mu.Lock()
+		if !buf.done {
+			writeTimeout()
+		}
+		buf.mu.Unlock()
+	})
+
+	completed := false
+	defer func() {
+		stop()
+
+		buf.mu.Lock()
+		buf.done = true
+		if completed && !buf.timedOut && deadlineCtx.Err() == stdContext.DeadlineExceeded {
+			// The handler returned on the deadline, before the timeout was written.
+			writeTimeout()
+		}
+		timedOut := buf.timedOut
+		buf.mu.Unlock()
+
+		// On panic the buffered response is dropped,
+		// so the recover middleware can write its own.
+		if completed && !timedOut {
+			// Flush the current writer, e.g. a recorder, to the buffer.
+			ctx.ResponseWriter().FlushResponse()
+			buf.copyTo(w)
+		}
+
+		ctx.ResetResponseWriter(w)
+		// The next handlers have returned, release the detached writer.
+		tw.EndResponse()
+		ctx.ResetRequest(ctx.Request().WithContext(parent))
+	}()
+
+	ctx.Next()
+	completed = true
+}
+
+// bufferedResponse is the http.ResponseWriter of the next handlers.
+type bufferedResponse struct {
+	header http.Header
+
+	mu         sync.Mutex
+	statusCode int
+	body       bytes.Buffer
+	done       bool
+	timedOut   bool
+}
+
+var _ http.ResponseWriter = (*bufferedResponse)(nil)
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(statusCode int) {
+	b.mu.Lock()
+	if !b.timedOut && b.statusCode == 0 {
+		b.statusCode = statusCode
+	}
+	b.mu.Unlock()
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if b.statusCode == 0 {
+		b.statusCode = http.StatusOK
+	}
+
+	return b.body.Write(p)
+}
+
+// copyTo writes the buffered headers, status code and body to "w".
+func (b *bufferedResponse) copyTo(w context.ResponseWriter) {
+	header := w.Header()
+	for k, v := range b.header {
+		header[k] = v
+	}
+
+	if b.statusCode != 0 {
+		w.WriteHeader(b.statusCode)
+	}
+
+	if b.body.Len() > 0 {
+		w.Write(b.body.Bytes())
+	}
+}
@@ -0,0 +1,121 @@
+package timeout_test
+
+import (
+	stdContext "context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	irisHttptest "github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/middleware/timeout"
+	"github.com/kataras/iris/v12/x/client"
+)
+
+func TestTimeout(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+
+	clientErr := make(chan error, 1)
+
+	app := iris.New()
+	app.Get("/fast", timeout.Handler(time.Second), func(ctx iris.Context) {
+		ctx.Header("X-Fast", "true")
+		ctx.StatusCode(iris.StatusCreated)
+		ctx.WriteString("fast")
+	})
+	app.Get("/slow", timeout.Handler(50*time.Millisecond), func(ctx iris.Context) {
+		<-ctx.Done()
+		ctx.WriteString("late")
+	})
+	app.Get("/record", timeout.Handler(time.Second), func(ctx iris.Context) {
+		ctx.Record()
+		ctx.WriteString("recorded")
+	})
+
+	gateway := app.Party("/gateway")
+	gateway.Use(timeout.New(timeout.Options{
+		Timeout:    50 * time.Millisecond,
+		StatusCode: iris.StatusGatewayTimeout,
+		Message:    "upstream timeout",
+	}))
+	gateway.Get("/", func(ctx iris.Context) {
+		c := client.New(client.BaseURL(upstream.URL))
+		_, err := c.Do(ctx, http.MethodGet, "/", nil)
+		clientErr <- err
+	})
+	gateway.ConfigureContainer().Get("/hero", func(ctx stdContext.Context) string {
+		if _, ok := ctx.Deadline(); !ok {
+			return "no deadline"
+		}
+
+		return "deadline"
+	})
+
+	e := irisHttptest.New(t, app)
+
+	e.GET("/fast").Expect().Status(irisHttptest.StatusCreated).
+		Header("X-Fast").IsEqual("true")
+	e.GET("/fast").Expect().Body().IsEqual("fast")
+	e.GET("/record").Expect().Status(irisHttptest.StatusOK).Body().IsEqual("recorded")
+
+	e.GET("/slow").Expect().Status(irisHttptest.StatusServiceUnavailable).
+		Body().IsEqual("Service Unavailable")
+
+	// The deadline is propagated to the x/client calls
+	// and the context.Context input of the dependency injection handlers.
+	e.GET("/gateway").Expect().Status(irisHttptest.StatusGatewayTimeout).
+		Body().IsEqual("upstream timeout")
+	if err := <-clientErr; !errors.Is(err, stdContext.DeadlineExceeded) {
+		t.Fatalf("expected error: %v but got: %v", stdContext.DeadlineExceeded, err)
+	}
+
+	e.GET("/gateway/hero").Expect().Status(irisHttptest.StatusOK).Body().IsEqual("deadline")
+}
+
+func TestTimeoutHandlerRunning(t *testing.T) {
+	unblock := make(chan struct{})
+	done := make(chan struct{})
+
+	app := iris.New()
+	app.Get("/", timeout.Handler(50*time.Millisecond), func(ctx iris.Context) {
+		defer close(done)
+
+		// Ignores the deadline.
+		<-unblock
+		if _, err := ctx.WriteString("late"); !errors.Is(err, http.ErrHandlerTimeout) {
+			t.Errorf("expected error: %v but got: %v", http.ErrHandlerTimeout, err)
+		}
+	})
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	// The timeout response is sent while the handler is still running.
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusServiceUnavailable || string(body) != "Service Unavailable" {
+		t.Fatalf("unexpected response: %d: %s", resp.StatusCode, body)
+	}
+
+	close(unblock)
+	<-done
+}
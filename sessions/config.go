@@ -57,6 +57,35 @@ type (
 		// Defaults to infinitive/unlimited life duration(0).
 		Expires time.Duration
 
+		// IdleTimeout, if positive, destroys a session which is not used
+		// for that duration. Each request of the session shifts its server-side expiration,
+		// up to its AbsoluteTimeout. The cookie's lifetime is still controlled by the Expires field.
+		//
+		// Defaults to zero (disabled).
+		IdleTimeout time.Duration
+		// AbsoluteTimeout, if positive, destroys a session when that duration
+		// passes since its creation, no matter how active it is.
+		// The creation time is stored among the session's values,
+		// so it's enforced by all the databases and across server restarts.
+		//
+		// Defaults to zero (disabled).
+		AbsoluteTimeout time.Duration
+
+		// Fingerprint, if not nil, binds a session to the fingerprint of the client
+		// which created it, e.g. its user agent and its IP address.
+		// Requests of the session with a different fingerprint
+		// are handled based on the FingerprintMismatch policy.
+		// See the `FingerprintUserAgent`, `FingerprintIP` and `FingerprintUserAgentIP`
+		// package-level functions.
+		//
+		// Defaults to nil (disabled).
+		Fingerprint func(ctx *context.Context) string
+		// FingerprintMismatch is the policy for requests of a session
+		// with a different fingerprint than the one stored on its creation.
+		//
+		// Defaults to FingerprintDestroy.
+		FingerprintMismatch FingerprintMismatchPolicy
+
 		// SessionIDGenerator can be set to a function which
 		// return a unique session id.
 		// By default we will use a uuid impl package to generate
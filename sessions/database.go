@@ -17,10 +17,10 @@ import (
 var ErrNotImplemented = errors.New("not implemented yet")
 
 // Database is the interface which all session databases should implement
-// By design it doesn't support any type of cookie session like other frameworks.
-// I want to protect you, believe me.
 // The scope of the database is to store somewhere the sessions in order to
 // keep them after restarting the server, nothing more.
+// The client-side cookie database keeps them on the client instead,
+// see the `DatabaseRequestStarter` interface.
 //
 // Synchronization are made automatically, you can register one using `UseDatabase`.
 //
@@ -66,10 +66,19 @@ type Database interface {
 // can implement. It contains a single EndRequest method which is fired
 // on the very end of the request life cycle. It should be used to Flush
 // any local session's values to the client.
+// The session is nil when the request was rejected, see Config.FingerprintMismatch.
 type DatabaseRequestHandler interface {
 	EndRequest(ctx *context.Context, session *Session)
 }
 
+// DatabaseRequestStarter is an optional interface that a sessions database
+// can implement. It contains a single BeginRequest method which is fired
+// when the session ID of the request is known, before the session is read.
+// It should be used to load the session's values from the client.
+type DatabaseRequestStarter interface {
+	BeginRequest(ctx *context.Context, sid string)
+}
+
 type mem struct {
 	values map[string]*memstore.Store
 	mu     sync.RWMutex
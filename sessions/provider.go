@@ -16,6 +16,7 @@ type (
 		sessions         map[string]*Session
 		db               Database
 		dbRequestHandler DatabaseRequestHandler
+		dbRequestStarter DatabaseRequestStarter
 		destroyListeners []DestroyListener
 	}
 )
@@ -41,6 +42,9 @@ func (p *provider) RegisterDatabase(db Database) {
 	if dbreq, ok := db.(DatabaseRequestHandler); ok {
 		p.dbRequestHandler = dbreq
 	}
+	if dbreq, ok := db.(DatabaseRequestStarter); ok {
+		p.dbRequestStarter = dbreq
+	}
 	p.mu.Unlock()
 }
 
@@ -89,6 +93,12 @@ func (p *provider) Init(man *Sessions, sid string, expires time.Duration) *Sessi
 	return newSession
 }
 
+func (p *provider) BeginRequest(ctx *context.Context, sid string) {
+	if p.dbRequestStarter != nil {
+		p.dbRequestStarter.BeginRequest(ctx, sid)
+	}
+}
+
 func (p *provider) EndRequest(ctx *context.Context, session *Session) {
 	if p.dbRequestHandler != nil {
 		p.dbRequestHandler.EndRequest(ctx, session)
@@ -161,10 +171,14 @@ func (p *provider) fireDestroy(sid string) {
 // Destroy destroys the session, removes all sessions and flash values,
 // the session itself and updates the registered session databases,
 // this called from sessionManager which removes the client's cookie also.
+// A session which is not loaded in memory, e.g. after a restart,
+// is removed from the database.
 func (p *provider) Destroy(sid string) {
 	p.mu.Lock()
 	if sess, found := p.sessions[sid]; found {
 		p.deleteSession(sess)
+	} else {
+		p.db.Release(sid)
 	}
 	p.mu.Unlock()
 }
@@ -188,22 +202,54 @@ func (p *provider) deleteSession(sess *Session) {
 	p.fireDestroy(sid)
 }
 
-/*
-func (p *provider) regenerateID(ctx *context.Context, oldsid string) {
-	p.mu.RLock()
-	sess, ok := p.sessions[oldsid]
-	p.mu.RUnlock()
+// Regenerate moves the values of the session to the "newsid" database entry,
+// releases the old one and registers the session under its new id.
+func (p *provider) Regenerate(sess *Session, newsid string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	if ok {
-		newsid := sess.Man.config.SessionIDGenerator(ctx)
-		sess.mu.Lock()
-		sess.sid = newsid
-		sess.mu.Unlock()
+	oldsid := sess.sid
+	if _, found := p.sessions[oldsid]; !found {
+		return ErrNotFound
+	}
 
-		p.mu.Lock()
-		p.sessions[newsid] = sess
-		delete(p.sessions, oldsid)
-		p.mu.Unlock()
+	var expires time.Duration
+	if !sess.Lifetime.IsZero() {
+		expires = sess.Lifetime.DurationUntilExpiration()
+	}
+
+	p.db.Acquire(newsid, expires)
+	// keep any values that the database sets on Acquire, e.g. the redis session id.
+	acquired := make(map[string]struct{})
+	p.db.Visit(newsid, func(key string, _ interface{}) {
+		acquired[key] = struct{}{}
+	})
+
+	// collect first, databases may not allow writes inside a Visit.
+	values := make(map[string]interface{})
+	if err := p.db.Visit(oldsid, func(key string, value interface{}) {
+		if _, ok := acquired[key]; !ok {
+			values[key] = value
+		}
+	}); err != nil {
+		p.db.Release(newsid)
+		return err
 	}
+
+	for key, value := range values {
+		if err := p.db.Set(newsid, key, value, expires, false); err != nil {
+			p.db.Release(newsid)
+			return err
+		}
+	}
+
+	p.db.Release(oldsid)
+
+	sess.mu.Lock()
+	sess.sid = newsid
+	sess.mu.Unlock()
+
+	p.sessions[newsid] = sess
+	delete(p.sessions, oldsid)
+	return nil
 }
-*/
@@ -0,0 +1,175 @@
+package sessions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+// FingerprintMismatchPolicy is the type of the Config.FingerprintMismatch field.
+type FingerprintMismatchPolicy uint8
+
+const (
+	// FingerprintDestroy destroys the session and starts a new one for the request.
+	// This is the default policy.
+	FingerprintDestroy FingerprintMismatchPolicy = iota
+	// FingerprintRenew keeps the session for its original client
+	// and starts a new one for the request.
+	FingerprintRenew
+	// FingerprintReject keeps the session for its original client
+	// and stops the request with 403 Forbidden.
+	FingerprintReject
+)
+
+// FingerprintUserAgent is a Config.Fingerprint which binds
+// a session to the user agent of its client.
+func FingerprintUserAgent(ctx *context.Context) string {
+	return ctx.GetHeader("User-Agent")
+}
+
+// FingerprintIP is a Config.Fingerprint which binds
+// a session to the IP address of its client.
+// Note that the IP address of mobile clients may change often.
+func FingerprintIP(ctx *context.Context) string {
+	return ctx.RemoteAddr()
+}
+
+// FingerprintUserAgentIP is a Config.Fingerprint which binds
+// a session to both the user agent and the IP address of its client.
+func FingerprintUserAgentIP(ctx *context.Context) string {
+	return FingerprintUserAgent(ctx) + "|" + FingerprintIP(ctx)
+}
+
+// The session values which are used internally to enforce
+// the absolute timeout and the fingerprint binding on all databases.
+// They are not visible through the Session's GetAll, Visit and Len methods.
+const (
+	reservedKeyPrefix = "_iris.session."
+	createdKey        = reservedKeyPrefix + "created"
+	fingerprintKey    = reservedKeyPrefix + "fingerprint"
+)
+
+func isReservedKey(key string) bool {
+	return strings.HasPrefix(key, reservedKeyPrefix)
+}
+
+// guarded reports whether any of the idle timeout, absolute timeout
+// and the fingerprint binding features is enabled.
+func (c Config) guarded() bool {
+	return c.IdleTimeout > 0 || c.AbsoluteTimeout > 0 || c.Fingerprint != nil
+}
+
+// lifetime returns the server-side lifetime of a new session.
+func (c Config) lifetime() time.Duration {
+	expires := c.Expires
+	for _, d := range []time.Duration{c.IdleTimeout, c.AbsoluteTimeout} {
+		if d > 0 && (expires <= 0 || d < expires) {
+			expires = d
+		}
+	}
+
+	return expires
+}
+
+// ErrEmptySessionID is returned from `Session.Regenerate`
+// when the Config.SessionIDGenerator failed to generate a new session id.
+var ErrEmptySessionID = errors.New("sessions: empty session id")
+
+type verification uint8
+
+const (
+	verified verification = iota
+	verificationDestroy
+	verificationRenew
+	verificationReject
+)
+
+func (s *Sessions) fingerprint(ctx *context.Context) string {
+	sum := sha256.Sum256([]byte(s.config.Fingerprint(ctx)))
+	return hex.EncodeToString(sum[:])
+}
+
+// guard stores the creation time and the fingerprint of a new session.
+func (s *Sessions) guard(ctx *context.Context, sess *Session) {
+	sess.set(createdKey, time.Now().Format(time.RFC3339Nano), false)
+	if s.config.Fingerprint != nil {
+		sess.set(fingerprintKey, s.fingerprint(ctx), false)
+	}
+}
+
+// verify reports whether an existing session can be used by the request.
+// A session without a creation time is never accepted, so a client can not
+// pick its own session id (session fixation).
+func (s *Sessions) verify(ctx *context.Context, sess *Session) (verification, time.Time) {
+	created, err := time.Parse(time.RFC3339Nano, sess.GetString(createdKey))
+	if err != nil {
+		return verificationDestroy, created
+	}
+
+	if sess.Lifetime.HasExpired() {
+		return verificationDestroy, created
+	}
+
+	if s.config.AbsoluteTimeout > 0 && !time.Now().Before(created.Add(s.config.AbsoluteTimeout)) {
+		return verificationDestroy, created
+	}
+
+	if s.config.Fingerprint != nil && sess.GetString(fingerprintKey) != s.fingerprint(ctx) {
+		s.config.Logger.Debugf("sessions: fingerprint mismatch from: %s", ctx.RemoteAddr())
+
+		switch s.config.FingerprintMismatch {
+		case FingerprintRenew:
+			return verificationRenew, created
+		case FingerprintReject:
+			return verificationReject, created
+		default:
+			return verificationDestroy, created
+		}
+	}
+
+	return verified, created
+}
+
+// touch shifts the expiration of a verified session based on the idle timeout,
+// without exceeding its absolute timeout.
+func (s *Sessions) touch(sess *Session, created time.Time) {
+	expires := s.config.IdleTimeout
+	if expires <= 0 {
+		return
+	}
+
+	if s.config.AbsoluteTimeout > 0 {
+		if remaining := time.Until(created.Add(s.config.AbsoluteTimeout)); remaining < expires {
+			expires = remaining
+		}
+	}
+
+	if err := s.provider.UpdateExpiration(sess.sid, expires); err != nil && !errors.Is(err, ErrNotImplemented) {
+		s.config.Logger.Debugf("sessions: unable to shift the idle expiration: %v", err)
+	}
+}
+
+// check verifies the existing session of the request, see Config.guarded.
+// It returns a nil session when a new one should be started
+// and false when the request was rejected.
+func (s *Sessions) check(ctx *context.Context, sess *Session) (*Session, bool) {
+	v, created := s.verify(ctx, sess)
+	switch v {
+	case verified:
+		s.touch(sess, created)
+		return sess, true
+	case verificationRenew:
+		return nil, true
+	case verificationReject:
+		ctx.StopWithStatus(http.StatusForbidden)
+		return nil, false
+	default:
+		s.provider.Destroy(sess.sid)
+		return nil, true
+	}
+}
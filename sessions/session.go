@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"sync"
 
+	"github.com/kataras/iris/v12/context"
 	"github.com/kataras/iris/v12/core/memstore"
 )
 
@@ -18,7 +19,10 @@ type (
 		sid     string
 		isNew   bool
 		flashes map[string]*flashMessage
-		mu      sync.RWMutex // for flashes.
+		mu      sync.RWMutex // for flashes and info.
+		// the user binding, see BindUser.
+		info       *SessionInfo
+		infoLoaded bool
 		// Lifetime it contains the expiration data, use it for read-only information.
 		// See `Sessions.UpdateExpiration` too.
 		Lifetime *memstore.LifeTime
@@ -47,6 +51,27 @@ func (s *Session) Destroy() {
 	s.provider.Destroy(s.sid)
 }
 
+// Regenerate replaces the session's ID with a new one and sends it to the client,
+// the session keeps its values, flash messages and lifetime.
+// Call it on privilege changes, e.g. right after sign in,
+// to protect against session fixation attacks.
+//
+// It returns ErrNotFound if the session has been destroyed.
+func (s *Session) Regenerate(ctx *context.Context, cookieOptions ...context.CookieOption) error {
+	sid := s.Man.config.SessionIDGenerator(ctx)
+	if sid == "" {
+		return ErrEmptySessionID
+	}
+
+	oldsid := s.sid
+	if err := s.provider.Regenerate(s, sid); err != nil {
+		return err
+	}
+
+	s.Man.updateCookie(ctx, sid, s.Man.config.Expires, cookieOptions...)
+	return s.regenerated(oldsid)
+}
+
 // ID returns the session's ID.
 func (s *Session) ID() string {
 	return s.sid
@@ -489,7 +514,9 @@ func (s *Session) GetAll() map[string]interface{} {
 	items := make(map[string]interface{}, s.provider.db.Len(s.sid))
 	s.mu.RLock()
 	s.provider.db.Visit(s.sid, func(key string, value interface{}) {
-		items[key] = value
+		if !isReservedKey(key) {
+			items[key] = value
+		}
 	})
 	s.mu.RUnlock()
 	return items
@@ -510,12 +537,28 @@ func (s *Session) GetFlashes() map[string]interface{} {
 
 // Visit loops each of the entries and calls the callback function func(key, value).
 func (s *Session) Visit(cb func(k string, v interface{})) {
-	s.provider.db.Visit(s.sid, cb)
+	s.provider.db.Visit(s.sid, func(key string, value interface{}) {
+		if !isReservedKey(key) {
+			cb(key, value)
+		}
+	})
 }
 
 // Len returns the total number of stored values in this session.
 func (s *Session) Len() int {
-	return s.provider.db.Len(s.sid)
+	return s.provider.db.Len(s.sid) - len(s.reserved())
+}
+
+// reserved returns the internal values of the session,
+// see Config.guarded and BindUser.
+func (s *Session) reserved() map[string]interface{} {
+	items := make(map[string]interface{})
+	s.provider.db.Visit(s.sid, func(key string, value interface{}) {
+		if isReservedKey(key) {
+			items[key] = value
+		}
+	})
+	return items
 }
 
 func (s *Session) set(key string, value interface{}, immutable bool) {
@@ -583,7 +626,12 @@ func (s *Session) DeleteFlash(key string) {
 
 // Clear removes all entries.
 func (s *Session) Clear() {
+	reserved := s.reserved()
 	s.provider.db.Clear(s.sid)
+
+	for key, value := range reserved {
+		s.set(key, value, false)
+	}
 }
 
 // ClearFlashes removes all flash messages.
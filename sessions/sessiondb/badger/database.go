@@ -109,10 +109,43 @@ func (db *Database) Acquire(sid string, expires time.Duration) memstore.LifeTime
 	return memstore.LifeTime{} // session manager will handle the rest.
 }
 
-// OnUpdateExpiration not implemented here, yet.
-// Note that this error will not be logged, callers should catch it manually.
+// OnUpdateExpiration re-sets the ttl of the session's entries.
 func (db *Database) OnUpdateExpiration(sid string, newExpires time.Duration) error {
-	return sessions.ErrNotImplemented
+	prefix := makePrefix(sid)
+
+	err := db.Service.Update(func(txn *badger.Txn) error {
+		iter := txn.NewIterator(badger.DefaultIteratorOptions)
+		var entries []*badger.Entry
+		for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
+			item := iter.Item()
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				iter.Close()
+				return err
+			}
+
+			entries = append(entries, badger.NewEntry(item.KeyCopy(nil), value).WithTTL(newExpires))
+		}
+		iter.Close()
+
+		if len(entries) == 0 {
+			return sessions.ErrNotFound
+		}
+
+		for _, entry := range entries {
+			if err := txn.SetEntry(entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil && err != sessions.ErrNotFound {
+		db.logger.Debugf("Database.OnUpdateExpiration: %s: %v", sid, err)
+	}
+
+	return err
 }
 
 var delim = byte('_')
@@ -0,0 +1,610 @@
+// Package cookie implements a client-side sessions database.
+// The whole session is kept on one or more chunked, encrypted and authenticated cookies,
+// so no server-side database is required, e.g. on stateless edge deployments.
+package cookie
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/memstore"
+	"github.com/kataras/iris/v12/sessions"
+
+	"github.com/kataras/golog"
+)
+
+const (
+	// DefaultName is the default name of the session values cookie.
+	// The next chunks are named as "irissessiondata_1", "irissessiondata_2" and so on.
+	DefaultName = "irissessiondata"
+	// DefaultChunkSize is the default maximum length of a cookie's value.
+	// Browsers limit the cookies to 4096 bytes, including their name and attributes.
+	DefaultChunkSize = 3800
+	// DefaultMaxChunks is the default maximum number of cookies of a session.
+	DefaultMaxChunks = 5
+)
+
+// ErrTooLarge is returned by Set when the session values
+// do not fit in the configured chunks.
+// A session which does not fit on EndRequest is not sent to the client.
+var ErrTooLarge = errors.New("session is too large for the cookie database")
+
+// Config is the configuration for the cookie sessions database.
+//
+// Note that the database records the response of every request
+// which starts a session, see Context.Record, so the cookies can be sent
+// after the route's handlers. The response body is kept in memory until
+// the handlers return, register the sessions handler only on the routes
+// which need a session and avoid it on large or streamed responses.
+type Config struct {
+	// Name is the name of the session values cookie.
+	//
+	// Defaults to "irissessiondata".
+	Name string
+	// Codecs encrypt and authenticate the session values. Required.
+	// The first one encodes the values, all of them are tried to decode them,
+	// so keys can be rotated by prepending a new codec and keeping the old ones
+	// for the lifetime of the sessions. Sessions decoded by an old codec
+	// are sent back encoded with the first one.
+	//
+	// Note that the gorilla/securecookie limits the encoded value to 4096 bytes,
+	// call its MaxLength(0) method to allow sessions of more than one cookie.
+	Codecs []context.SecureCookie
+	// ChunkSize is the maximum length of a cookie's value.
+	//
+	// Defaults to 3800.
+	ChunkSize int
+	// MaxChunks is the maximum number of cookies of a session.
+	//
+	// Defaults to 5.
+	MaxChunks int
+	// CookieOptions are applied on the cookies of the session values,
+	// e.g. context.CookieSameSite and context.CookieAllowSubdomains.
+	// The cookies are always HttpOnly, their path is "/" and
+	// they are secure under TLS.
+	CookieOptions []context.CookieOption
+}
+
+// Database the client-side cookie session storage.
+//
+// The values of the requests' sessions are kept in memory
+// from the BeginRequest to the EndRequest, in which they are written to the client.
+// The responses of the session handler are recorded, see Context.Record,
+// so the cookies can be sent after the route's handlers.
+//
+// The sessions can not be listed, the Session.BindUser,
+// Sessions.ListByUser and RevokeAllForUser methods return sessions.ErrNotImplemented.
+type Database struct {
+	cfg    Config
+	logger *golog.Logger
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+var (
+	_ sessions.Database               = (*Database)(nil)
+	_ sessions.DatabaseRequestStarter = (*Database)(nil)
+	_ sessions.DatabaseRequestHandler = (*Database)(nil)
+)
+
+type entry struct {
+	mu        sync.RWMutex
+	values    map[string][]byte // transcoded values.
+	size      int
+	expiresAt time.Time
+	exists    bool // found on the client or created by Acquire.
+	released  bool
+	dirty     bool
+	refs      int
+}
+
+// payload is the encoded form of a session.
+type payload struct {
+	SID       string            `json:"sid"`
+	ExpiresAt int64             `json:"exp,omitempty"`
+	Values    map[string][]byte `json:"values"`
+}
+
+// New returns a new client-side cookie sessions database.
+// It panics if no codec is given.
+//
+// Its BeginRequest calls Context.Record on each request of the sessions handler,
+// the whole response is buffered in memory, see the Config type.
+//
+// Example Code:
+//
+//	codec := securecookie.New(hashKey, blockKey).MaxLength(0)
+//	db := cookie.New(cookie.Config{Codecs: []context.SecureCookie{codec}})
+//	sess.UseDatabase(db)
+func New(cfg Config) *Database {
+	if len(cfg.Codecs) == 0 {
+		panic("sessions: cookie: at least one codec is required")
+	}
+
+	if cfg.Name == "" {
+		cfg.Name = DefaultName
+	}
+
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = DefaultChunkSize
+	}
+
+	if cfg.MaxChunks <= 0 {
+		cfg.MaxChunks = DefaultMaxChunks
+	}
+
+	return &Database{
+		cfg:     cfg,
+		logger:  golog.Default,
+		entries: make(map[string]*entry),
+	}
+}
+
+// SetLogger sets the logger once before server ran.
+// By default the Iris one is injected.
+func (db *Database) SetLogger(logger *golog.Logger) {
+	db.logger = logger
+}
+
+func (db *Database) maxSize() int {
+	return db.cfg.ChunkSize * db.cfg.MaxChunks
+}
+
+func (db *Database) chunkName(i int) string {
+	if i == 0 {
+		return db.cfg.Name
+	}
+
+	return db.cfg.Name + "_" + strconv.Itoa(i)
+}
+
+func (db *Database) get(sid string) *entry {
+	db.mu.Lock()
+	e := db.entries[sid]
+	db.mu.Unlock()
+	return e
+}
+
+func (db *Database) getOrCreate(sid string) *entry {
+	db.mu.Lock()
+	e, ok := db.entries[sid]
+	if !ok {
+		e = &entry{values: make(map[string][]byte)}
+		db.entries[sid] = e
+	}
+	db.mu.Unlock()
+	return e
+}
+
+// done drops the entry of "sid" when no other request uses it.
+func (db *Database) done(sid string) {
+	db.mu.Lock()
+	if e, ok := db.entries[sid]; ok {
+		e.refs--
+		if e.refs <= 0 {
+			delete(db.entries, sid)
+		}
+	}
+	db.mu.Unlock()
+}
+
+const sidsContextKey = "iris.session.cookie.sids"
+
+// BeginRequest starts recording the response and
+// loads the session values of "sid" from the request's cookies.
+func (db *Database) BeginRequest(ctx *context.Context, sid string) {
+	ctx.Record()
+
+	sids, _ := ctx.Values().Get(sidsContextKey).([]string)
+	ctx.Values().Set(sidsContextKey, append(sids, sid))
+
+	db.mu.Lock()
+	e, ok := db.entries[sid]
+	if !ok {
+		e = &entry{values: make(map[string][]byte)}
+		db.entries[sid] = e
+	}
+	e.refs++
+	db.mu.Unlock()
+
+	if ok { // loaded by a concurrent request of the same session.
+		return
+	}
+
+	e.mu.Lock()
+	db.load(ctx, sid, e)
+	e.mu.Unlock()
+}
+
+func (db *Database) load(ctx *context.Context, sid string, e *entry) {
+	var b strings.Builder
+	for i := 0; i < db.cfg.MaxChunks; i++ {
+		c, err := ctx.Request().Cookie(db.chunkName(i))
+		if err != nil {
+			break
+		}
+		b.WriteString(c.Value)
+	}
+
+	if b.Len() == 0 {
+		return
+	}
+
+	// Any invalid cookies are removed on EndRequest.
+	e.dirty = true
+
+	var data []byte
+	decoded := false
+	for i, codec := range db.cfg.Codecs {
+		if err := codec.Decode(db.cfg.Name, b.String(), &data); err == nil {
+			decoded = true
+			e.dirty = i > 0 // encode it with the current key.
+			break
+		}
+	}
+
+	if !decoded {
+		db.logger.Debugf("cookie: unable to decode the session values of '%s'", sid)
+		return
+	}
+
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		db.logger.Debugf("cookie: %s: %v", sid, err)
+		e.dirty = true
+		return
+	}
+
+	if p.SID != sid {
+		e.dirty = true
+		return
+	}
+
+	if p.ExpiresAt > 0 {
+		e.expiresAt = time.Unix(p.ExpiresAt, 0)
+		if !e.expiresAt.After(time.Now()) {
+			e.expiresAt = time.Time{}
+			e.dirty = true
+			return
+		}
+	}
+
+	if p.Values != nil {
+		e.values = p.Values
+	}
+
+	for key, value := range e.values {
+		e.size += len(key) + len(value)
+	}
+	e.exists = true
+}
+
+// Acquire receives a session's lifetime from the cookie,
+// if the return value is LifeTime{} then the session manager sets the life time based on the expiration duration lives in configuration.
+func (db *Database) Acquire(sid string, expires time.Duration) memstore.LifeTime {
+	e := db.getOrCreate(sid)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.exists {
+		return memstore.LifeTime{Time: e.expiresAt}
+	}
+
+	e.exists = true
+	e.released = false
+	if expires > 0 {
+		e.expiresAt = time.Now().Add(expires)
+	}
+
+	return memstore.LifeTime{} // session manager will handle the rest.
+}
+
+// OnUpdateExpiration re-sets the expiration of the session's cookies.
+func (db *Database) OnUpdateExpiration(sid string, newExpires time.Duration) error {
+	e := db.get(sid)
+	if e == nil {
+		return sessions.ErrNotFound
+	}
+
+	e.mu.Lock()
+	e.expiresAt = time.Now().Add(newExpires)
+	e.dirty = true
+	e.mu.Unlock()
+	return nil
+}
+
+// Set sets a key value of a specific session.
+// It returns ErrTooLarge if the values do not fit in the configured chunks.
+// Ignore the "immutable".
+func (db *Database) Set(sid string, key string, value interface{}, _ time.Duration, _ bool) error {
+	valueBytes, err := sessions.DefaultTranscoder.Marshal(value)
+	if err != nil {
+		db.logger.Error(err)
+		return err
+	}
+
+	e := db.get(sid)
+	if e == nil {
+		return sessions.ErrNotFound
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	size := e.size + len(key) + len(valueBytes)
+	if old, ok := e.values[key]; ok {
+		size -= len(key) + len(old)
+	}
+
+	// The encoded size is larger, the final check happens on EndRequest.
+	if size > db.maxSize() {
+		db.logger.Errorf("cookie: %s: %v", key, ErrTooLarge)
+		return ErrTooLarge
+	}
+
+	e.values[key] = valueBytes
+	e.size = size
+	e.dirty = true
+	return nil
+}
+
+// Get retrieves a session value based on the key.
+func (db *Database) Get(sid string, key string) (value interface{}) {
+	if err := db.Decode(sid, key, &value); err == nil {
+		return value
+	}
+
+	return nil
+}
+
+// Decode binds the "outPtr" to the value associated to the provided "key".
+func (db *Database) Decode(sid, key string, outPtr interface{}) error {
+	e := db.get(sid)
+	if e == nil {
+		return nil
+	}
+
+	e.mu.RLock()
+	valueBytes, ok := e.values[key]
+	e.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	return sessions.DefaultTranscoder.Unmarshal(valueBytes, outPtr)
+}
+
+// Visit loops through all session keys and values.
+func (db *Database) Visit(sid string, cb func(key string, value interface{})) error {
+	e := db.get(sid)
+	if e == nil {
+		return nil
+	}
+
+	e.mu.RLock()
+	values := make(map[string][]byte, len(e.values))
+	for key, valueBytes := range e.values {
+		values[key] = valueBytes
+	}
+	e.mu.RUnlock()
+
+	for key, valueBytes := range values {
+		var value interface{}
+		if err := sessions.DefaultTranscoder.Unmarshal(valueBytes, &value); err != nil {
+			db.logger.Debugf("unable to decode %s:%s: %v", sid, key, err)
+			return err
+		}
+
+		cb(key, value)
+	}
+
+	return nil
+}
+
+// Len returns the length of the session's entries (keys).
+func (db *Database) Len(sid string) int {
+	e := db.get(sid)
+	if e == nil {
+		return 0
+	}
+
+	e.mu.RLock()
+	n := len(e.values)
+	e.mu.RUnlock()
+	return n
+}
+
+// Delete removes a session key value based on its key.
+func (db *Database) Delete(sid string, key string) (deleted bool) {
+	e := db.get(sid)
+	if e == nil {
+		return false
+	}
+
+	e.mu.Lock()
+	if old, ok := e.values[key]; ok {
+		delete(e.values, key)
+		e.size -= len(key) + len(old)
+		e.dirty = true
+		deleted = true
+	}
+	e.mu.Unlock()
+	return
+}
+
+// Clear removes all session key values but it keeps the session entry.
+func (db *Database) Clear(sid string) error {
+	e := db.get(sid)
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	e.values = make(map[string][]byte)
+	e.size = 0
+	e.dirty = true
+	e.mu.Unlock()
+	return nil
+}
+
+// Release destroys the session, its cookies are removed on EndRequest.
+func (db *Database) Release(sid string) error {
+	e := db.get(sid)
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	e.values = make(map[string][]byte)
+	e.size = 0
+	e.exists = false
+	e.released = true
+	e.dirty = true
+	e.mu.Unlock()
+	return nil
+}
+
+// EndRequest writes the modified sessions of the request to the client's cookies.
+func (db *Database) EndRequest(ctx *context.Context, session *sessions.Session) {
+	begun, _ := ctx.Values().Get(sidsContextKey).([]string)
+	ctx.Values().Remove(sidsContextKey)
+
+	sids := begun
+	if session != nil && !contains(begun, session.ID()) {
+		// A regenerated session, its entry was created by Acquire.
+		sids = append(sids, session.ID())
+	}
+
+	// All sessions share the same cookies,
+	// the active ones are written after the removals.
+	var released, active []string
+	for i, sid := range sids {
+		if contains(sids[:i], sid) {
+			continue
+		}
+
+		e := db.get(sid)
+		if e == nil {
+			continue
+		}
+
+		e.mu.RLock()
+		isReleased := e.released
+		e.mu.RUnlock()
+
+		if isReleased {
+			released = append(released, sid)
+		} else {
+			active = append(active, sid)
+		}
+	}
+
+	for _, sid := range append(released, active...) {
+		db.flush(ctx, sid, db.get(sid))
+	}
+
+	for _, sid := range sids {
+		db.done(sid)
+	}
+}
+
+func contains(s []string, v string) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (db *Database) flush(ctx *context.Context, sid string, e *entry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.dirty {
+		return
+	}
+	e.dirty = false
+
+	var value string
+	if !e.released && len(e.values) > 0 {
+		p := payload{SID: sid, Values: e.values}
+		if !e.expiresAt.IsZero() {
+			p.ExpiresAt = e.expiresAt.Unix()
+		}
+
+		data, err := json.Marshal(p)
+		if err != nil {
+			db.logger.Errorf("cookie: %s: %v", sid, err)
+			return
+		}
+
+		value, err = db.cfg.Codecs[0].Encode(db.cfg.Name, data)
+		if err != nil {
+			db.logger.Errorf("cookie: %s: %v", sid, err)
+			return
+		}
+
+		if len(value) > db.maxSize() {
+			db.logger.Errorf("cookie: %s: %v", sid, ErrTooLarge)
+			return // keep the previous cookies.
+		}
+	}
+
+	opts := append([]context.CookieOption{context.CookieSecure}, db.cfg.CookieOptions...)
+
+	n := 0
+	for ; len(value) > 0; n++ {
+		size := db.cfg.ChunkSize
+		if size > len(value) {
+			size = len(value)
+		}
+
+		c := &http.Cookie{
+			Name:     db.chunkName(n),
+			Value:    value[:size],
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+			Expires:  context.CookieExpireUnlimited,
+		}
+
+		if !e.expiresAt.IsZero() {
+			c.Expires = e.expiresAt
+		}
+		c.MaxAge = int(time.Until(c.Expires).Seconds())
+
+		ctx.UpsertCookie(c, opts...)
+		value = value[size:]
+	}
+
+	// Remove the client's chunks which are not used anymore.
+	for i := n; i < db.cfg.MaxChunks; i++ {
+		name := db.chunkName(i)
+		if _, err := ctx.Request().Cookie(name); err != nil {
+			continue
+		}
+
+		ctx.UpsertCookie(&http.Cookie{
+			Name:     name,
+			Path:     "/",
+			HttpOnly: true,
+			Expires:  memstore.ExpireDelete,
+			MaxAge:   -1,
+		}, opts...)
+	}
+}
+
+// Close does nothing, the sessions live on the client.
+func (db *Database) Close() error {
+	return nil
+}
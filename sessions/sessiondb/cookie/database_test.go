@@ -0,0 +1,147 @@
+package cookie_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/sessions"
+	"github.com/kataras/iris/v12/sessions/sessiondb/cookie"
+
+	"github.com/gorilla/securecookie"
+	"github.com/iris-contrib/httpexpect/v2"
+)
+
+const sessionCookie = "mycustomsessionid"
+
+func newCodec() *securecookie.SecureCookie {
+	return securecookie.New(securecookie.GenerateRandomKey(64), securecookie.GenerateRandomKey(32)).MaxLength(0)
+}
+
+// newApp returns a tester of a new application which does not keep the cookies,
+// they are sent explicitly.
+func newApp(t *testing.T, cfg cookie.Config) *httpexpect.Expect {
+	app := iris.New()
+	sess := sessions.New(sessions.Config{Cookie: sessionCookie})
+	sess.UseDatabase(cookie.New(cfg))
+	app.Use(sess.Handler())
+
+	app.Get("/set", func(ctx iris.Context) {
+		session := sessions.Get(ctx)
+		for key, value := range ctx.URLParams() {
+			session.Set(key, value)
+		}
+	})
+
+	app.Get("/get", func(ctx iris.Context) {
+		ctx.JSON(sessions.Get(ctx).GetAll())
+	})
+
+	app.Get("/destroy", func(ctx iris.Context) {
+		sessions.Get(ctx).Man.Destroy(ctx)
+	})
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	return httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  "http://example.com",
+		Client:   &http.Client{Transport: httpexpect.NewBinder(app)},
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+}
+
+type cookies map[string]string
+
+func collect(t *testing.T, resp *http.Response, into cookies) cookies {
+	t.Helper()
+
+	for _, c := range resp.Cookies() {
+		if c.MaxAge < 0 {
+			delete(into, c.Name)
+			continue
+		}
+
+		into[c.Name] = c.Value
+	}
+
+	return into
+}
+
+func request(e *httpexpect.Expect, path string, c cookies) *httpexpect.Request {
+	req := e.GET(path)
+	for name, value := range c {
+		req = req.WithCookie(name, value)
+	}
+
+	return req
+}
+
+func TestCookieDatabase(t *testing.T) {
+	codec := newCodec()
+	cfg := cookie.Config{Codecs: []context.SecureCookie{codec}, ChunkSize: 200}
+
+	// The first server sets the values.
+	e := newApp(t, cfg)
+	c := collect(t, e.GET("/set").WithQuery("name", "iris").WithQuery("long", strings.Repeat("a", 300)).
+		Expect().Status(httptest.StatusOK).Raw(), make(cookies))
+
+	chunks := len(c) - 1
+	if chunks < 3 {
+		t.Fatalf("expected chunked cookies but got: %v", c)
+	}
+
+	expected := iris.Map{"name": "iris", "long": strings.Repeat("a", 300)}
+
+	// A new server, without any server-side state, reads them.
+	e = newApp(t, cfg)
+	request(e, "/get", c).Expect().Status(httptest.StatusOK).JSON().IsEqual(expected)
+
+	// Fewer chunks, the rest are removed.
+	c = collect(t, request(e, "/set", c).WithQuery("long", "a").Expect().Status(httptest.StatusOK).Raw(), c)
+	if len(c)-1 >= chunks {
+		t.Fatalf("expected the unused chunks to be removed but got: %v", c)
+	}
+	expected["long"] = "a"
+	request(e, "/get", c).Expect().Status(httptest.StatusOK).JSON().IsEqual(expected)
+
+	// Key rotation: the new codec encodes, the old one still decodes.
+	rotated := cookie.Config{Codecs: []context.SecureCookie{newCodec(), codec}, ChunkSize: 200}
+	e = newApp(t, rotated)
+	resp := request(e, "/get", c).Expect().Status(httptest.StatusOK)
+	resp.JSON().IsEqual(expected)
+	c = collect(t, resp.Raw(), c)
+
+	// Re-encoded with the new key, so the new codec alone can decode it.
+	rotated.Codecs = rotated.Codecs[:1]
+	e = newApp(t, rotated)
+	request(e, "/get", c).Expect().Status(httptest.StatusOK).JSON().IsEqual(expected)
+
+	// Tampered values are ignored.
+	tampered := cookies{sessionCookie: c[sessionCookie], cookie.DefaultName: "invalid"}
+	request(e, "/get", tampered).Expect().Status(httptest.StatusOK).JSON().Object().IsEmpty()
+
+	// Size limits, the session is not modified.
+	resp = request(e, "/set", c).WithQuery("long", strings.Repeat("a", 1000)).Expect().Status(httptest.StatusOK)
+	if got := resp.Raw().Cookies(); len(got) != 0 {
+		t.Fatalf("expected no cookies but got: %v", got)
+	}
+	request(e, "/get", c).Expect().Status(httptest.StatusOK).JSON().IsEqual(expected)
+
+	db := cookie.New(cfg)
+	db.Acquire("sid", 0)
+	if err := db.Set("sid", "long", strings.Repeat("a", 1000), 0, false); !errors.Is(err, cookie.ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge but got: %v", err)
+	}
+
+	// Destroy removes the cookies.
+	c = collect(t, request(e, "/destroy", c).Expect().Status(httptest.StatusOK).Raw(), c)
+	if len(c) != 0 {
+		t.Fatalf("expected all cookies to be removed but got: %v", c)
+	}
+}
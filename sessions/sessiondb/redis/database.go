@@ -255,7 +255,7 @@ func (db *Database) Visit(sid string, cb func(key string, value interface{})) er
 
 // Len returns the length of the session's entries (keys).
 func (db *Database) Len(sid string) int {
-	return db.c.Driver.Len(sid)
+	return db.c.Driver.Len(db.makeSID(sid))
 }
 
 // Delete removes a session key value based on its key.
@@ -154,6 +154,17 @@ func (r *GoRedisDriver) Set(sid, key string, value interface{}) error {
 	return r.Client.HSet(defaultContext, sid, key, value).Err()
 }
 
+// SetWithTTL stores a "value" based on the session's "key"
+// and sets the expiration duration of the session in a single transaction.
+func (r *GoRedisDriver) SetWithTTL(sid, key string, value interface{}, lifetime time.Duration) error {
+	_, err := r.Client.TxPipelined(defaultContext, func(pipe redis.Pipeliner) error {
+		pipe.HSet(defaultContext, sid, key, value)
+		pipe.Expire(defaultContext, sid, lifetime)
+		return nil
+	})
+	return err
+}
+
 // Get returns the associated value of the session's given "key".
 func (r *GoRedisDriver) Get(sid, key string) (interface{}, error) {
 	return r.Client.HGet(defaultContext, sid, key).Bytes()
@@ -0,0 +1,547 @@
+package sqldb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/iris/v12/core/memstore"
+	"github.com/kataras/iris/v12/sessions"
+
+	"github.com/kataras/golog"
+)
+
+const (
+	// DefaultTable is the default Config.Table, "iris_sessions".
+	DefaultTable = "iris_sessions"
+	// DefaultCleanupInterval is the default Config.CleanupInterval, 5 minutes.
+	DefaultCleanupInterval = 5 * time.Minute
+)
+
+// Config is the configuration of the SQL database.
+type Config struct {
+	// Dialect of the database, e.g. `Postgres`, `MySQL` or `SQLite`.
+	// Defaults to `Postgres`.
+	Dialect Dialect
+	// Table is the name of the sessions table.
+	// The session values are stored on the Table + "_values" one,
+	// unless Blob is true.
+	// The tables are created on `New` if they do not exist.
+	// Defaults to "iris_sessions".
+	Table string
+	// Blob stores all the values of a session on a single row,
+	// as a serialized blob, instead of a row per key.
+	// It's faster to load sessions with many values
+	// but each modification re-writes the whole blob.
+	// The per-key time-to-live of the values is ignored.
+	// Defaults to false.
+	Blob bool
+	// CleanupInterval is the interval of the background removal
+	// of the expired sessions. A negative value disables it.
+	// Defaults to 5 minutes.
+	CleanupInterval time.Duration
+}
+
+// Database is the database/sql session storage.
+// It supports any database/sql driver of the Postgres, MySQL and SQLite databases,
+// the driver should be imported by the caller.
+type Database struct {
+	// Service is the underline database connection, it's initialized at `New`.
+	Service *sql.DB
+	logger  *golog.Logger
+
+	cfg     Config
+	queries queries
+	// mu guards the blob modifications of this instance,
+	// the row lock of a transaction guards them between many instances.
+	mu sync.Mutex
+
+	done   chan struct{}
+	closed uint32 // if 1 is closed.
+}
+
+var _ sessions.Database = (*Database)(nil)
+
+type queries struct {
+	acquire, insertSession, updateExpiration, deleteSession string
+	getBlob, lockBlob, setBlob                              string
+	get, visit, set, count, delete, clear                   string
+	cleanupSessions, cleanupValues, cleanupExpiredValues    string
+}
+
+// New returns a new SQL sessions database of the "service" connection.
+// It creates the tables, removes the expired sessions
+// and starts their background cleanup.
+//
+// Example Code:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"
+//	service, err := sql.Open("pgx", "postgres://...")
+//	db, err := sqldb.New(service, sqldb.Config{Dialect: sqldb.Postgres})
+//	sess.UseDatabase(db)
+func New(service *sql.DB, cfg Config) (*Database, error) {
+	if cfg.Dialect == nil {
+		cfg.Dialect = Postgres
+	}
+
+	if cfg.Table == "" {
+		cfg.Table = DefaultTable
+	}
+
+	if cfg.CleanupInterval == 0 {
+		cfg.CleanupInterval = DefaultCleanupInterval
+	}
+
+	db := &Database{
+		Service: service,
+		logger:  golog.Default,
+		cfg:     cfg,
+		queries: newQueries(cfg.Dialect, cfg.Table, cfg.Table+"_values"),
+		done:    make(chan struct{}),
+	}
+
+	for _, stmt := range cfg.Dialect.Migrate(cfg.Table, cfg.Table+"_values") {
+		if _, err := service.Exec(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Cleanup(); err != nil {
+		return nil, err
+	}
+
+	if cfg.CleanupInterval > 0 {
+		go db.cleanupLoop(cfg.CleanupInterval)
+	}
+
+	return db, nil
+}
+
+func newQueries(d Dialect, sessionsTable, valuesTable string) queries {
+	lockBlob := "SELECT data FROM " + sessionsTable + " WHERE sid = ?"
+	if _, ok := d.(sqlite); !ok {
+		// SQLite serializes the write transactions and it has no row locks.
+		lockBlob += " FOR UPDATE"
+	}
+
+	return queries{
+		acquire:          bind(d, "SELECT expires_at FROM "+sessionsTable+" WHERE sid = ?"),
+		insertSession:    d.Upsert(sessionsTable, []string{"sid"}, []string{"expires_at", "data"}),
+		updateExpiration: bind(d, "UPDATE "+sessionsTable+" SET expires_at = ? WHERE sid = ?"),
+		deleteSession:    bind(d, "DELETE FROM "+sessionsTable+" WHERE sid = ?"),
+
+		getBlob:  bind(d, "SELECT data FROM "+sessionsTable+" WHERE sid = ?"),
+		lockBlob: bind(d, lockBlob),
+		setBlob:  bind(d, "UPDATE "+sessionsTable+" SET data = ? WHERE sid = ?"),
+
+		get:    bind(d, "SELECT value FROM "+valuesTable+" WHERE sid = ? AND name = ? AND (expires_at = 0 OR expires_at > ?)"),
+		visit:  bind(d, "SELECT name, value FROM "+valuesTable+" WHERE sid = ? AND (expires_at = 0 OR expires_at > ?) ORDER BY name"),
+		set:    d.Upsert(valuesTable, []string{"sid", "name"}, []string{"value", "expires_at"}),
+		count:  bind(d, "SELECT COUNT(*) FROM "+valuesTable+" WHERE sid = ? AND (expires_at = 0 OR expires_at > ?)"),
+		delete: bind(d, "DELETE FROM "+valuesTable+" WHERE sid = ? AND name = ?"),
+		clear:  bind(d, "DELETE FROM "+valuesTable+" WHERE sid = ?"),
+
+		cleanupValues:        bind(d, "DELETE FROM "+valuesTable+" WHERE sid IN (SELECT sid FROM "+sessionsTable+" WHERE expires_at > 0 AND expires_at <= ?)"),
+		cleanupSessions:      bind(d, "DELETE FROM "+sessionsTable+" WHERE expires_at > 0 AND expires_at <= ?"),
+		cleanupExpiredValues: bind(d, "DELETE FROM "+valuesTable+" WHERE expires_at > 0 AND expires_at <= ?"),
+	}
+}
+
+// SetLogger sets the logger once before server ran.
+// By default the Iris one is injected.
+func (db *Database) SetLogger(logger *golog.Logger) {
+	db.logger = logger
+}
+
+// expiresAt returns the stored expiration of a time-to-live,
+// the unix nanoseconds or zero if it does not expire.
+func expiresAt(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+
+	return time.Now().Add(ttl).UnixNano()
+}
+
+// Acquire receives a session's lifetime from the database,
+// if the return value is LifeTime{} then the session manager sets the life time based on the expiration duration lives in configuration.
+func (db *Database) Acquire(sid string, expires time.Duration) memstore.LifeTime {
+	var exp int64
+	err := db.Service.QueryRow(db.queries.acquire, sid).Scan(&exp)
+	if err == nil {
+		if exp == 0 {
+			return memstore.LifeTime{}
+		}
+
+		if expiration := time.Unix(0, exp); expiration.After(time.Now()) {
+			// found, return the expiration.
+			return memstore.LifeTime{Time: expiration}
+		}
+
+		// expired but not cleaned up yet, start over.
+		if err = db.Release(sid); err != nil {
+			return memstore.LifeTime{}
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		db.logger.Debugf("Database.Acquire: %s: %v", sid, err)
+		return memstore.LifeTime{}
+	}
+
+	// not found, create the session entry, session manager will do its job.
+	if _, err = db.Service.Exec(db.queries.insertSession, sid, expiresAt(expires), nil); err != nil {
+		db.logger.Debugf("Database.Acquire: %s: %v", sid, err)
+	}
+
+	return memstore.LifeTime{}
+}
+
+// OnUpdateExpiration re-sets the expiration of the session entry.
+func (db *Database) OnUpdateExpiration(sid string, newExpires time.Duration) error {
+	result, err := db.Service.Exec(db.queries.updateExpiration, expiresAt(newExpires), sid)
+	if err != nil {
+		db.logger.Debugf("Database.OnUpdateExpiration: %s: %v", sid, err)
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sessions.ErrNotFound
+	}
+
+	return nil
+}
+
+// blob is the serialized form of the session values on Blob mode.
+type blob map[string][]byte
+
+func (db *Database) getBlob(sid string) (blob, error) {
+	return scanBlob(db.Service.QueryRow(db.queries.getBlob, sid))
+}
+
+func scanBlob(row *sql.Row) (blob, error) {
+	var data []byte
+	if err := row.Scan(&data); err != nil {
+		return nil, err
+	}
+
+	values := make(blob)
+	if len(data) == 0 {
+		return values, nil
+	}
+
+	return values, json.Unmarshal(data, &values)
+}
+
+// updateBlob modifies the values of a session on Blob mode.
+// The values are read and written inside a transaction which locks the session's row,
+// so concurrent modifications from many servers are not lost.
+func (db *Database) updateBlob(sid string, modify func(values blob) bool) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.Service.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op after commit.
+
+	values, err := scanBlob(tx.QueryRow(db.queries.lockBlob, sid))
+	if err != nil {
+		return err
+	}
+
+	if !modify(values) {
+		return nil
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(db.queries.setBlob, data, sid); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Set sets a key value of a specific session.
+// Ignore the "immutable".
+func (db *Database) Set(sid string, key string, value interface{}, ttl time.Duration, immutable bool) error {
+	valueBytes, err := sessions.DefaultTranscoder.Marshal(value)
+	if err != nil {
+		db.logger.Error(err)
+		return err
+	}
+
+	if db.cfg.Blob {
+		err = db.updateBlob(sid, func(values blob) bool {
+			values[key] = valueBytes
+			return true
+		})
+	} else {
+		_, err = db.Service.Exec(db.queries.set, sid, key, valueBytes, expiresAt(ttl))
+	}
+
+	if err != nil {
+		db.logger.Error(err)
+	}
+
+	return err
+}
+
+// Get retrieves a session value based on the key.
+func (db *Database) Get(sid string, key string) (value interface{}) {
+	if err := db.Decode(sid, key, &value); err == nil {
+		return value
+	}
+
+	return nil
+}
+
+// Decode binds the "outPtr" to the value associated to the provided "key".
+func (db *Database) Decode(sid, key string, outPtr interface{}) error {
+	var (
+		valueBytes []byte
+		err        error
+	)
+
+	if db.cfg.Blob {
+		var values blob
+		if values, err = db.getBlob(sid); err == nil {
+			var ok bool
+			if valueBytes, ok = values[key]; !ok {
+				err = sql.ErrNoRows
+			}
+		}
+	} else {
+		err = db.Service.QueryRow(db.queries.get, sid, key, time.Now().UnixNano()).Scan(&valueBytes)
+	}
+
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			db.logger.Error(err)
+		}
+
+		return err
+	}
+
+	return sessions.DefaultTranscoder.Unmarshal(valueBytes, outPtr)
+}
+
+// Visit loops through all session keys and values.
+func (db *Database) Visit(sid string, cb func(key string, value interface{})) error {
+	values, keys, err := db.values(sid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		db.logger.Errorf("Database.Visit: %s: %v", sid, err)
+		return err
+	}
+
+	// The callback is called after the rows are closed,
+	// so it can use the database too.
+	for _, key := range keys {
+		var value interface{}
+		if err = sessions.DefaultTranscoder.Unmarshal(values[key], &value); err != nil {
+			db.logger.Errorf("Database.Visit: %s: %v", sid, err)
+			return err
+		}
+
+		cb(key, value)
+	}
+
+	return nil
+}
+
+// values returns the session values and their sorted keys.
+func (db *Database) values(sid string) (blob, []string, error) {
+	if db.cfg.Blob {
+		values, err := db.getBlob(sid)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		return values, keys, nil
+	}
+
+	rows, err := db.Service.Query(db.queries.visit, sid, time.Now().UnixNano())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var (
+		values = make(blob)
+		keys   []string
+	)
+
+	for rows.Next() {
+		var (
+			key        string
+			valueBytes []byte
+		)
+
+		if err = rows.Scan(&key, &valueBytes); err != nil {
+			return nil, nil, err
+		}
+
+		values[key] = valueBytes
+		keys = append(keys, key)
+	}
+
+	return values, keys, rows.Err()
+}
+
+// Len returns the length of the session's entries (keys).
+func (db *Database) Len(sid string) (n int) {
+	if db.cfg.Blob {
+		values, err := db.getBlob(sid)
+		if err != nil {
+			return 0
+		}
+
+		return len(values)
+	}
+
+	if err := db.Service.QueryRow(db.queries.count, sid, time.Now().UnixNano()).Scan(&n); err != nil {
+		db.logger.Debugf("Database.Len: %s: %v", sid, err)
+		return 0
+	}
+
+	return
+}
+
+// Delete removes a session key value based on its key.
+func (db *Database) Delete(sid string, key string) (deleted bool) {
+	if db.cfg.Blob {
+		err := db.updateBlob(sid, func(values blob) bool {
+			_, deleted = values[key]
+			delete(values, key)
+			return deleted
+		})
+		if err != nil {
+			db.logger.Debugf("Database.Delete: %s: %v", sid, err)
+			return false
+		}
+
+		return
+	}
+
+	result, err := db.Service.Exec(db.queries.delete, sid, key)
+	if err != nil {
+		db.logger.Error(err)
+		return false
+	}
+
+	n, err := result.RowsAffected()
+	return err == nil && n > 0
+}
+
+// Clear removes all session key values but it keeps the session entry.
+func (db *Database) Clear(sid string) error {
+	var err error
+	if db.cfg.Blob {
+		db.mu.Lock()
+		_, err = db.Service.Exec(db.queries.setBlob, nil, sid)
+		db.mu.Unlock()
+	} else {
+		_, err = db.Service.Exec(db.queries.clear, sid)
+	}
+
+	if err != nil {
+		db.logger.Warnf("Database.Clear: %s: %v", sid, err)
+	}
+
+	return err
+}
+
+// Release destroys the session, it clears and removes the session entry,
+// session manager will create a new session ID on the next request after this call.
+func (db *Database) Release(sid string) error {
+	tx, err := db.Service.Begin()
+	if err != nil {
+		db.logger.Debugf("Database.Release: %s: %v", sid, err)
+		return err
+	}
+
+	if _, err = tx.Exec(db.queries.clear, sid); err == nil {
+		_, err = tx.Exec(db.queries.deleteSession, sid)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		db.logger.Warnf("Database.Release: %s: %v", sid, err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		db.logger.Debugf("Database.Release.Commit: %s: %v", sid, err)
+	}
+
+	return err
+}
+
+// Cleanup removes the expired sessions and values.
+// It's called on `New` and on every Config.CleanupInterval.
+func (db *Database) Cleanup() error {
+	now := time.Now().UnixNano()
+
+	tx, err := db.Service.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, query := range []string{db.queries.cleanupValues, db.queries.cleanupSessions, db.queries.cleanupExpiredValues} {
+		if _, err = tx.Exec(query, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (db *Database) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-ticker.C:
+			if err := db.Cleanup(); err != nil {
+				db.logger.Debugf("Database.Cleanup: %v", err)
+			}
+		}
+	}
+}
+
+// Close stops the background cleanup and closes the database connection.
+func (db *Database) Close() error {
+	if !atomic.CompareAndSwapUint32(&db.closed, 0, 1) {
+		return nil
+	}
+
+	close(db.done)
+
+	err := db.Service.Close()
+	if err != nil {
+		db.logger.Warnf("closing the sql connection: %v", err)
+	}
+
+	return err
+}
@@ -0,0 +1,127 @@
+package sqldb
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the SQL syntax differences between the supported databases.
+// Builtin dialects are `Postgres`, `MySQL` and `SQLite`.
+type Dialect interface {
+	// Placeholder returns the bind parameter of the nth argument, starting from 1.
+	Placeholder(n int) string
+	// Migrate returns the statements which create the sessions
+	// and the values tables and their indexes, if they do not exist.
+	Migrate(sessionsTable, valuesTable string) []string
+	// Upsert returns an insert statement of the "keys" and the "columns"
+	// which updates the "columns" when a row with the same "keys" already exists.
+	Upsert(table string, keys, columns []string) string
+}
+
+var (
+	// Postgres is the dialect of PostgreSQL 9.5+ and CockroachDB.
+	Postgres Dialect = postgres{}
+	// MySQL is the dialect of MySQL 5.7+ and MariaDB.
+	MySQL Dialect = mysql{}
+	// SQLite is the dialect of SQLite 3.24+.
+	SQLite Dialect = sqlite{}
+)
+
+type postgres struct{}
+
+func (postgres) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (postgres) Migrate(sessionsTable, valuesTable string) []string {
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + sessionsTable + " (sid VARCHAR(255) NOT NULL PRIMARY KEY, expires_at BIGINT NOT NULL DEFAULT 0, data BYTEA)",
+		"CREATE INDEX IF NOT EXISTS " + sessionsTable + "_expires_at ON " + sessionsTable + " (expires_at)",
+		"CREATE TABLE IF NOT EXISTS " + valuesTable + " (sid VARCHAR(255) NOT NULL, name VARCHAR(255) NOT NULL, value BYTEA NOT NULL, expires_at BIGINT NOT NULL DEFAULT 0, PRIMARY KEY (sid, name))",
+		"CREATE INDEX IF NOT EXISTS " + valuesTable + "_expires_at ON " + valuesTable + " (expires_at)",
+	}
+}
+
+func (d postgres) Upsert(table string, keys, columns []string) string {
+	return onConflict(d, table, keys, columns)
+}
+
+type mysql struct{}
+
+func (mysql) Placeholder(int) string {
+	return "?"
+}
+
+func (mysql) Migrate(sessionsTable, valuesTable string) []string {
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + sessionsTable + " (sid VARCHAR(255) NOT NULL PRIMARY KEY, expires_at BIGINT NOT NULL DEFAULT 0, data LONGBLOB, INDEX (expires_at))",
+		"CREATE TABLE IF NOT EXISTS " + valuesTable + " (sid VARCHAR(255) NOT NULL, name VARCHAR(255) NOT NULL, value LONGBLOB NOT NULL, expires_at BIGINT NOT NULL DEFAULT 0, PRIMARY KEY (sid, name), INDEX (expires_at))",
+	}
+}
+
+func (d mysql) Upsert(table string, keys, columns []string) string {
+	updates := make([]string, 0, len(columns))
+	for _, column := range columns {
+		updates = append(updates, column+" = VALUES("+column+")")
+	}
+
+	return insert(d, table, append(keys, columns...)) + " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
+
+type sqlite struct{}
+
+func (sqlite) Placeholder(int) string {
+	return "?"
+}
+
+func (sqlite) Migrate(sessionsTable, valuesTable string) []string {
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + sessionsTable + " (sid TEXT NOT NULL PRIMARY KEY, expires_at INTEGER NOT NULL DEFAULT 0, data BLOB)",
+		"CREATE INDEX IF NOT EXISTS " + sessionsTable + "_expires_at ON " + sessionsTable + " (expires_at)",
+		"CREATE TABLE IF NOT EXISTS " + valuesTable + " (sid TEXT NOT NULL, name TEXT NOT NULL, value BLOB NOT NULL, expires_at INTEGER NOT NULL DEFAULT 0, PRIMARY KEY (sid, name))",
+		"CREATE INDEX IF NOT EXISTS " + valuesTable + "_expires_at ON " + valuesTable + " (expires_at)",
+	}
+}
+
+func (d sqlite) Upsert(table string, keys, columns []string) string {
+	return onConflict(d, table, keys, columns)
+}
+
+func insert(d Dialect, table string, columns []string) string {
+	placeholders := make([]string, 0, len(columns))
+	for i := range columns {
+		placeholders = append(placeholders, d.Placeholder(i+1))
+	}
+
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ")"
+}
+
+// onConflict returns the upsert statement of the Postgres and SQLite dialects.
+func onConflict(d Dialect, table string, keys, columns []string) string {
+	updates := make([]string, 0, len(columns))
+	for _, column := range columns {
+		updates = append(updates, column+" = excluded."+column)
+	}
+
+	return insert(d, table, append(keys, columns...)) + " ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// bind replaces the "?" bind parameters of a statement with the dialect ones.
+func bind(d Dialect, query string) string {
+	var (
+		b strings.Builder
+		n int
+	)
+
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString(d.Placeholder(n))
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package sqldb
+
+import "testing"
+
+func TestDialectUpsert(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{Postgres, "INSERT INTO t (sid, name, value) VALUES ($1, $2, $3) ON CONFLICT (sid, name) DO UPDATE SET value = excluded.value"},
+		{SQLite, "INSERT INTO t (sid, name, value) VALUES (?, ?, ?) ON CONFLICT (sid, name) DO UPDATE SET value = excluded.value"},
+		{MySQL, "INSERT INTO t (sid, name, value) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE value = VALUES(value)"},
+	}
+
+	for i, tt := range tests {
+		if got := tt.dialect.Upsert("t", []string{"sid", "name"}, []string{"value"}); got != tt.expected {
+			t.Fatalf("[%d] expected:\n%s\nbut got:\n%s", i, tt.expected, got)
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	query := "SELECT value FROM t WHERE sid = ? AND name = ?"
+	if expected, got := "SELECT value FROM t WHERE sid = $1 AND name = $2", bind(Postgres, query); got != expected {
+		t.Fatalf("expected: %s but got: %s", expected, got)
+	}
+
+	if got := bind(MySQL, query); got != query {
+		t.Fatalf("expected: %s but got: %s", query, got)
+	}
+}
+
+func TestLockBlobQuery(t *testing.T) {
+	if expected, got := "SELECT data FROM s WHERE sid = $1 FOR UPDATE", newQueries(Postgres, "s", "v").lockBlob; got != expected {
+		t.Fatalf("expected: %s but got: %s", expected, got)
+	}
+
+	// SQLite has no row locks, its transactions are serialized.
+	if expected, got := "SELECT data FROM s WHERE sid = ?", newQueries(SQLite, "s", "v").lockBlob; got != expected {
+		t.Fatalf("expected: %s but got: %s", expected, got)
+	}
+}
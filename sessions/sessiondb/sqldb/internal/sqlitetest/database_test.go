@@ -0,0 +1,135 @@
+package sqlitetest_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/sessions"
+	"github.com/kataras/iris/v12/sessions/sessiondb/sqldb"
+
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite opens an in-memory SQLite database.
+func openSQLite(t *testing.T) *sql.DB {
+	service, err := sql.Open("sqlite", "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The in-memory database lives as long as its connection.
+	service.SetMaxOpenConns(1)
+	return service
+}
+
+func newApp(db sessions.Database) *iris.Application {
+	app := iris.New()
+	sess := sessions.New(sessions.Config{Cookie: "mysessionid", Expires: time.Hour})
+	sess.UseDatabase(db)
+	app.Use(sess.Handler())
+
+	app.Get("/set", func(ctx iris.Context) {
+		session := sessions.Get(ctx)
+		for key, value := range ctx.URLParams() {
+			session.Set(key, value)
+		}
+	})
+
+	app.Get("/get", func(ctx iris.Context) {
+		ctx.JSON(sessions.Get(ctx).GetAll())
+	})
+
+	app.Get("/delete", func(ctx iris.Context) {
+		sessions.Get(ctx).Delete(ctx.URLParam("key"))
+	})
+
+	app.Get("/clear", func(ctx iris.Context) {
+		sessions.Get(ctx).Clear()
+	})
+
+	app.Get("/destroy", func(ctx iris.Context) {
+		sessions.Get(ctx).Man.Destroy(ctx)
+	})
+
+	return app
+}
+
+func TestDatabase(t *testing.T) {
+	for _, blob := range []bool{false, true} {
+		service := openSQLite(t)
+		db, err := sqldb.New(service, sqldb.Config{Dialect: sqldb.SQLite, Blob: blob, CleanupInterval: -1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e := httptest.New(t, newApp(db), httptest.URL("http://example.com"))
+		// The session cookie is sent on the first response only.
+		cookie := e.GET("/set").WithQuery("name", "iris").WithQuery("age", "7").Expect().Status(httptest.StatusOK).
+			Cookie("mysessionid").Value().Raw()
+		e.GET("/get").Expect().Status(httptest.StatusOK).JSON().IsEqual(iris.Map{"name": "iris", "age": "7"})
+		e.GET("/delete").WithQuery("key", "age").Expect().Status(httptest.StatusOK)
+		e.GET("/get").Expect().Status(httptest.StatusOK).JSON().IsEqual(iris.Map{"name": "iris"})
+
+		// A new server, e.g. after a restart, loads the session from the database.
+		e2 := httptest.New(t, newApp(db), httptest.URL("http://example.com"))
+		e2.GET("/get").WithCookie("mysessionid", cookie).Expect().Status(httptest.StatusOK).
+			JSON().IsEqual(iris.Map{"name": "iris"})
+
+		e.GET("/clear").Expect().Status(httptest.StatusOK)
+		e.GET("/get").Expect().Status(httptest.StatusOK).JSON().Object().IsEmpty()
+
+		e.GET("/set").WithQuery("name", "iris").Expect().Status(httptest.StatusOK)
+		e.GET("/destroy").Expect().Status(httptest.StatusOK)
+		if n := db.Len(cookie); n != 0 {
+			t.Fatalf("[blob=%v] expected no values after destroy but got: %d", blob, n)
+		}
+
+		if err = db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDatabaseExpiration(t *testing.T) {
+	db, err := sqldb.New(openSQLite(t), sqldb.Config{Dialect: sqldb.SQLite, CleanupInterval: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.Acquire("sid", time.Hour)
+	if err = db.Set("sid", "key", "value", 0, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if lifetime := db.Acquire("sid", time.Hour); lifetime.IsZero() {
+		t.Fatalf("expected the stored lifetime")
+	}
+
+	if err = db.OnUpdateExpiration("sid", time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.OnUpdateExpiration("unknown", time.Hour); err != sessions.ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got: %v", err)
+	}
+
+	time.Sleep(time.Millisecond)
+	if err = db.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := db.Get("sid", "key"); got != nil {
+		t.Fatalf("expected the expired session to be removed but got: %v", got)
+	}
+
+	// Values with a time-to-live.
+	db.Acquire("sid", 0)
+	db.Set("sid", "temp", "value", time.Nanosecond, false)
+	time.Sleep(time.Millisecond)
+	if n := db.Len("sid"); n != 0 {
+		t.Fatalf("expected the expired value to be ignored but got %d values", n)
+	}
+}
@@ -0,0 +1,4 @@
+// Package sqlitetest runs the sqldb sessions database tests against SQLite.
+// It is a separate module, so the SQLite driver is not a dependency of Iris,
+// run its tests from this directory: go test ./...
+package sqlitetest
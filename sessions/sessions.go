@@ -3,6 +3,7 @@ package sessions
 import (
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/kataras/iris/v12/context"
@@ -21,6 +22,7 @@ func init() {
 type Sessions struct {
 	config   Config
 	provider *provider
+	usersMu  sync.Mutex // protects the user index entries, see BindUser.
 
 	cookieOptions []context.CookieOption // options added on each session cookie action.
 }
@@ -130,12 +132,23 @@ func (s *Sessions) getCookie(ctx *context.Context, cookieOptions []context.Cooki
 // on MVC and APIContainer as well.
 //
 // NOTE: Use `app.Use(sess.Handler())` instead, avoid using `Start` manually.
+//
+// When the request is rejected by the FingerprintReject policy,
+// see Config.FingerprintMismatch, the context is stopped with 403 Forbidden
+// and a new session which is never stored is returned instead.
 func (s *Sessions) Start(ctx *context.Context, cookieOptions ...context.CookieOption) *Session {
+	sess, _ := s.start(ctx, cookieOptions)
+	return sess
+}
+
+// start is the `Start` method which reports false
+// when the request is rejected by the fingerprint mismatch policy.
+func (s *Sessions) start(ctx *context.Context, cookieOptions []context.CookieOption) (*Session, bool) {
 	// cookieValue := s.getCookieValue(ctx, cookieOptions)
 	cookie := s.getCookie(ctx, cookieOptions)
 	if cookie != nil {
 		sid := cookie.Value
-		if sid == "" { // rare case: a client may contains a cookie with session name but with empty value.
+		if sid == "" || isReservedKey(sid) { // rare case: a client may contains a cookie with session name but with empty value.
 			// ctx.RemoveCookie(cookie.Name)
 			cookie = nil
 		} else if cookie.Expires.Add(time.Second).After(time.Now()) { // rare case: of custom clients that may hold expired cookies.
@@ -153,7 +166,21 @@ func (s *Sessions) Start(ctx *context.Context, cookieOptions ...context.CookieOp
 			} else {
 				//	untilExpirationDur := time.Until(cookie.Expires)
 				// ^ this should be
-				return s.provider.Read(s, sid, s.config.Expires) // cookie exists and it's valid, let's return its session.
+				s.provider.BeginRequest(ctx, sid)
+				sess := s.provider.Read(s, sid, s.config.lifetime()) // cookie exists and it's valid, let's return its session.
+				if s.config.guarded() {
+					var ok bool
+					if sess, ok = s.check(ctx, sess); !ok {
+						// rejected by the fingerprint mismatch policy,
+						// the original session is kept for its client.
+						return newProvider().Init(s, s.config.SessionIDGenerator(ctx), 0), false
+					}
+				}
+
+				if sess != nil {
+					s.seen(ctx, sess)
+					return sess, true
+				}
 			}
 		}
 	}
@@ -161,7 +188,11 @@ func (s *Sessions) Start(ctx *context.Context, cookieOptions ...context.CookieOp
 	// Cookie doesn't exist, let's generate a session and set a cookie.
 	sid := s.config.SessionIDGenerator(ctx)
 
-	sess := s.provider.Init(s, sid, s.config.Expires)
+	s.provider.BeginRequest(ctx, sid)
+	sess := s.provider.Init(s, sid, s.config.lifetime())
+	if s.config.guarded() {
+		s.guard(ctx, sess)
+	}
 	// n := s.provider.db.Len(sid)
 	// fmt.Printf("db.Len(%s) = %d\n", sid, n)
 	// if n > 0 {
@@ -170,7 +201,7 @@ func (s *Sessions) Start(ctx *context.Context, cookieOptions ...context.CookieOp
 	// 	})
 	// }
 	s.updateCookie(ctx, sid, s.config.Expires, cookieOptions...)
-	return sess
+	return sess, true
 }
 
 const sessionContextKey = "iris.session"
@@ -181,7 +212,12 @@ const sessionContextKey = "iris.session"
 // Call `Handler()` once per sessions manager.
 func (s *Sessions) Handler(requestOptions ...context.CookieOption) context.Handler {
 	return func(ctx *context.Context) {
-		session := s.Start(ctx, requestOptions...) // this cookie's end-developer's custom options.
+		session, ok := s.start(ctx, requestOptions) // this cookie's end-developer's custom options.
+		if !ok {
+			// rejected by the fingerprint mismatch policy.
+			s.provider.EndRequest(ctx, nil)
+			return
+		}
 
 		ctx.Values().Set(sessionContextKey, session)
 		ctx.Next()
@@ -1,6 +1,7 @@
 package sessions_test
 
 import (
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -323,3 +324,238 @@ func TestSessionsUpdateExpirationConcurrently(t *testing.T) {
 	tt.Status(httptest.StatusOK).Body().IsEqual(id)
 	tt.Cookie(cookieName).MaxAge().InRange(29*time.Minute, 30*time.Minute)
 }
+
+func TestSessionsRegenerate(t *testing.T) {
+	app := iris.New()
+	sess := sessions.New(sessions.Config{Cookie: "mycustomsessionid", AbsoluteTimeout: time.Hour})
+	app.Use(sess.Handler())
+
+	app.Get("/set", func(ctx iris.Context) {
+		session := sessions.Get(ctx)
+		session.Set("name", "iris")
+		ctx.WriteString(session.ID())
+	})
+
+	app.Get("/regenerate", func(ctx iris.Context) {
+		session := sessions.Get(ctx)
+		if err := session.Regenerate(ctx); err != nil {
+			ctx.StopWithError(iris.StatusInternalServerError, err)
+			return
+		}
+
+		ctx.WriteString(session.ID())
+	})
+
+	app.Get("/get", func(ctx iris.Context) {
+		session := sessions.Get(ctx)
+		ctx.JSON(iris.Map{"id": session.ID(), "values": session.GetAll(), "len": session.Len()})
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+
+	oldID := e.GET("/set").Expect().Status(httptest.StatusOK).Body().Raw()
+	newID := e.GET("/regenerate").Expect().Status(httptest.StatusOK).Body().NotEqual(oldID).Raw()
+	e.GET("/get").Expect().Status(httptest.StatusOK).JSON().IsEqual(iris.Map{
+		"id":     newID,
+		"values": iris.Map{"name": "iris"},
+		"len":    1,
+	})
+
+	// The old session id is not accepted anymore.
+	e.GET("/get").WithCookie("mycustomsessionid", oldID).Expect().Status(httptest.StatusOK).
+		JSON().Object().Value("id").NotEqual(oldID)
+}
+
+func TestSessionsTimeouts(t *testing.T) {
+	app := iris.New()
+	sess := sessions.New(sessions.Config{
+		Cookie:          "mycustomsessionid",
+		IdleTimeout:     300 * time.Millisecond,
+		AbsoluteTimeout: 900 * time.Millisecond,
+	})
+	app.Use(sess.Handler())
+
+	app.Get("/set", func(ctx iris.Context) {
+		session := sessions.Get(ctx)
+		session.Set("name", "iris")
+		ctx.WriteString(session.ID())
+	})
+
+	app.Get("/get", func(ctx iris.Context) {
+		ctx.WriteString(sessions.Get(ctx).GetString("name"))
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+
+	// Unknown session ids are never accepted.
+	e.GET("/set").WithCookie("mycustomsessionid", "fixated").Expect().Status(httptest.StatusOK).
+		Body().NotEqual("fixated")
+
+	// Idle timeout.
+	e.GET("/set").Expect().Status(httptest.StatusOK)
+	time.Sleep(150 * time.Millisecond)
+	e.GET("/get").Expect().Status(httptest.StatusOK).Body().IsEqual("iris")
+	time.Sleep(450 * time.Millisecond)
+	e.GET("/get").Expect().Status(httptest.StatusOK).Body().IsEmpty()
+
+	// Absolute timeout, the session is used before its idle timeout.
+	e.GET("/set").Expect().Status(httptest.StatusOK)
+	for i := 0; i < 4; i++ {
+		time.Sleep(150 * time.Millisecond)
+		e.GET("/get").Expect().Status(httptest.StatusOK).Body().IsEqual("iris")
+	}
+	time.Sleep(350 * time.Millisecond)
+	e.GET("/get").Expect().Status(httptest.StatusOK).Body().IsEmpty()
+}
+
+func TestSessionsFingerprint(t *testing.T) {
+	tests := []struct {
+		policy             sessions.FingerprintMismatchPolicy
+		expectedStatusCode int
+		expectedBody       string
+		originalKept       bool
+	}{
+		{sessions.FingerprintDestroy, httptest.StatusOK, "", false},
+		{sessions.FingerprintRenew, httptest.StatusOK, "", true},
+		{sessions.FingerprintReject, httptest.StatusForbidden, "Forbidden", true},
+	}
+
+	for _, tt := range tests {
+		app := iris.New()
+		sess := sessions.New(sessions.Config{
+			Cookie:              "mycustomsessionid",
+			Fingerprint:         sessions.FingerprintUserAgent,
+			FingerprintMismatch: tt.policy,
+		})
+		app.Use(sess.Handler())
+
+		app.Get("/set", func(ctx iris.Context) {
+			session := sessions.Get(ctx)
+			session.Set("name", "iris")
+			ctx.WriteString(session.ID())
+		})
+
+		app.Get("/get", func(ctx iris.Context) {
+			ctx.WriteString(sessions.Get(ctx).GetString("name"))
+		})
+
+		e := httptest.New(t, app, httptest.URL("http://example.com"))
+
+		sid := e.GET("/set").WithHeader("User-Agent", "client").Expect().Status(httptest.StatusOK).Body().Raw()
+		e.GET("/get").WithHeader("User-Agent", "client").WithCookie("mycustomsessionid", sid).Expect().
+			Status(httptest.StatusOK).Body().IsEqual("iris")
+
+		e.GET("/get").WithHeader("User-Agent", "attacker").WithCookie("mycustomsessionid", sid).Expect().
+			Status(tt.expectedStatusCode).Body().IsEqual(tt.expectedBody)
+
+		expectedBody := ""
+		if tt.originalKept {
+			expectedBody = "iris"
+		}
+		e.GET("/get").WithHeader("User-Agent", "client").WithCookie("mycustomsessionid", sid).Expect().
+			Status(httptest.StatusOK).Body().IsEqual(expectedBody)
+	}
+
+	// Start never returns nil, a rejected request receives a session which is not stored.
+	app := iris.New()
+	sess := sessions.New(sessions.Config{
+		Cookie:              "mycustomsessionid",
+		Fingerprint:         sessions.FingerprintUserAgent,
+		FingerprintMismatch: sessions.FingerprintReject,
+	})
+
+	app.Get("/start", func(ctx iris.Context) {
+		session := sess.Start(ctx)
+		if ctx.IsStopped() {
+			session.Set("name", "attacker")
+			return
+		}
+
+		if name := session.GetString("name"); name != "" {
+			ctx.WriteString(name)
+			return
+		}
+
+		session.Set("name", "iris")
+		ctx.WriteString(session.ID())
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	sid := e.GET("/start").WithHeader("User-Agent", "client").Expect().Status(httptest.StatusOK).Body().Raw()
+	e.GET("/start").WithHeader("User-Agent", "attacker").WithCookie("mycustomsessionid", sid).Expect().
+		Status(httptest.StatusForbidden).Cookies().IsEmpty()
+	e.GET("/start").WithHeader("User-Agent", "client").WithCookie("mycustomsessionid", sid).Expect().
+		Status(httptest.StatusOK).Body().IsEqual("iris")
+}
+
+func TestSessionsUserIndex(t *testing.T) {
+	app := iris.New()
+	sess := sessions.New(sessions.Config{Cookie: "mycustomsessionid"})
+	app.Use(sess.Handler())
+
+	app.Get("/signin", func(ctx iris.Context) {
+		session := sessions.Get(ctx)
+		if err := session.BindUser(ctx, "42"); err != nil {
+			ctx.StopWithError(iris.StatusInternalServerError, err)
+			return
+		}
+
+		if err := session.Regenerate(ctx); err != nil {
+			ctx.StopWithError(iris.StatusInternalServerError, err)
+			return
+		}
+
+		session.Set("user", "42")
+		ctx.WriteString(session.ID())
+	})
+
+	app.Get("/user", func(ctx iris.Context) {
+		ctx.WriteString(sessions.Get(ctx).GetString("user"))
+	})
+
+	app.Get("/sessions", func(ctx iris.Context) {
+		list, err := sess.ListByUser("42")
+		if err != nil {
+			ctx.StopWithError(iris.StatusInternalServerError, err)
+			return
+		}
+
+		ids := make([]string, 0, len(list))
+		for _, info := range list {
+			if info.User != "42" || info.CreatedAt.IsZero() || info.LastSeenAt.IsZero() {
+				ctx.StopWithText(iris.StatusInternalServerError, "invalid info")
+				return
+			}
+
+			ids = append(ids, info.ID)
+		}
+
+		ctx.JSON(ids)
+	})
+
+	app.Get("/logout_others", func(ctx iris.Context) {
+		n, err := sess.RevokeAllForUser("42", sessions.Get(ctx).ID())
+		if err != nil {
+			ctx.StopWithError(iris.StatusInternalServerError, err)
+			return
+		}
+
+		ctx.WriteString(strconv.Itoa(n))
+	})
+
+	desktop := httptest.New(t, app, httptest.URL("http://example.com"))
+	mobile := httptest.New(t, app, httptest.URL("http://example.com"))
+
+	desktopID := desktop.GET("/signin").Expect().Status(httptest.StatusOK).Body().Raw()
+	mobileID := mobile.GET("/signin").Expect().Status(httptest.StatusOK).Body().Raw()
+
+	desktop.GET("/sessions").Expect().Status(httptest.StatusOK).
+		JSON().Array().ContainsOnly(desktopID, mobileID)
+
+	desktop.GET("/logout_others").Expect().Status(httptest.StatusOK).Body().IsEqual("1")
+	mobile.GET("/user").Expect().Status(httptest.StatusOK).Body().IsEmpty()
+	desktop.GET("/user").Expect().Status(httptest.StatusOK).Body().IsEqual("42")
+
+	desktop.GET("/sessions").Expect().Status(httptest.StatusOK).
+		JSON().Array().ContainsOnly(desktopID)
+}
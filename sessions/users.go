@@ -0,0 +1,289 @@
+package sessions
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+// SessionInfo holds the metadata of a session which is bound to a user,
+// see the `Session.BindUser` and `Sessions.ListByUser` methods.
+type SessionInfo struct {
+	// ID is the session ID.
+	ID string `json:"id"`
+	// User is the identifier of the session's user.
+	User string `json:"user"`
+	// CreatedAt is the creation time of the session, if known,
+	// otherwise the time it was bound to its user.
+	CreatedAt time.Time `json:"created_at"`
+	// LastSeenAt is the time of the last request of the session.
+	// It's updated at most once per minute.
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// IP is the remote address of the last request of the session.
+	IP string `json:"ip"`
+	// UserAgent is the user agent of the last request of the session.
+	UserAgent string `json:"user_agent"`
+}
+
+// lastSeenInterval is the minimum interval between two updates
+// of the SessionInfo.LastSeenAt field of a session.
+const lastSeenInterval = time.Minute
+
+const (
+	// infoKey is the reserved session value of its SessionInfo.
+	infoKey = reservedKeyPrefix + "info"
+	// userIndexPrefix is the prefix of the database entries
+	// which hold the session IDs of each user.
+	userIndexPrefix = reservedKeyPrefix + "user."
+	// userIndexKeyPrefix is the prefix of the keys of a user index entry.
+	userIndexKeyPrefix = "sid:"
+)
+
+func userIndexSID(user string) string {
+	return userIndexPrefix + user
+}
+
+// BindUser binds the session to a user and indexes it,
+// so the user's sessions can be listed and revoked through
+// the `Sessions.ListByUser` and `RevokeAllForUser` methods,
+// e.g. to build a "log out other devices" page.
+// Call it on sign in, the `Regenerate` method keeps the binding.
+//
+// It returns ErrNotImplemented on client-side databases.
+func (s *Session) BindUser(ctx *context.Context, user string) error {
+	if s.provider.dbRequestStarter != nil {
+		// client-side database, the sessions can not be listed.
+		return ErrNotImplemented
+	}
+
+	now := time.Now()
+	info := SessionInfo{
+		ID:         s.sid,
+		User:       user,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		IP:         ctx.RemoteAddr(),
+		UserAgent:  ctx.GetHeader("User-Agent"),
+	}
+
+	if created, err := time.Parse(time.RFC3339Nano, s.GetString(createdKey)); err == nil {
+		info.CreatedAt = created
+	}
+
+	previous, bound := s.Info()
+	if bound && previous.User != user {
+		s.Man.unindexUser(previous.User, s.sid)
+	}
+
+	if err := s.setInfo(info); err != nil {
+		return err
+	}
+
+	return s.Man.indexUser(user, s.sid, "")
+}
+
+// Info returns the metadata of the session
+// and reports whether it's bound to a user, see `BindUser`.
+func (s *Session) Info() (SessionInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.infoLoaded {
+		s.info = loadInfo(s.provider.db, s.sid)
+		s.infoLoaded = true
+	}
+
+	if s.info == nil {
+		return SessionInfo{}, false
+	}
+
+	return *s.info, true
+}
+
+func (s *Session) setInfo(info SessionInfo) error {
+	b, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	if err = s.provider.db.Set(s.sid, infoKey, string(b), s.Lifetime.DurationUntilExpiration(), false); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.info = &info
+	s.infoLoaded = true
+	s.mu.Unlock()
+	return nil
+}
+
+// regenerated moves the user binding of the session
+// from its "oldsid" to its current ID, see `Regenerate`.
+func (s *Session) regenerated(oldsid string) error {
+	info, bound := s.Info()
+	if !bound {
+		return nil
+	}
+
+	info.ID = s.sid
+	if err := s.setInfo(info); err != nil {
+		return err
+	}
+
+	return s.Man.indexUser(info.User, s.sid, oldsid)
+}
+
+// seen updates the last seen time, the IP and the user agent
+// of a session which is bound to a user.
+func (s *Sessions) seen(ctx *context.Context, sess *Session) {
+	info, bound := sess.Info()
+	if !bound || time.Since(info.LastSeenAt) < lastSeenInterval {
+		return
+	}
+
+	info.LastSeenAt = time.Now()
+	info.IP = ctx.RemoteAddr()
+	info.UserAgent = ctx.GetHeader("User-Agent")
+	if err := sess.setInfo(info); err != nil {
+		s.config.Logger.Debugf("sessions: unable to update the session info: %v", err)
+	}
+}
+
+func loadInfo(db Database, sid string) *SessionInfo {
+	v, ok := db.Get(sid, infoKey).(string)
+	if !ok || v == "" {
+		return nil
+	}
+
+	var info SessionInfo
+	if err := json.Unmarshal([]byte(v), &info); err != nil {
+		return nil
+	}
+
+	return &info
+}
+
+// indexUser adds the "sid" to the index of the "user"
+// and removes the "oldsid", if not empty.
+func (s *Sessions) indexUser(user, sid, oldsid string) error {
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	db := s.provider.db
+	indexSID := userIndexSID(user)
+	if len(userIndex(db, indexSID)) == 0 {
+		db.Acquire(indexSID, 0)
+	}
+
+	if oldsid != "" {
+		db.Delete(indexSID, userIndexKeyPrefix+oldsid)
+	}
+
+	return db.Set(indexSID, userIndexKeyPrefix+sid, sid, 0, false)
+}
+
+func (s *Sessions) unindexUser(user string, sids ...string) {
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	db := s.provider.db
+	indexSID := userIndexSID(user)
+	for _, sid := range sids {
+		db.Delete(indexSID, userIndexKeyPrefix+sid)
+	}
+
+	if len(userIndex(db, indexSID)) == 0 {
+		db.Release(indexSID)
+	}
+}
+
+// userIndex returns the session IDs of a user index entry.
+// The entry may contain other values too, e.g. the redis session id.
+func userIndex(db Database, indexSID string) (sids []string) {
+	db.Visit(indexSID, func(key string, _ interface{}) {
+		if strings.HasPrefix(key, userIndexKeyPrefix) {
+			sids = append(sids, strings.TrimPrefix(key, userIndexKeyPrefix))
+		}
+	})
+
+	return
+}
+
+// ListByUser returns the metadata of the sessions of a "user",
+// most recently seen first. See the `Session.BindUser` method.
+// The destroyed and expired sessions are removed from the user's index.
+//
+// It returns ErrNotImplemented on client-side databases.
+func (s *Sessions) ListByUser(user string) ([]SessionInfo, error) {
+	if s.provider.dbRequestStarter != nil {
+		return nil, ErrNotImplemented
+	}
+
+	db := s.provider.db
+
+	var (
+		list  []SessionInfo
+		stale []string
+	)
+
+	for _, sid := range userIndex(db, userIndexSID(user)) {
+		info := loadInfo(db, sid)
+		if info == nil || info.User != user || info.ID != sid {
+			stale = append(stale, sid)
+			continue
+		}
+
+		list = append(list, *info)
+	}
+
+	if len(stale) > 0 {
+		s.unindexUser(user, stale...)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeenAt.After(list[j].LastSeenAt)
+	})
+
+	return list, nil
+}
+
+// RevokeAllForUser destroys the sessions of a "user",
+// except the "exceptSessionIDs" ones, e.g. the current session's ID.
+// It returns the number of the destroyed sessions.
+//
+// It returns ErrNotImplemented on client-side databases.
+func (s *Sessions) RevokeAllForUser(user string, exceptSessionIDs ...string) (int, error) {
+	list, err := s.ListByUser(user)
+	if err != nil {
+		return 0, err
+	}
+
+	var revoked []string
+	for _, info := range list {
+		if isException(info.ID, exceptSessionIDs) {
+			continue
+		}
+
+		s.provider.Destroy(info.ID)
+		revoked = append(revoked, info.ID)
+	}
+
+	if len(revoked) > 0 {
+		s.unindexUser(user, revoked...)
+	}
+
+	return len(revoked), nil
+}
+
+func isException(sid string, exceptions []string) bool {
+	for _, exception := range exceptions {
+		if sid == exception {
+			return true
+		}
+	}
+
+	return false
+}
@@ -221,6 +221,12 @@ func (e ErrorCodeName) Error() string {
 	return ""
 }
 
+// StatusCode returns the HTTP status code of a registered ErrorCodeName,
+// zero if it's not registered.
+func (e ErrorCodeName) StatusCode() int {
+	return errorCodeMap[e].Status
+}
+
 type joinedErrors interface{ Unwrap() []error }
 
 // Wrap wraps the given error with this ErrorCodeName.
@@ -327,7 +333,7 @@ func (e ErrorCodeName) Err(ctx *context.Context, err error) {
 	if httpErr, ok := err.(*Error); ok {
 		if errorCode, ok := errorCodeMap[e]; ok {
 			httpErr.ErrorCode = errorCode
-			ctx.StopWithJSON(errorCode.Status, httpErr) // here we override the fail function and send the error as it is.
+			send(ctx, httpErr) // here we override the fail function and send the error as it is.
 			return
 		}
 	}
@@ -541,5 +547,45 @@ func fail(ctx *context.Context, codeName ErrorCodeName, msg, details string, val
 	}
 
 	// ctx.SetErr(&err)
-	ctx.StopWithJSON(errorCode.Status, err)
+	send(ctx, &err)
+}
+
+// send stops the handlers chain and writes the error to the client,
+// as a Problem when the `Configuration.EnableProblemDetails` setting is true.
+func send(ctx *context.Context, err *Error) {
+	if ctx.Application().ConfigurationReadOnly().GetEnableProblemDetails() {
+		ctx.StopWithStatus(err.ErrorCode.Status)
+		ctx.WriteProblemDetails(err.ErrorCode.Status, err.Problem())
+		return
+	}
+
+	ctx.StopWithJSON(err.ErrorCode.Status, err)
+}
+
+// Problem converts the error to an RFC 9457 Problem.
+// The message is the problem's title and the details its detail.
+// The canonical name, the validation errors and the data
+// are set to the "code", "errors" and "data" extension members respectively.
+func (err *Error) Problem() context.Problem {
+	problem := context.NewProblem()
+	if err.Message != "" {
+		problem.Title(err.Message)
+	}
+
+	if err.Details != "" {
+		problem.Detail(err.Details)
+	}
+
+	problem.Status(err.ErrorCode.Status)
+	problem.Key("code", err.ErrorCode.CanonicalName)
+
+	if err.Validation != nil {
+		problem.Key("errors", err.Validation)
+	}
+
+	if len(err.Data) > 0 {
+		problem.Key("data", err.Data)
+	}
+
+	return problem
 }
@@ -0,0 +1,579 @@
+package openapi
+
+import (
+	stdContext "context"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/router"
+	"github.com/kataras/iris/v12/hero"
+	"github.com/kataras/iris/v12/macro"
+	"github.com/kataras/iris/v12/macro/interpreter/ast"
+	"github.com/kataras/iris/v12/macro/interpreter/parser"
+	"github.com/kataras/iris/v12/x/errors"
+)
+
+// Routes is the source of the routes to document,
+// e.g. an Iris Application or a Party.
+type Routes interface {
+	GetRoutes() []*router.Route
+	Macros() *macro.Macros
+}
+
+// Generator builds the OpenAPI document of an application's routes.
+// The routes are described by their path parameters,
+// the inputs and the results of their dependency injection handlers
+// and the details given through the `Route` method.
+//
+// The details are kept by the method, subdomain and path of the route,
+// so they still apply after a `router.RoutesTransaction` is committed,
+// which registers copies of the previous routes.
+type Generator struct {
+	// Info holds the metadata of the API.
+	Info Info
+	// Servers is an optional list of the servers of the API.
+	Servers []Server
+	// Filter reports whether a route should be documented.
+	// The http error handlers and the routes of this package
+	// are never documented.
+	Filter func(r *router.Route) bool
+
+	mu     sync.RWMutex
+	routes map[routeKey]*RouteSpec
+}
+
+// routeKey is the identity of a route which is kept across route transactions.
+type routeKey struct {
+	method    string
+	subdomain string
+	path      string
+}
+
+func newRouteKey(r *router.Route) routeKey {
+	return routeKey{method: r.Method, subdomain: r.Subdomain, path: r.Tmpl().Src}
+}
+
+// New returns a new OpenAPI document generator.
+func New(info Info) *Generator {
+	return &Generator{
+		Info:   info,
+		routes: make(map[routeKey]*RouteSpec),
+	}
+}
+
+// RouteSpec holds the details of a route which can not be resolved
+// from its handlers, e.g. the error codes of a common Iris handler
+// or the body which is read through `Context.ReadJSON`.
+type RouteSpec struct {
+	summary    string
+	tags       []string
+	deprecated bool
+	body       reflect.Type
+	query      reflect.Type
+	responses  map[int]reflect.Type
+	errors     []errors.ErrorCodeName
+}
+
+// Route returns the details of a route, see `RouteSpec`.
+// A route registered later with the same method, subdomain and path,
+// e.g. through a `router.RoutesTransaction`, shares the same details.
+func (g *Generator) Route(r *router.Route) *RouteSpec {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := newRouteKey(r)
+	spec, ok := g.routes[key]
+	if !ok {
+		spec = &RouteSpec{responses: make(map[int]reflect.Type)}
+		g.routes[key] = spec
+	}
+
+	return spec
+}
+
+// Summary sets the short summary of the route.
+// The route's Description field is used as the operation's description.
+func (s *RouteSpec) Summary(summary string) *RouteSpec {
+	s.summary = summary
+	return s
+}
+
+// Tags groups the route under the given tags.
+func (s *RouteSpec) Tags(tags ...string) *RouteSpec {
+	s.tags = append(s.tags, tags...)
+	return s
+}
+
+// Deprecated marks the route as deprecated.
+func (s *RouteSpec) Deprecated() *RouteSpec {
+	s.deprecated = true
+	return s
+}
+
+// Body sets the JSON request body of the route
+// to the type of the "v" value, e.g. User{}.
+func (s *RouteSpec) Body(v interface{}) *RouteSpec {
+	s.body = reflect.TypeOf(v)
+	return s
+}
+
+// Query sets the URL query parameters of the route
+// to the "url" tagged fields of the "v" struct value, see `Context.ReadQuery`.
+func (s *RouteSpec) Query(v interface{}) *RouteSpec {
+	s.query = reflect.TypeOf(v)
+	return s
+}
+
+// Response sets the JSON response of the "statusCode"
+// to the type of the "v" value. A nil "v" describes a response without a body.
+func (s *RouteSpec) Response(statusCode int, v interface{}) *RouteSpec {
+	s.responses[statusCode] = reflect.TypeOf(v)
+	return s
+}
+
+// Errors adds the x/errors error codes which the route may respond with.
+func (s *RouteSpec) Errors(codes ...errors.ErrorCodeName) *RouteSpec {
+	s.errors = append(s.errors, codes...)
+	return s
+}
+
+func (g *Generator) filter(r *router.Route) bool {
+	if r.StatusCode > 0 || r.MainHandlerName == handlerName {
+		return false
+	}
+
+	return g.Filter == nil || g.Filter(r)
+}
+
+// Generate builds the OpenAPI document of the routes.
+func (g *Generator) Generate(api Routes) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    g.Info,
+		Servers: g.Servers,
+		Paths:   make(map[string]*PathItem),
+	}
+
+	b := &builder{
+		schemas: newSchemas(),
+		macros:  api.Macros(),
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	for _, r := range api.GetRoutes() {
+		if !g.filter(r) {
+			continue
+		}
+
+		field := (&PathItem{}).operation(r.Method)
+		if field == nil { // e.g. CONNECT.
+			continue
+		}
+
+		path := documentPath(r.Tmpl())
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		*item.operation(r.Method) = b.operation(r, g.routes[newRouteKey(r)])
+	}
+
+	if len(b.schemas.components) > 0 {
+		doc.Components = &Components{Schemas: b.schemas.components}
+	}
+
+	return doc
+}
+
+// documentPath converts a route path template to an OpenAPI path,
+// e.g. /users/{id:uint64 min(1)} to /users/{id}.
+func documentPath(tmpl macro.Template) string {
+	path := tmpl.Src
+	for _, p := range tmpl.Params {
+		path = strings.Replace(path, p.Src, "{"+p.Name+"}", 1)
+	}
+
+	return path
+}
+
+type builder struct {
+	schemas *schemas
+	macros  *macro.Macros
+}
+
+func (b *builder) operation(r *router.Route, spec *RouteSpec) *Operation {
+	op := &Operation{
+		Description: r.Description,
+		Responses:   make(map[string]*Response),
+	}
+
+	if r.Name != r.Method+r.Subdomain+r.Tmpl().Src { // a custom route name.
+		op.OperationID = r.Name
+	}
+
+	tmpl := r.Tmpl()
+	for i := range tmpl.Params {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:     tmpl.Params[i].Name,
+			In:       "path",
+			Required: true,
+			Schema:   b.paramSchema(&tmpl.Params[i]),
+		})
+	}
+
+	if r.MainHandlerFunc != nil {
+		b.describeFunc(op, r)
+	}
+
+	if spec != nil {
+		b.describeSpec(op, spec)
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses[strconv.Itoa(http.StatusOK)] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+
+	return op
+}
+
+func (b *builder) describeSpec(op *Operation, spec *RouteSpec) {
+	op.Summary = spec.summary
+	op.Tags = spec.tags
+	op.Deprecated = spec.deprecated
+
+	if spec.body != nil {
+		op.RequestBody = b.requestBody(spec.body)
+	}
+
+	if spec.query != nil {
+		op.Parameters = append(op.Parameters, b.schemas.queryParameters(spec.query)...)
+	}
+
+	for statusCode, typ := range spec.responses {
+		response := &Response{Description: http.StatusText(statusCode)}
+		if typ != nil {
+			response.Content = jsonContent(b.schemas.of(typ))
+		}
+
+		op.Responses[strconv.Itoa(statusCode)] = response
+	}
+
+	if len(spec.errors) > 0 {
+		delete(op.Responses, "default") // replaced by the specific ones.
+		b.errorResponses(op, spec.errors)
+	}
+}
+
+var (
+	stdContextType = reflect.TypeOf((*stdContext.Context)(nil)).Elem()
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	resultType     = reflect.TypeOf((*hero.Result)(nil)).Elem()
+	preflightType  = reflect.TypeOf((*hero.PreflightResult)(nil)).Elem()
+	codeType       = reflect.TypeOf(hero.Code(0))
+)
+
+// describeFunc describes the inputs and the results of a dependency injection handler,
+// the same way the hero package binds them.
+func (b *builder) describeFunc(op *Operation, r *router.Route) {
+	typ := reflect.TypeOf(r.MainHandlerFunc)
+	if typ.Kind() != reflect.Func {
+		return
+	}
+
+	container := r.Party.ConfigureContainer().Container
+
+	for i := 0; i < typ.NumIn(); i++ {
+		in := typ.In(i)
+		if _, isParam := context.ParamResolvers[in]; isParam || in == stdContextType || isDependency(container, in) {
+			// path parameters and dependencies.
+			continue
+		}
+
+		if container.DisablePayloadAutoBinding || !isPayloadType(in) {
+			continue
+		}
+
+		if r.Method == http.MethodGet {
+			op.Parameters = append(op.Parameters, b.schemas.queryParameters(in)...)
+		} else {
+			op.RequestBody = b.requestBody(in)
+		}
+	}
+
+	for i := 0; i < typ.NumOut(); i++ {
+		out := typ.Out(i)
+		switch {
+		case out == errorType:
+			op.Responses["default"] = &Response{Description: "Error"}
+		case out.Kind() == reflect.Bool:
+			op.Responses[strconv.Itoa(http.StatusNotFound)] = &Response{Description: http.StatusText(http.StatusNotFound)}
+		case out.Kind() == reflect.Int, out == codeType:
+			// status code.
+		case out.Kind() == reflect.String:
+			if _, ok := op.Responses["200"]; !ok {
+				op.Responses["200"] = &Response{
+					Description: http.StatusText(http.StatusOK),
+					Content:     map[string]*MediaType{context.ContentTextHeaderValue: {Schema: &Schema{Type: SchemaType{"string"}}}},
+				}
+			}
+		case out.Kind() == reflect.Slice && out.Elem().Kind() == reflect.Uint8:
+			op.Responses["200"] = &Response{
+				Description: http.StatusText(http.StatusOK),
+				Content:     map[string]*MediaType{context.ContentBinaryHeaderValue: {Schema: &Schema{Type: SchemaType{"string"}, Format: "binary"}}},
+			}
+		case out.Implements(resultType) || out.Implements(preflightType):
+			// custom dispatcher, its response is unknown.
+		default:
+			op.Responses["200"] = &Response{
+				Description: http.StatusText(http.StatusOK),
+				Content:     jsonContent(b.schemas.of(out)),
+			}
+		}
+	}
+}
+
+// isDependency reports whether a registered dependency
+// of the container is bound to an input of "typ".
+func isDependency(c *hero.Container, typ reflect.Type) bool {
+	for _, d := range c.Dependencies {
+		if d.DestType == nil { // dynamic dependencies may skip any input.
+			continue
+		}
+
+		if d.Match != nil {
+			if d.Match(typ) {
+				return true
+			}
+		} else if hero.DefaultDependencyMatcher(d, typ) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPayloadType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Slice:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *builder) requestBody(typ reflect.Type) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  jsonContent(b.schemas.of(typ)),
+	}
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{context.ContentJSONHeaderValue: {Schema: schema}}
+}
+
+// errorResponses adds the responses of the x/errors error codes,
+// grouped by their HTTP status code.
+func (b *builder) errorResponses(op *Operation, codes []errors.ErrorCodeName) {
+	errorSchema := b.schemas.of(reflect.TypeOf(errors.Error{}))
+
+	names := make(map[int][]string)
+	for _, code := range codes {
+		statusCode := code.StatusCode()
+		names[statusCode] = append(names[statusCode], string(code))
+	}
+
+	for statusCode, canonicalNames := range names {
+		sort.Strings(canonicalNames)
+		op.Responses[strconv.Itoa(statusCode)] = &Response{
+			Description: strings.Join(canonicalNames, ", "),
+			Content:     jsonContent(errorSchema),
+		}
+	}
+}
+
+// macroSchemas holds the schemas of the builtin path parameter types,
+// the rest, including the custom ones which replace a builtin type,
+// are resolved by their Go type.
+var macroSchemas = map[*macro.Macro]func() *Schema{
+	macro.String:       func() *Schema { return &Schema{Type: SchemaType{"string"}} },
+	macro.Path:         func() *Schema { return &Schema{Type: SchemaType{"string"}} },
+	macro.Alphabetical: func() *Schema { return &Schema{Type: SchemaType{"string"}, Pattern: "^[a-zA-Z ]+$"} },
+	macro.File:         func() *Schema { return &Schema{Type: SchemaType{"string"}, Pattern: "^[a-zA-Z0-9_.-]*$"} },
+	macro.UUID:         func() *Schema { return &Schema{Type: SchemaType{"string"}, Format: "uuid"} },
+	macro.Mail:         func() *Schema { return &Schema{Type: SchemaType{"string"}, Format: "email"} },
+	macro.Email:        func() *Schema { return &Schema{Type: SchemaType{"string"}, Format: "email"} },
+	macro.Date:         func() *Schema { return &Schema{Type: SchemaType{"string"}, Pattern: `^\d{4}/\d{2}/\d{2}$`} },
+	macro.Weekday:      func() *Schema { return &Schema{Type: SchemaType{"string"}} },
+	macro.ULID: func() *Schema {
+		return &Schema{Type: SchemaType{"string"}, Pattern: "^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$"}
+	},
+	macro.Semver: func() *Schema {
+		return &Schema{Type: SchemaType{"string"}, Pattern: `^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`}
+	},
+	macro.Base64: func() *Schema { return &Schema{Type: SchemaType{"string"}, Format: "byte"} },
+	macro.Hex:    func() *Schema { return &Schema{Type: SchemaType{"string"}, Pattern: "^([0-9a-fA-F]{2})*$"} },
+	macro.Slug:   func() *Schema { return &Schema{Type: SchemaType{"string"}, Pattern: "^[a-z0-9]+(?:-[a-z0-9]+)*$"} },
+	macro.IPv4:   func() *Schema { return &Schema{Type: SchemaType{"string"}, Format: "ipv4"} },
+	macro.IPv6:   func() *Schema { return &Schema{Type: SchemaType{"string"}, Format: "ipv6"} },
+	macro.Enum:   func() *Schema { return &Schema{Type: SchemaType{"string"}} },
+	macro.Duration: func() *Schema {
+		return &Schema{Type: SchemaType{"string"}, Pattern: `^[-+]?(0|((\d+(\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h))+)$`}
+	},
+	macro.ISO8601: func() *Schema { return &Schema{Type: SchemaType{"string"}, Format: "date-time"} },
+}
+
+// paramSchema returns the schema of a path parameter,
+// based on its macro and its functions, e.g. {id:uint64 min(1)}.
+func (b *builder) paramSchema(p *macro.TemplateParam) *Schema {
+	schema := b.macroSchema(p.Type.Indent())
+
+	types := make([]ast.ParamType, 0, len(*b.macros))
+	for _, m := range *b.macros {
+		types = append(types, m)
+	}
+
+	stmt, err := parser.NewParamParser(p.Src).Parse(types)
+	if err != nil {
+		return schema
+	}
+
+	for _, fn := range stmt.Funcs {
+		if fn.Name == "" { // e.g. {color:enum(red,green)}.
+			fn.Name = p.Type.Indent()
+		}
+
+		applyParamFunc(schema, fn)
+	}
+
+	return schema
+}
+
+// macroSchema returns the schema of a parameter type,
+// the defined ones (see `macro.Definition`) are based on their base type's schema.
+func (b *builder) macroSchema(indent string) *Schema {
+	m := b.macros.Get(indent)
+	if m == nil {
+		return &Schema{Type: SchemaType{"string"}}
+	}
+
+	if fn, ok := macroSchemas[m]; ok {
+		return fn()
+	}
+
+	def := m.Definition()
+	if def == nil {
+		if m.GoType() == nil {
+			return &Schema{Type: SchemaType{"string"}}
+		}
+
+		return b.schemas.of(m.GoType())
+	}
+
+	schema := b.macroSchema(def.Type)
+	if def.Regexp != "" {
+		schema.Pattern = def.Regexp
+	}
+
+	for _, value := range def.Enum {
+		schema.Enum = append(schema.Enum, value)
+	}
+
+	if def.MinLength != nil {
+		schema.MinLength = intPtr(*def.MinLength)
+	}
+
+	if def.MaxLength != nil {
+		schema.MaxLength = intPtr(*def.MaxLength)
+	}
+
+	if def.Min != nil {
+		schema.Minimum = floatPtr(*def.Min)
+	}
+
+	if def.Max != nil {
+		schema.Maximum = floatPtr(*def.Max)
+	}
+
+	return schema
+}
+
+// applyParamFunc describes the builtin macro functions.
+func applyParamFunc(schema *Schema, fn ast.ParamFunc) {
+	numeric := schema.Type.Is("integer") || schema.Type.Is("number")
+
+	arg := func(i int) (float64, bool) {
+		if len(fn.Args) <= i {
+			return 0, false
+		}
+
+		n, err := strconv.ParseFloat(fn.Args[i], 64)
+		return n, err == nil
+	}
+
+	switch fn.Name {
+	case "min", "max":
+		n, ok := arg(0)
+		if !ok {
+			return
+		}
+
+		switch {
+		case numeric && fn.Name == "min":
+			schema.Minimum = floatPtr(n)
+		case numeric:
+			schema.Maximum = floatPtr(n)
+		case fn.Name == "min":
+			schema.MinLength = intPtr(int(n))
+		default:
+			schema.MaxLength = intPtr(int(n))
+		}
+	case "range":
+		min, minOK := arg(0)
+		max, maxOK := arg(1)
+		if minOK && maxOK {
+			schema.Minimum, schema.Maximum = floatPtr(min), floatPtr(max)
+		}
+	case "regexp":
+		if len(fn.Args) > 0 {
+			schema.Pattern = fn.Args[0]
+		}
+	case "prefix":
+		if len(fn.Args) > 0 {
+			schema.Pattern = "^" + regexp.QuoteMeta(fn.Args[0])
+		}
+	case "suffix":
+		if len(fn.Args) > 0 {
+			schema.Pattern = regexp.QuoteMeta(fn.Args[0]) + "$"
+		}
+	case "contains":
+		if len(fn.Args) > 0 {
+			schema.Pattern = regexp.QuoteMeta(fn.Args[0])
+		}
+	case "eq":
+		for _, value := range fn.Args {
+			schema.Enum = append(schema.Enum, value)
+		}
+	case "eqor", "enum":
+		for _, arg := range fn.Args {
+			// e.g. eqor(a,b) or eqor([a,b]).
+			for _, value := range strings.Split(strings.Trim(arg, "[]"), ",") {
+				schema.Enum = append(schema.Enum, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+	"sync"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+// handlerName is the name of the handlers of this package,
+// their routes are not documented.
+const handlerName = "iris.openapi"
+
+func init() {
+	context.SetHandlerName(`iris/x/openapi\..*`, handlerName)
+}
+
+// Handler returns a handler which serves the OpenAPI document
+// of the "api" routes as JSON. The document is generated on the first request,
+// so all routes are registered by then, and again when the routes change,
+// e.g. after a `router.RoutesTransaction` is committed.
+//
+// Example Code:
+//
+//	app.Get("/openapi.json", docs.Handler(app))
+func (g *Generator) Handler(api Routes) context.Handler {
+	var (
+		mu     sync.Mutex
+		routes []*router.Route
+		body   []byte
+		err    error
+	)
+
+	return func(ctx *context.Context) {
+		mu.Lock()
+		if current := api.GetRoutes(); !sameRoutes(routes, current) {
+			routes = current
+			body, err = g.Generate(api).JSON()
+		}
+		b, genErr := body, err
+		mu.Unlock()
+
+		if genErr != nil {
+			ctx.StopWithError(http.StatusInternalServerError, genErr)
+			return
+		}
+
+		ctx.ContentType(context.ContentJSONHeaderValue)
+		ctx.Write(b)
+	}
+}
+
+// sameRoutes reports whether "a" and "b" hold the same routes,
+// a committed routes transaction registers new ones.
+func sameRoutes(a, b []*router.Route) bool {
+	if a == nil || len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UITemplate is the HTML page of the `UI` handler.
+// It loads the Swagger UI assets from a CDN.
+var UITemplate = template.Must(template.New("openapi").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.Title}}</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+    <script>
+        window.onload = function () {
+            window.ui = SwaggerUIBundle({ url: "{{.SpecURL}}", dom_id: "#swagger-ui" });
+        };
+    </script>
+</body>
+</html>`))
+
+// UI returns a handler which serves an API documentation page
+// of the OpenAPI document served at "specURL", see `UITemplate`.
+//
+// Example Code:
+//
+//	app.Get("/docs", openapi.UI("/openapi.json"))
+func UI(specURL string) context.Handler {
+	data := struct {
+		Title   string
+		SpecURL string
+	}{"API Documentation", specURL}
+
+	return func(ctx *context.Context) {
+		ctx.ContentType(context.ContentHTMLHeaderValue)
+		if err := UITemplate.Execute(ctx, data); err != nil {
+			ctx.StopWithError(http.StatusInternalServerError, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads an OpenAPI 3 document from a JSON or YAML file.
+func Load(filename string) (*Document, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes an OpenAPI 3 document from JSON or YAML data.
+func Parse(data []byte) (*Document, error) {
+	var v interface{}
+	// YAML is a superset of JSON.
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	// The document is converted to JSON,
+	// so the types decode the same way for both formats.
+	b, err := json.Marshal(normalizeYAML(v))
+	if err != nil {
+		return nil, err
+	}
+
+	doc := new(Document)
+	if err = json.Unmarshal(b, doc); err != nil {
+		return nil, err
+	}
+
+	if doc.OpenAPI == "" {
+		return nil, fmt.Errorf("openapi: missing openapi version field")
+	}
+
+	return doc, nil
+}
+
+// normalizeYAML converts the YAML maps with non-string keys,
+// e.g. the response status codes, to JSON compatible ones.
+func normalizeYAML(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range value {
+			value[k] = normalizeYAML(elem)
+		}
+		return value
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, elem := range value {
+			m[fmt.Sprint(k)] = normalizeYAML(elem)
+		}
+		return m
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = normalizeYAML(elem)
+		}
+		return value
+	default:
+		return v
+	}
+}
@@ -0,0 +1,156 @@
+// Package openapi generates OpenAPI 3.1 documents from the registered routes
+// of an Iris application: their path parameter types (macros),
+// the inputs and the results of the dependency injection handlers
+// and the x/errors codes they may respond with.
+//
+// Example Code:
+//
+//	docs := openapi.New(openapi.Info{Title: "Users API", Version: "1.0.0"})
+//	route := app.ConfigureContainer().Post("/users", createUser)
+//	docs.Route(route).Summary("Creates a user").Errors(errors.InvalidArgument, errors.AlreadyExists)
+//
+//	app.Get("/openapi.json", docs.Handler(app))
+//	app.Get("/docs", openapi.UI("/openapi.json"))
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Version is the OpenAPI specification version of the generated documents.
+const Version = "3.1.0"
+
+type (
+	// Document is the root object of an OpenAPI document.
+	Document struct {
+		OpenAPI    string               `json:"openapi"`
+		Info       Info                 `json:"info"`
+		Servers    []Server             `json:"servers,omitempty"`
+		Paths      map[string]*PathItem `json:"paths"`
+		Components *Components          `json:"components,omitempty"`
+	}
+
+	// Info holds the metadata of the API.
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	// Server describes a server of the API.
+	Server struct {
+		URL         string `json:"url"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// Components holds the reusable objects of a document.
+	Components struct {
+		Schemas       map[string]*Schema      `json:"schemas,omitempty"`
+		Parameters    map[string]*Parameter   `json:"parameters,omitempty"`
+		RequestBodies map[string]*RequestBody `json:"requestBodies,omitempty"`
+		Responses     map[string]*Response    `json:"responses,omitempty"`
+	}
+
+	// PathItem describes the operations of a path.
+	PathItem struct {
+		Summary     string       `json:"summary,omitempty"`
+		Description string       `json:"description,omitempty"`
+		Get         *Operation   `json:"get,omitempty"`
+		Put         *Operation   `json:"put,omitempty"`
+		Post        *Operation   `json:"post,omitempty"`
+		Delete      *Operation   `json:"delete,omitempty"`
+		Options     *Operation   `json:"options,omitempty"`
+		Head        *Operation   `json:"head,omitempty"`
+		Patch       *Operation   `json:"patch,omitempty"`
+		Trace       *Operation   `json:"trace,omitempty"`
+		Parameters  []*Parameter `json:"parameters,omitempty"`
+	}
+
+	// Operation describes a single API operation on a path.
+	Operation struct {
+		Tags        []string             `json:"tags,omitempty"`
+		Summary     string               `json:"summary,omitempty"`
+		Description string               `json:"description,omitempty"`
+		OperationID string               `json:"operationId,omitempty"`
+		Parameters  []*Parameter         `json:"parameters,omitempty"`
+		RequestBody *RequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*Response `json:"responses"`
+		Deprecated  bool                 `json:"deprecated,omitempty"`
+	}
+
+	// Parameter describes a path, query, header or cookie parameter of an operation.
+	Parameter struct {
+		Ref         string  `json:"$ref,omitempty"`
+		Name        string  `json:"name,omitempty"`
+		In          string  `json:"in,omitempty"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema,omitempty"`
+	}
+
+	// RequestBody describes the request body of an operation.
+	RequestBody struct {
+		Ref         string                `json:"$ref,omitempty"`
+		Description string                `json:"description,omitempty"`
+		Required    bool                  `json:"required,omitempty"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+
+	// Response describes a single response of an operation.
+	Response struct {
+		Ref         string                `json:"$ref,omitempty"`
+		Description string                `json:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+
+	// MediaType holds the schema of a request or response content type.
+	MediaType struct {
+		Schema *Schema `json:"schema,omitempty"`
+	}
+)
+
+// Operations returns the operations of the path item by their HTTP method.
+func (p *PathItem) Operations() map[string]*Operation {
+	operations := make(map[string]*Operation)
+	for _, method := range []string{
+		http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+		http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+	} {
+		if op := p.operation(method); op != nil && *op != nil {
+			operations[method] = *op
+		}
+	}
+
+	return operations
+}
+
+// operation returns the field of the "method" operation, nil if it's not supported.
+func (p *PathItem) operation(method string) **Operation {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		return &p.Get
+	case http.MethodPut:
+		return &p.Put
+	case http.MethodPost:
+		return &p.Post
+	case http.MethodDelete:
+		return &p.Delete
+	case http.MethodOptions:
+		return &p.Options
+	case http.MethodHead:
+		return &p.Head
+	case http.MethodPatch:
+		return &p.Patch
+	case http.MethodTrace:
+		return &p.Trace
+	default:
+		return nil
+	}
+}
+
+// JSON returns the indented JSON form of the document.
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
@@ -0,0 +1,224 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/macro"
+	"github.com/kataras/iris/v12/x/errors"
+	"github.com/kataras/iris/v12/x/openapi"
+)
+
+type (
+	user struct {
+		ID       uint64   `json:"id"`
+		Username string   `json:"username"`
+		Email    string   `json:"email,omitempty"`
+		Friends  []*user  `json:"friends,omitempty"`
+		Tags     []string `json:"tags"`
+	}
+
+	createUserRequest struct {
+		Username string `json:"username"`
+	}
+
+	listOptions struct {
+		Page int    `url:"page"`
+		Sort string `url:"sort"`
+	}
+
+	service struct{}
+)
+
+func TestGenerate(t *testing.T) {
+	app := iris.New()
+	app.RegisterDependency(new(service))
+
+	docs := openapi.New(openapi.Info{Title: "Users API", Version: "1.0.0"})
+
+	users := app.Party("/users")
+	users.ConfigureContainer(func(api *iris.APIContainer) {
+		api.Get("/", func(opts listOptions, s *service) []user { return nil })
+		api.Get("/{id:uint64 min(1)}", func(id uint64) (user, error) { return user{ID: id}, nil })
+		create := api.Post("/", func(req createUserRequest) (user, error) { return user{}, nil })
+		docs.Route(create).Summary("Creates a user").Tags("users").Errors(errors.InvalidArgument, errors.AlreadyExists)
+	})
+
+	route := users.Delete("/{name:string prefix(user_) max(32)}", func(ctx iris.Context) {})
+	route.Description = "Deletes a user"
+	docs.Route(route).Response(http.StatusNoContent, nil).Errors(errors.NotFound)
+
+	app.Get("/openapi.json", docs.Handler(app))
+	app.Get("/docs", openapi.UI("/openapi.json"))
+
+	e := httptest.New(t, app)
+	body := e.GET("/openapi.json").Expect().Status(httptest.StatusOK).
+		ContentType("application/json").Body().Raw()
+
+	var doc openapi.Document
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := openapi.Version, doc.OpenAPI; expected != got {
+		t.Fatalf("expected version: %s but got: %s", expected, got)
+	}
+
+	if _, ok := doc.Paths["/openapi.json"]; ok {
+		t.Fatalf("expected the document route to be excluded")
+	}
+
+	// Query parameters from the GET payload, the registered dependency is not a parameter.
+	list := doc.Paths["/users"].Get
+	if list == nil || len(list.Parameters) != 2 || list.Parameters[0].Name != "page" || list.Parameters[0].In != "query" {
+		t.Fatalf("unexpected list operation: %#+v", list)
+	}
+	if items := list.Responses["200"].Content["application/json"].Schema.Items; items == nil || items.Ref != "#/components/schemas/user" {
+		t.Fatalf("expected a list of users response but got: %#+v", list.Responses["200"])
+	}
+
+	// Path parameters from macros.
+	get := doc.Paths["/users/{id}"].Get
+	param := get.Parameters[0]
+	if param.Name != "id" || param.In != "path" || !param.Required || !param.Schema.Type.Is("integer") ||
+		param.Schema.Minimum == nil || *param.Schema.Minimum != 1 {
+		t.Fatalf("unexpected path parameter: %#+v", param)
+	}
+	if _, ok := get.Responses["default"]; !ok {
+		t.Fatalf("expected an error response")
+	}
+
+	// Request body and x/errors codes.
+	create := doc.Paths["/users"].Post
+	if create.Summary != "Creates a user" || create.RequestBody == nil ||
+		create.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/createUserRequest" {
+		t.Fatalf("unexpected create operation: %#+v", create)
+	}
+	if resp, ok := create.Responses["400"]; !ok || resp.Description != "INVALID_ARGUMENT" {
+		t.Fatalf("expected a bad request error response but got: %#+v", create.Responses)
+	}
+	if _, ok := create.Responses["409"]; !ok {
+		t.Fatalf("expected a conflict error response but got: %#+v", create.Responses)
+	}
+
+	// Common handlers are described through the generator.
+	del := doc.Paths["/users/{name}"].Delete
+	if del.Description != "Deletes a user" || del.Parameters[0].Schema.Pattern != "^user_" ||
+		del.Parameters[0].Schema.MaxLength == nil || *del.Parameters[0].Schema.MaxLength != 32 {
+		t.Fatalf("unexpected delete operation: %#+v", del)
+	}
+	if _, ok := del.Responses["204"]; !ok {
+		t.Fatalf("expected a no content response but got: %#+v", del.Responses)
+	}
+
+	schema := doc.Components.Schemas["user"]
+	if schema == nil || !schema.Type.Is("object") || schema.Properties["friends"].Items.Ref != "#/components/schemas/user" {
+		t.Fatalf("unexpected user schema: %#+v", schema)
+	}
+	if expected, got := []string{"id", "username", "tags"}, schema.Required; len(got) != len(expected) {
+		t.Fatalf("expected required fields: %v but got: %v", expected, got)
+	}
+	if _, ok := doc.Components.Schemas["Error"]; !ok {
+		t.Fatalf("expected the x/errors error schema")
+	}
+
+	e.GET("/docs").Expect().Status(httptest.StatusOK).ContentType("text/html").
+		Body().Contains("swagger-ui")
+}
+
+func TestGenerateMacros(t *testing.T) {
+	app := iris.New()
+	err := app.Macros().Define(macro.Definition{Name: "page", Type: "int", Min: floatPtr(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Macros().Unregister("page")
+
+	docs := openapi.New(openapi.Info{Title: "Macros API", Version: "1.0.0"})
+	app.Get("/colors/{color:enum(red,green)}", func(ctx iris.Context) {})
+	app.Get("/hosts/{ip:ipv6}", func(ctx iris.Context) {})
+	app.Get("/pages/{page:page}", func(ctx iris.Context) {})
+	app.Get("/openapi.json", docs.Handler(app))
+
+	e := httptest.New(t, app)
+	body := e.GET("/openapi.json").Expect().Status(httptest.StatusOK).Body().Raw()
+
+	var doc openapi.Document
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	color := doc.Paths["/colors/{color}"].Get.Parameters[0].Schema
+	if len(color.Enum) != 2 || color.Enum[0] != "red" || color.Enum[1] != "green" {
+		t.Fatalf("unexpected enum schema: %#+v", color)
+	}
+
+	if ip := doc.Paths["/hosts/{ip}"].Get.Parameters[0].Schema; ip.Format != "ipv6" {
+		t.Fatalf("unexpected ipv6 schema: %#+v", ip)
+	}
+
+	page := doc.Paths["/pages/{page}"].Get.Parameters[0].Schema
+	if !page.Type.Is("integer") || page.Minimum == nil || *page.Minimum != 1 {
+		t.Fatalf("unexpected defined macro schema: %#+v", page)
+	}
+}
+
+func floatPtr(n float64) *float64 { return &n }
+
+func TestGenerateCustomMacro(t *testing.T) {
+	app := iris.New()
+	defaults := append(macro.Macros(nil), *app.Macros()...)
+	defer func() { *app.Macros() = defaults }()
+
+	// a custom parameter type which replaces the builtin "slug" one.
+	app.Macros().Register("slug", "", 0, false, false, func(paramValue string) (interface{}, bool) {
+		n, err := strconv.Atoi(paramValue)
+		return n, err == nil
+	})
+
+	docs := openapi.New(openapi.Info{Title: "Macros API", Version: "1.0.0"})
+	app.Get("/posts/{id:slug}", func(ctx iris.Context) {})
+	app.Get("/openapi.json", docs.Handler(app))
+
+	e := httptest.New(t, app)
+	body := e.GET("/openapi.json").Expect().Status(httptest.StatusOK).Body().Raw()
+
+	var doc openapi.Document
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if schema := doc.Paths["/posts/{id}"].Get.Parameters[0].Schema; !schema.Type.Is("integer") || schema.Pattern != "" {
+		t.Fatalf("expected the schema of the custom macro but got: %#+v", schema)
+	}
+}
+
+func TestGenerateRoutesTransaction(t *testing.T) {
+	app := iris.New()
+	docs := openapi.New(openapi.Info{Title: "Users API", Version: "1.0.0"})
+
+	route := app.Delete("/users/{id:uint64}", func(ctx iris.Context) {})
+	docs.Route(route).Summary("Deletes a user").Response(http.StatusNoContent, nil)
+	app.Get("/openapi.json", docs.Handler(app))
+
+	e := httptest.New(t, app)
+	e.GET("/openapi.json").Expect().Status(httptest.StatusOK).
+		JSON().Path("$.paths").Object().NotContainsKey("/users")
+
+	tx := app.BeginRoutes()
+	tx.Get("/users", func(ctx iris.Context) {})
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the document is generated again and the details of the copied route are kept.
+	paths := e.GET("/openapi.json").Expect().Status(httptest.StatusOK).JSON().Path("$.paths").Object()
+	paths.ContainsKey("/users")
+	del := paths.Value("/users/{id}").Object().Value("delete").Object()
+	del.Value("summary").String().IsEqual("Deletes a user")
+	del.Value("responses").Object().ContainsKey("204")
+}
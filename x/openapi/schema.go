@@ -0,0 +1,355 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12) object,
+// the subset which is used to describe request and response data.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Not                  *Schema            `json:"not,omitempty"`
+	// Nullable is the OpenAPI 3.0 form of the "null" type.
+	Nullable bool `json:"nullable,omitempty"`
+}
+
+// UnmarshalJSON decodes a schema, including the boolean ones,
+// e.g. "additionalProperties: false".
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{Not: &Schema{}}
+		return nil
+	}
+
+	type schema Schema // without the UnmarshalJSON method.
+	return json.Unmarshal(b, (*schema)(s))
+}
+
+// SchemaType is the type of a Schema.
+// OpenAPI 3.1 accepts a list of types, e.g. ["string", "null"].
+type SchemaType []string
+
+// MarshalJSON encodes a single type as a string.
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON decodes a type from a string or a list of strings.
+func (t *SchemaType) UnmarshalJSON(b []byte) error {
+	var typ string
+	if err := json.Unmarshal(b, &typ); err == nil {
+		*t = SchemaType{typ}
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+// Is reports whether "typ" is one of the types.
+func (t SchemaType) Is(typ string) bool {
+	for _, s := range t {
+		if s == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func floatPtr(n float64) *float64 {
+	return &n
+}
+
+// componentRefPrefix is the prefix of the references to the component schemas.
+const componentRefPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemas builds the schemas of Go types,
+// the named structs are stored as components.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// of returns the schema of a value of "typ".
+func (s *schemas) of(typ reflect.Type) *Schema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ {
+	case timeType:
+		return &Schema{Type: SchemaType{"string"}, Format: "date-time"}
+	case durationType:
+		return &Schema{Type: SchemaType{"integer"}, Format: "int64", Description: "nanoseconds"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	if implements(typ, jsonMarshalerType) {
+		// custom encoding, its form is unknown.
+		return &Schema{}
+	}
+
+	if implements(typ, textMarshalerType) {
+		return &Schema{Type: SchemaType{"string"}}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		schema := &Schema{Type: SchemaType{"integer"}, Format: "int64"}
+		if isUnsigned(typ.Kind()) {
+			schema.Minimum = floatPtr(0)
+		}
+		return schema
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return integerSchema(typ.Kind())
+	case reflect.Float32:
+		return &Schema{Type: SchemaType{"number"}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}, Format: "double"}
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaType{"string"}, Format: "byte"}
+		}
+
+		return &Schema{Type: SchemaType{"array"}, Items: s.of(typ.Elem())}
+	case reflect.Map:
+		return &Schema{Type: SchemaType{"object"}, AdditionalProperties: s.of(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return s.object(typ)
+		}
+
+		return &Schema{Ref: componentRefPrefix + s.component(typ)}
+	default: // interfaces, any value.
+		return &Schema{}
+	}
+}
+
+// component registers the schema of a named struct type and returns its name.
+func (s *schemas) component(typ reflect.Type) string {
+	if name, ok := s.names[typ]; ok {
+		return name
+	}
+
+	name := componentName(typ.Name())
+	if _, exists := s.components[name]; exists {
+		// same name from another package.
+		name = componentName(typ.String())
+	}
+
+	s.names[typ] = name
+	s.components[name] = &Schema{} // placeholder for recursive types.
+	*s.components[name] = *s.object(typ)
+	return name
+}
+
+var invalidComponentNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// componentName returns a valid component name of a type name,
+// e.g. of the generic ones.
+func componentName(typeName string) string {
+	return strings.Trim(invalidComponentNameChars.ReplaceAllString(typeName, "_"), "_")
+}
+
+// object returns the object schema of a struct type.
+func (s *schemas) object(typ reflect.Type) *Schema {
+	schema := &Schema{Type: SchemaType{"object"}, Properties: make(map[string]*Schema)}
+	s.fields(schema, typ)
+	return schema
+}
+
+func (s *schemas) fields(schema *Schema, typ reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+
+		name, opts := parseTag(f.Tag.Get("json"))
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ftyp := f.Type
+			if ftyp.Kind() == reflect.Ptr {
+				ftyp = ftyp.Elem()
+			}
+
+			if ftyp.Kind() == reflect.Struct {
+				// embedded struct, its fields are promoted.
+				s.fields(schema, ftyp)
+				continue
+			}
+		}
+
+		if f.PkgPath != "" { // unexported.
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fieldSchema := s.of(f.Type)
+		if hasOption(opts, "string") {
+			fieldSchema = &Schema{Type: SchemaType{"string"}}
+		}
+
+		schema.Properties[name] = fieldSchema
+
+		if !hasOption(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// queryParameters returns the URL query parameters of a struct type,
+// based on its "url" field tags, see `Context.ReadQuery`.
+func (s *schemas) queryParameters(typ reflect.Type) (params []*Parameter) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, opts := parseTag(f.Tag.Get("url"))
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			if f.Anonymous {
+				params = append(params, s.queryParameters(f.Type)...)
+				continue
+			}
+
+			name = f.Name
+		}
+
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: hasOption(opts, "required"),
+			Schema:   s.of(f.Type),
+		})
+	}
+
+	return
+}
+
+func parseTag(tag string) (string, string) {
+	if idx := strings.IndexByte(tag, ','); idx != -1 {
+		return tag[:idx], tag[idx+1:]
+	}
+
+	return tag, ""
+}
+
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var name string
+		name, opts = parseTag(opts)
+		if name == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+func implements(typ, iface reflect.Type) bool {
+	return typ.Implements(iface) || reflect.PtrTo(typ).Implements(iface)
+}
+
+func isUnsigned(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// integerSchema returns the schema of a sized integer, with its bounds.
+func integerSchema(kind reflect.Kind) *Schema {
+	schema := &Schema{Type: SchemaType{"integer"}, Format: "int32"}
+	switch kind {
+	case reflect.Int8:
+		schema.Minimum, schema.Maximum = floatPtr(-1<<7), floatPtr(1<<7-1)
+	case reflect.Int16:
+		schema.Minimum, schema.Maximum = floatPtr(-1<<15), floatPtr(1<<15-1)
+	case reflect.Int32:
+		schema.Minimum, schema.Maximum = floatPtr(-1<<31), floatPtr(1<<31-1)
+	case reflect.Uint8:
+		schema.Minimum, schema.Maximum = floatPtr(0), floatPtr(1<<8-1)
+	case reflect.Uint16:
+		schema.Minimum, schema.Maximum = floatPtr(0), floatPtr(1<<16-1)
+	case reflect.Uint32:
+		schema.Format = "int64"
+		schema.Minimum, schema.Maximum = floatPtr(0), floatPtr(1<<32-1)
+	}
+
+	return schema
+}
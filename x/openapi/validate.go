@@ -0,0 +1,313 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/x/errors"
+	"github.com/kataras/iris/v12/x/errors/validation"
+
+	"github.com/google/uuid"
+)
+
+// schemaValidator validates decoded JSON values
+// against the schemas of a document.
+type schemaValidator struct {
+	doc      *Document
+	patterns sync.Map // pattern string to *regexp.Regexp.
+}
+
+// maxRefDepth protects against circular schema references.
+const maxRefDepth = 32
+
+// resolve follows the local references of a schema.
+func (v *schemaValidator) resolve(s *Schema) *Schema {
+	for depth := 0; s != nil && s.Ref != ""; depth++ {
+		if depth == maxRefDepth || v.doc.Components == nil {
+			return nil
+		}
+
+		s = v.doc.Components.Schemas[strings.TrimPrefix(s.Ref, componentRefPrefix)]
+	}
+
+	return s
+}
+
+func (v *schemaValidator) pattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	v.patterns.Store(expr, re)
+	return re, nil
+}
+
+func invalid(field string, value interface{}, format string, args ...interface{}) errors.ValidationError {
+	return &validation.FieldError[any]{Field: field, Value: value, Reason: fmt.Sprintf(format, args...)}
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
+
+// validate validates a decoded JSON value, i.e. nil, bool, float64,
+// string, []interface{} or map[string]interface{}, against the "schema".
+func (v *schemaValidator) validate(field string, value interface{}, schema *Schema) (errs []errors.ValidationError) {
+	schema = v.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Not != nil && len(v.validate(field, value, schema.Not)) == 0 {
+		if s := v.resolve(schema.Not); s != nil && reflect.DeepEqual(*s, Schema{}) {
+			return append(errs, invalid(field, value, "is not allowed"))
+		}
+
+		return append(errs, invalid(field, value, "must not match the schema"))
+	}
+
+	for _, s := range schema.AllOf {
+		errs = append(errs, v.validate(field, value, s)...)
+	}
+
+	if len(schema.AnyOf) > 0 && v.matches(field, value, schema.AnyOf) == 0 {
+		errs = append(errs, invalid(field, value, "must match at least one schema"))
+	}
+
+	if len(schema.OneOf) > 0 && v.matches(field, value, schema.OneOf) != 1 {
+		errs = append(errs, invalid(field, value, "must match exactly one schema"))
+	}
+
+	if value == nil {
+		if len(schema.Type) > 0 && !schema.Nullable && !schema.Type.Is("null") {
+			errs = append(errs, invalid(field, value, "must not be null"))
+		}
+
+		return
+	}
+
+	if !typeMatches(schema.Type, value) {
+		return append(errs, invalid(field, value, "must be of type %s", strings.Join(schema.Type, " or ")))
+	}
+
+	if len(schema.Enum) > 0 && !isEnum(value, schema.Enum) {
+		errs = append(errs, invalid(field, value, "must be one of %v", schema.Enum))
+	}
+
+	switch val := value.(type) {
+	case string:
+		errs = append(errs, v.validateString(field, val, schema)...)
+	case float64:
+		if schema.Minimum != nil && val < *schema.Minimum {
+			errs = append(errs, invalid(field, value, "must be at least %v", *schema.Minimum))
+		}
+
+		if schema.Maximum != nil && val > *schema.Maximum {
+			errs = append(errs, invalid(field, value, "must be at most %v", *schema.Maximum))
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(val) < *schema.MinItems {
+			errs = append(errs, invalid(field, value, "must have at least %d items", *schema.MinItems))
+		}
+
+		if schema.MaxItems != nil && len(val) > *schema.MaxItems {
+			errs = append(errs, invalid(field, value, "must have at most %d items", *schema.MaxItems))
+		}
+
+		if schema.Items != nil {
+			for i, item := range val {
+				errs = append(errs, v.validate(field+"["+strconv.Itoa(i)+"]", item, schema.Items)...)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := val[name]; !ok {
+				errs = append(errs, invalid(joinField(field, name), nil, "is required"))
+			}
+		}
+
+		for name, property := range val {
+			if s, ok := schema.Properties[name]; ok {
+				errs = append(errs, v.validate(joinField(field, name), property, s)...)
+			} else if schema.AdditionalProperties != nil {
+				errs = append(errs, v.validate(joinField(field, name), property, schema.AdditionalProperties)...)
+			}
+		}
+	}
+
+	return
+}
+
+// matches returns the number of the schemas which the value matches.
+func (v *schemaValidator) matches(field string, value interface{}, schemas []*Schema) (n int) {
+	for _, s := range schemas {
+		if len(v.validate(field, value, s)) == 0 {
+			n++
+		}
+	}
+
+	return
+}
+
+func (v *schemaValidator) validateString(field, value string, schema *Schema) (errs []errors.ValidationError) {
+	if n := len([]rune(value)); schema.MinLength != nil && n < *schema.MinLength {
+		errs = append(errs, invalid(field, value, "must be at least %d characters long", *schema.MinLength))
+	} else if schema.MaxLength != nil && n > *schema.MaxLength {
+		errs = append(errs, invalid(field, value, "must be at most %d characters long", *schema.MaxLength))
+	}
+
+	if schema.Pattern != "" {
+		if re, err := v.pattern(schema.Pattern); err == nil && !re.MatchString(value) {
+			errs = append(errs, invalid(field, value, "must match the pattern %s", schema.Pattern))
+		}
+	}
+
+	if check, ok := formats[schema.Format]; ok && !check(value) {
+		errs = append(errs, invalid(field, value, "must be a valid %s", schema.Format))
+	}
+
+	return
+}
+
+// formats holds the validators of the known string formats,
+// the rest are not validated.
+var formats = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	},
+	"email": func(s string) bool {
+		_, err := mail.ParseAddress(s)
+		return err == nil
+	},
+	"uuid": func(s string) bool {
+		_, err := uuid.Parse(s)
+		return err == nil
+	},
+	"ipv4": func(s string) bool {
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil
+	},
+	"ipv6": func(s string) bool {
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() == nil
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	},
+}
+
+func typeMatches(types SchemaType, value interface{}) bool {
+	if len(types) == 0 {
+		return true
+	}
+
+	for _, typ := range types {
+		switch val := value.(type) {
+		case bool:
+			if typ == "boolean" {
+				return true
+			}
+		case float64:
+			if typ == "number" || (typ == "integer" && val == math.Trunc(val)) {
+				return true
+			}
+		case string:
+			if typ == "string" {
+				return true
+			}
+		case []interface{}:
+			if typ == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if typ == "object" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func isEnum(value interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(value, e) || fmt.Sprint(value) == fmt.Sprint(e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseParameter converts a path, query, header or cookie parameter value
+// to a JSON value based on its schema type.
+// On failure it's kept as string, so the type validation fails.
+func (v *schemaValidator) parseParameter(values []string, schema *Schema) interface{} {
+	schema = v.resolve(schema)
+	if schema == nil || len(values) == 0 {
+		return firstValue(values)
+	}
+
+	if schema.Type.Is("array") {
+		if len(values) == 1 && strings.Contains(values[0], ",") {
+			values = strings.Split(values[0], ",")
+		}
+
+		items := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			items = append(items, v.parseParameter([]string{value}, schema.Items))
+		}
+
+		return items
+	}
+
+	value := values[0]
+	for _, typ := range schema.Type {
+		switch typ {
+		case "integer", "number":
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				return n
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(value); err == nil {
+				return b
+			}
+		case "string":
+			return value
+		}
+	}
+
+	return value
+}
+
+func firstValue(values []string) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values[0]
+}
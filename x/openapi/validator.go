@@ -0,0 +1,377 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/x/errors"
+)
+
+// Validator validates the requests, and optionally the responses,
+// of the operations of an OpenAPI document.
+// The failures are sent to the client through the x/errors validation format.
+//
+// Example Code:
+//
+//	doc, err := openapi.Load("openapi.yml")
+//	if err != nil {
+//		panic(err)
+//	}
+//
+//	app.UseRouter(openapi.NewValidator(doc).Handler)
+type Validator struct {
+	// ValidateResponses validates the JSON responses of the handlers too.
+	// The invalid responses are replaced with an Internal error,
+	// as they are a server's fault.
+	// Defaults to false.
+	ValidateResponses bool
+
+	schema *schemaValidator
+	base   string
+	paths  []*pathValidator
+}
+
+type pathValidator struct {
+	template string
+	expr     *regexp.Regexp
+	params   []string
+	item     *PathItem
+}
+
+var pathParamExpr = regexp.MustCompile(`\{([^}]+)\}`)
+
+// NewValidator returns a new Validator of the "doc" document.
+// The base path of its first server, if any, is stripped
+// from the request paths, e.g. "/v1" of "https://api.example.com/v1".
+func NewValidator(doc *Document) *Validator {
+	v := &Validator{schema: &schemaValidator{doc: doc}}
+
+	if len(doc.Servers) > 0 {
+		if u, err := url.Parse(doc.Servers[0].URL); err == nil {
+			v.base = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+
+	for template, item := range doc.Paths {
+		p := &pathValidator{template: template, item: item}
+
+		expr := "^"
+		last := 0
+		for _, loc := range pathParamExpr.FindAllStringSubmatchIndex(template, -1) {
+			expr += regexp.QuoteMeta(template[last:loc[0]]) + "([^/]+)"
+			p.params = append(p.params, template[loc[2]:loc[3]])
+			last = loc[1]
+		}
+		expr += regexp.QuoteMeta(template[last:]) + "$"
+
+		p.expr = regexp.MustCompile(expr)
+		v.paths = append(v.paths, p)
+	}
+
+	// Static paths win over the parameterized ones,
+	// e.g. "/users/me" over "/users/{id}".
+	sort.SliceStable(v.paths, func(i, j int) bool {
+		if len(v.paths[i].params) != len(v.paths[j].params) {
+			return len(v.paths[i].params) < len(v.paths[j].params)
+		}
+
+		return v.paths[i].template < v.paths[j].template
+	})
+
+	return v
+}
+
+// match returns the path item and the path parameters of a request path.
+func (v *Validator) match(path string) (*PathItem, map[string]string) {
+	if v.base != "" {
+		if !strings.HasPrefix(path, v.base) {
+			return nil, nil
+		}
+
+		path = path[len(v.base):]
+	}
+
+	for _, p := range v.paths {
+		matches := p.expr.FindStringSubmatch(path)
+		if matches == nil {
+			continue
+		}
+
+		params := make(map[string]string, len(p.params))
+		for i, name := range p.params {
+			params[name] = matches[i+1]
+		}
+
+		return p.item, params
+	}
+
+	return nil, nil
+}
+
+// Handler is the middleware which validates the requests.
+// The requests to paths or methods which are not part of the document
+// are not validated.
+func (v *Validator) Handler(ctx *context.Context) {
+	item, pathParams := v.match(ctx.Path())
+	if item == nil {
+		ctx.Next()
+		return
+	}
+
+	opField := item.operation(ctx.Method())
+	if opField == nil || *opField == nil {
+		ctx.Next()
+		return
+	}
+	op := *opField
+
+	errs := v.validateParameters(ctx, v.parameters(item, op), pathParams)
+	if op.RequestBody != nil {
+		errs = append(errs, v.validateBody(ctx, op.RequestBody)...)
+	}
+
+	if len(errs) > 0 {
+		errors.InvalidArgument.Validation(ctx, errs...)
+		return
+	}
+
+	if !v.ValidateResponses {
+		ctx.Next()
+		return
+	}
+
+	ctx.Record()
+	ctx.Next()
+
+	if errs = v.validateResponse(ctx, op); len(errs) > 0 {
+		ctx.Recorder().ResetBody()
+		errors.Internal.Validation(ctx, errs...)
+	}
+}
+
+// parameters returns the resolved path item and operation parameters,
+// the operation ones override the path item ones of the same name and location.
+func (v *Validator) parameters(item *PathItem, op *Operation) []*Parameter {
+	type key struct{ name, in string }
+
+	var (
+		params  []*Parameter
+		indices = make(map[key]int)
+	)
+
+	all := make([]*Parameter, 0, len(item.Parameters)+len(op.Parameters))
+	all = append(all, item.Parameters...)
+	all = append(all, op.Parameters...)
+
+	for _, p := range all {
+		if p = v.resolveParameter(p); p == nil {
+			continue
+		}
+
+		k := key{p.Name, p.In}
+		if idx, ok := indices[k]; ok {
+			params[idx] = p
+			continue
+		}
+
+		indices[k] = len(params)
+		params = append(params, p)
+	}
+
+	return params
+}
+
+func (v *Validator) resolveParameter(p *Parameter) *Parameter {
+	for depth := 0; p != nil && p.Ref != ""; depth++ {
+		components := v.schema.doc.Components
+		if depth == maxRefDepth || components == nil {
+			return nil
+		}
+
+		p = components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+	}
+
+	return p
+}
+
+func (v *Validator) validateParameters(ctx *context.Context, params []*Parameter, pathParams map[string]string) (errs []errors.ValidationError) {
+	r := ctx.Request()
+	query := r.URL.Query()
+
+	for _, p := range params {
+		var values []string
+
+		switch p.In {
+		case "path":
+			if value, ok := pathParams[p.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = r.Header.Values(p.Name)
+		case "cookie":
+			if cookie, err := r.Cookie(p.Name); err == nil {
+				values = []string{cookie.Value}
+			}
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if p.Required {
+				errs = append(errs, invalid(p.Name, nil, "is required"))
+			}
+
+			continue
+		}
+
+		if p.Schema != nil {
+			errs = append(errs, v.schema.validate(p.Name, v.schema.parseParameter(values, p.Schema), p.Schema)...)
+		}
+	}
+
+	return
+}
+
+func (v *Validator) validateBody(ctx *context.Context, body *RequestBody) []errors.ValidationError {
+	for depth := 0; body != nil && body.Ref != ""; depth++ {
+		components := v.schema.doc.Components
+		if depth == maxRefDepth || components == nil {
+			return nil
+		}
+
+		body = components.RequestBodies[strings.TrimPrefix(body.Ref, "#/components/requestBodies/")]
+	}
+
+	if body == nil {
+		return nil
+	}
+
+	// The body is kept for the next handlers.
+	ctx.RecordRequestBody(true)
+	data, err := ctx.GetBody()
+	if err != nil {
+		return []errors.ValidationError{invalid("body", nil, "cannot be read")}
+	}
+
+	if len(data) == 0 {
+		if body.Required {
+			return []errors.ValidationError{invalid("body", nil, "is required")}
+		}
+
+		return nil
+	}
+
+	contentType := ctx.GetContentTypeRequested()
+	media, ok := mediaType(body.Content, contentType)
+	if !ok {
+		return []errors.ValidationError{invalid("body", contentType, "unsupported content type")}
+	}
+
+	return v.validateContent("body", contentType, data, media)
+}
+
+func (v *Validator) validateResponse(ctx *context.Context, op *Operation) []errors.ValidationError {
+	resp := responseOf(op.Responses, ctx.GetStatusCode())
+	for depth := 0; resp != nil && resp.Ref != ""; depth++ {
+		components := v.schema.doc.Components
+		if depth == maxRefDepth || components == nil {
+			return nil
+		}
+
+		resp = components.Responses[strings.TrimPrefix(resp.Ref, "#/components/responses/")]
+	}
+
+	if resp == nil || len(resp.Content) == 0 {
+		return nil
+	}
+
+	data := ctx.Recorder().Body()
+	if len(data) == 0 {
+		return nil
+	}
+
+	contentType := ctx.GetContentType()
+	media, ok := mediaType(resp.Content, contentType)
+	if !ok {
+		return []errors.ValidationError{invalid("response", contentType, "unexpected content type")}
+	}
+
+	return v.validateContent("response", contentType, data, media)
+}
+
+// validateContent validates JSON data against the schema of its media type,
+// the rest of the content types are not validated.
+func (v *Validator) validateContent(field, contentType string, data []byte, media *MediaType) []errors.ValidationError {
+	if media == nil || media.Schema == nil || !isJSON(contentType) {
+		return nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&value); err != nil {
+		return []errors.ValidationError{invalid(field, nil, "must be valid JSON")}
+	}
+
+	// The root errors are reported as "body" (or "response")
+	// and the rest by their JSON path, e.g. "friends[0].id".
+	var errs []errors.ValidationError
+	for _, err := range v.schema.validate("", value, media.Schema) {
+		if err.GetField() == "" || strings.HasPrefix(err.GetField(), "[") {
+			err = invalid(field+err.GetField(), err.GetValue(), "%s", err.GetReason())
+		}
+
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+func isJSON(contentType string) bool {
+	contentType, _, _ = mime.ParseMediaType(contentType)
+	return contentType == context.ContentJSONHeaderValue || strings.HasSuffix(contentType, "+json")
+}
+
+// mediaType returns the media type of the "contentType",
+// it matches the exact one first, then the "type/*" and "*/*" ranges.
+func mediaType(content map[string]*MediaType, contentType string) (*MediaType, bool) {
+	if len(content) == 0 {
+		return nil, true
+	}
+
+	contentType, _, _ = mime.ParseMediaType(contentType)
+	if media, ok := content[contentType]; ok {
+		return media, true
+	}
+
+	if idx := strings.IndexByte(contentType, '/'); idx > 0 {
+		if media, ok := content[contentType[:idx]+"/*"]; ok {
+			return media, true
+		}
+	}
+
+	media, ok := content["*/*"]
+	return media, ok
+}
+
+// responseOf returns the response of a status code,
+// it matches the exact one first, then its range, e.g. "2XX", and the "default" one.
+func responseOf(responses map[string]*Response, statusCode int) *Response {
+	if resp, ok := responses[strconv.Itoa(statusCode)]; ok {
+		return resp
+	}
+
+	if resp, ok := responses[strconv.Itoa(statusCode/100)+"XX"]; ok {
+		return resp
+	}
+
+	return responses["default"]
+}
@@ -0,0 +1,157 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/x/openapi"
+)
+
+const usersDocument = `
+openapi: 3.0.3
+info:
+  title: Users API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users:
+    get:
+      parameters:
+        - name: page
+          in: query
+          schema:
+            type: integer
+            minimum: 1
+        - $ref: '#/components/parameters/Tenant'
+      responses:
+        200:
+          description: The users.
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/User'
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [username]
+              additionalProperties: false
+              properties:
+                username:
+                  type: string
+                  minLength: 3
+                email:
+                  type: string
+                  format: email
+      responses:
+        201:
+          description: The created user.
+  /users/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        200:
+          description: The user.
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+components:
+  parameters:
+    Tenant:
+      name: X-Tenant
+      in: header
+      required: true
+      schema:
+        type: string
+        enum: [acme, globex]
+  schemas:
+    User:
+      type: object
+      required: [id, username]
+      properties:
+        id:
+          type: integer
+        username:
+          type: string
+`
+
+func TestValidator(t *testing.T) {
+	doc, err := openapi.Parse([]byte(usersDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := openapi.NewValidator(doc)
+	validator.ValidateResponses = true
+
+	app := iris.New()
+	app.UseRouter(validator.Handler)
+
+	v1 := app.Party("/v1")
+	v1.Get("/users", func(ctx iris.Context) {
+		ctx.JSON([]iris.Map{{"id": 1, "username": "makis"}})
+	})
+	v1.Post("/users", func(ctx iris.Context) {
+		var req iris.Map
+		if err := ctx.ReadJSON(&req); err != nil {
+			ctx.StopWithError(iris.StatusBadRequest, err)
+			return
+		}
+
+		ctx.StatusCode(iris.StatusCreated)
+		ctx.JSON(req)
+	})
+	v1.Get("/users/{id}", func(ctx iris.Context) {
+		// The "username" is missing.
+		ctx.JSON(iris.Map{"id": ctx.Params().GetUint64Default("id", 0)})
+	})
+	v1.Get("/health", func(ctx iris.Context) {
+		ctx.WriteString("OK")
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/v1/users").WithQuery("page", 1).WithHeader("X-Tenant", "acme").Expect().
+		Status(httptest.StatusOK).JSON().Array().Length().IsEqual(1)
+
+	errs := e.GET("/v1/users").WithQuery("page", 0).WithHeader("X-Tenant", "initech").Expect().
+		Status(httptest.StatusBadRequest).JSON().Object().Value("validation").Array()
+	errs.Length().IsEqual(2)
+	errs.Value(0).Object().HasValue("field", "page").HasValue("reason", "must be at least 1")
+	errs.Value(1).Object().HasValue("field", "X-Tenant")
+
+	e.GET("/v1/users").WithQuery("page", "first").Expect().Status(httptest.StatusBadRequest)
+
+	// The body is still readable by the route handler.
+	e.POST("/v1/users").WithJSON(iris.Map{"username": "makis"}).Expect().
+		Status(httptest.StatusCreated).JSON().Object().HasValue("username", "makis")
+
+	errs = e.POST("/v1/users").WithJSON(iris.Map{"username": "mk", "email": "invalid", "admin": true}).Expect().
+		Status(httptest.StatusBadRequest).JSON().Object().Value("validation").Array()
+	errs.Length().IsEqual(3)
+
+	e.POST("/v1/users").Expect().Status(httptest.StatusBadRequest).
+		JSON().Object().Value("validation").Array().Value(0).Object().HasValue("field", "body")
+
+	e.GET("/v1/users/abc").Expect().Status(httptest.StatusBadRequest)
+
+	// Invalid responses are replaced by an internal error.
+	e.GET("/v1/users/42").Expect().Status(httptest.StatusInternalServerError).
+		JSON().Object().Value("validation").Array().Value(0).Object().HasValue("field", "username")
+
+	// Undocumented routes are not validated.
+	e.GET("/v1/health").Expect().Status(httptest.StatusOK).Body().IsEqual("OK")
+}